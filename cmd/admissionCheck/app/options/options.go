@@ -0,0 +1,52 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/admission"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type AdmissionCheckOptions struct {
+	Kubeconfig string
+	Namespace  string
+	Ref        string
+	Filename   string
+}
+
+func NewAdmissionCheckOptions() *AdmissionCheckOptions {
+	return &AdmissionCheckOptions{}
+}
+
+func (o *AdmissionCheckOptions) NewSimulator() (*admission.Simulator, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &admission.Simulator{
+		ClientSet: clientset,
+		Namespace: o.Namespace,
+		Ref:       o.Ref,
+		Filename:  o.Filename,
+	}, nil
+}
+
+func (o *AdmissionCheckOptions) Validate() error {
+
+	if o.Ref != "" && o.Filename != "" {
+		return fmt.Errorf("use either a kind/name reference or -f, not both")
+	}
+	if o.Ref == "" && o.Filename == "" {
+		return fmt.Errorf("a kind/name reference or -f manifest is required")
+	}
+
+	return nil
+}
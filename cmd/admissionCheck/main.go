@@ -0,0 +1,55 @@
+package admissionCheck
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/cmd/admissionCheck/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewAdmissionCheckCommand() *cobra.Command {
+	opts := options.NewAdmissionCheckOptions()
+	cmd := &cobra.Command{
+		Use:   "admission-check [kind/name] [-f manifest] [-n namespace]",
+		Short: "show whether a pod would pass LimitRange and ResourceQuota admission",
+		Long: `apply the namespace's LimitRange defaults and min/max/ratio constraints to a pod, workload or manifest,
+then charge it against every matching ResourceQuota (including PriorityClass and BestEffort scopes) and report
+which quota or limit rejects it and by how much`,
+		SilenceUsage: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && opts.Filename == "" {
+				cmd.Help()
+				return fmt.Errorf("a kind/name reference or -f manifest is required")
+			}
+			return cobra.MaximumNArgs(1)(cmd, args)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Ref = args[0]
+			}
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "default", "namespace of the pod or workload")
+	cmd.Flags().StringVarP(&opts.Filename, "filename", "f", "", "manifest of pods or workloads to check instead of an existing object")
+
+	return cmd
+}
+
+func run(opts *options.AdmissionCheckOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	simulator, err := opts.NewSimulator()
+	if err != nil {
+		return err
+	}
+
+	return simulator.Simulate()
+}
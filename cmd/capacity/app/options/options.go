@@ -0,0 +1,59 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/capacity"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type CapacityOptions struct {
+	Kubeconfig string
+	Namespace  string
+	Template   string
+	CPU        string
+	Memory     string
+	Extended   map[string]string
+	Replicas   int64
+}
+
+func NewCapacityOptions() *CapacityOptions {
+	return &CapacityOptions{}
+}
+
+func (o *CapacityOptions) NewCapacityEstimator() (*capacity.CapacityEstimator, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &capacity.CapacityEstimator{
+		ClientSet: clientset,
+		Namespace: o.Namespace,
+		Template:  o.Template,
+		CPU:       o.CPU,
+		Memory:    o.Memory,
+		Extended:  o.Extended,
+		Replicas:  o.Replicas,
+	}, nil
+}
+
+func (o *CapacityOptions) Validate() error {
+
+	if o.Template == "" && o.CPU == "" && o.Memory == "" && len(o.Extended) == 0 {
+		return fmt.Errorf("a template (kind/name) or at least one of --cpu, --memory, --extended is required")
+	}
+
+	if o.Replicas < 0 {
+		return fmt.Errorf("replicas must not be negative")
+	}
+
+	return nil
+}
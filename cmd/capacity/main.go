@@ -0,0 +1,50 @@
+package capacity
+
+import (
+	"github.com/ops-tool/cmd/capacity/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewCapacityCommand() *cobra.Command {
+	opts := options.NewCapacityOptions()
+	cmd := &cobra.Command{
+		Use:   "capacity [kind/name] [--cpu 4 --memory 16Gi --extended name=quantity]",
+		Short: "estimate how many more copies of a pod shape fit in the cluster",
+		Long: `estimate how many more copies of a pod shape fit in the cluster.
+the shape is taken from an existing pod or workload template (pod/name, deployment/name, statefulset/name, ...)
+or given ad-hoc with --cpu, --memory and --extended`,
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				opts.Template = args[0]
+			}
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "default", "namespace of the template")
+	cmd.Flags().StringVar(&opts.CPU, "cpu", "", "cpu request of the ad-hoc shape (e.g. 4, 500m)")
+	cmd.Flags().StringVar(&opts.Memory, "memory", "", "memory request of the ad-hoc shape (e.g. 16Gi)")
+	cmd.Flags().StringToStringVar(&opts.Extended, "extended", nil, "extended resource requests of the ad-hoc shape (e.g. cloudbed.abcstack.com/ssd-passthrough=1)")
+	cmd.Flags().Int64VarP(&opts.Replicas, "replicas", "r", 0, "number of replicas to check against the estimation")
+
+	return cmd
+}
+
+func run(opts *options.CapacityOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	estimator, err := opts.NewCapacityEstimator()
+	if err != nil {
+		return err
+	}
+
+	return estimator.Estimate()
+}
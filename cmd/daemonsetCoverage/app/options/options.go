@@ -0,0 +1,38 @@
+package options
+
+import (
+	"github.com/ops-tool/pkg/daemonsets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type DaemonSetCoverageOptions struct {
+	Kubeconfig   string
+	Namespace    string
+	Name         string
+	ShowExcluded bool
+}
+
+func NewDaemonSetCoverageOptions() *DaemonSetCoverageOptions {
+	return &DaemonSetCoverageOptions{}
+}
+
+func (o *DaemonSetCoverageOptions) NewCoverageReporter() (*daemonsets.CoverageReporter, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &daemonsets.CoverageReporter{
+		ClientSet:    clientset,
+		Namespace:    o.Namespace,
+		Name:         o.Name,
+		ShowExcluded: o.ShowExcluded,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package daemonsetCoverage
+
+import (
+	"github.com/ops-tool/cmd/daemonsetCoverage/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewDaemonSetCoverageCommand() *cobra.Command {
+	opts := options.NewDaemonSetCoverageOptions()
+	cmd := &cobra.Command{
+		Use:   "daemonset-coverage [daemonsetname] [-n namespace]",
+		Short: "show which nodes lack a DaemonSet pod and why",
+		Long: `compare the nodes each DaemonSet should run on with the nodes that actually run its pods,
+and run the nodeSelector / node affinity / taint / resource checks with the pod template on the others`,
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				opts.Name = args[0]
+			}
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "", "check daemonsets in specific namespace")
+	cmd.Flags().BoolVar(&opts.ShowExcluded, "show-excluded", false, "also list the nodes excluded by nodeSelector, node affinity or taints")
+
+	return cmd
+}
+
+func run(opts *options.DaemonSetCoverageOptions) error {
+
+	reporter, err := opts.NewCoverageReporter()
+	if err != nil {
+		return err
+	}
+
+	return reporter.GetCoverage()
+}
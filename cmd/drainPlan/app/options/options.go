@@ -0,0 +1,47 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/drain"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type DrainPlanOptions struct {
+	Kubeconfig string
+	Nodes      []string
+	Selector   string
+}
+
+func NewDrainPlanOptions() *DrainPlanOptions {
+	return &DrainPlanOptions{}
+}
+
+func (o *DrainPlanOptions) NewDrainPlanner() (*drain.DrainPlanner, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &drain.DrainPlanner{
+		ClientSet: clientset,
+		Nodes:     o.Nodes,
+		Selector:  o.Selector,
+	}, nil
+}
+
+func (o *DrainPlanOptions) Validate() error {
+
+	if len(o.Nodes) == 0 && o.Selector == "" {
+		return fmt.Errorf("node name or label selector is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package drainPlan
+
+import (
+	"github.com/ops-tool/cmd/drainPlan/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewDrainPlanCommand() *cobra.Command {
+	opts := options.NewDrainPlanOptions()
+	cmd := &cobra.Command{
+		Use:   "drain-plan [nodename...] [-l selector]",
+		Short: "simulate draining nodes and show which pods could not be rescheduled",
+		Long: `simulate draining nodes: treat them as removed and re-run the scheduling checks for every evictable pod
+against the remaining nodes. pods that would stay Pending, are blocked by PodDisruptionBudgets or local PVs
+and skipped DaemonSet/mirror pods are reported`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Nodes = args
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Selector, "selector", "l", "", "label selector of the nodes to drain")
+
+	return cmd
+}
+
+func run(opts *options.DrainPlanOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	planner, err := opts.NewDrainPlanner()
+	if err != nil {
+		return err
+	}
+
+	return planner.Plan()
+}
@@ -0,0 +1,48 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/feasibility"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type FeasibilityOptions struct {
+	Kubeconfig string
+	Namespace  string
+	PoolLabel  string
+}
+
+func NewFeasibilityOptions() *FeasibilityOptions {
+	return &FeasibilityOptions{}
+}
+
+func (o *FeasibilityOptions) Validate() error {
+
+	if o.PoolLabel == "" {
+		return fmt.Errorf("pool label is required")
+	}
+
+	return nil
+}
+
+func (o *FeasibilityOptions) NewFeasibilityReporter() (*feasibility.FeasibilityReporter, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &feasibility.FeasibilityReporter{
+		ClientSet: clientset,
+		Namespace: o.Namespace,
+		PoolLabel: o.PoolLabel,
+	}, nil
+
+}
@@ -0,0 +1,45 @@
+package feasibility
+
+import (
+	"github.com/ops-tool/cmd/feasibility/app/options"
+	"github.com/ops-tool/pkg/nodes"
+	"github.com/spf13/cobra"
+)
+
+func NewFeasibilityCommand() *cobra.Command {
+	opts := options.NewFeasibilityOptions()
+	cmd := &cobra.Command{
+		Use:          "feasibility",
+		Short:        "show on which node pools every workload could run",
+		Long:         `build a workloads × node pools matrix counting the nodes each Deployment/StatefulSet/DaemonSet pod template passes the static checks on (nodeSelector, node affinity, tolerations, RuntimeClass), and list the workloads with fewer eligible nodes than replicas`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "", "only check the workloads of this namespace")
+	cmd.Flags().StringVar(&opts.PoolLabel, "pool-label", nodes.DefaultPoolLabel, "label grouping nodes into the pools of the matrix")
+
+	return cmd
+}
+
+func run(opts *options.FeasibilityOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	feasibilityReporter, err := opts.NewFeasibilityReporter()
+	if err != nil {
+		return err
+	}
+
+	return feasibilityReporter.GetFeasibility()
+
+}
@@ -0,0 +1,55 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type FitsOnOptions struct {
+	Kubeconfig   string
+	NodeName     string
+	Namespace    string
+	Templates    bool
+	Uncordon     bool
+	SetLabels    map[string]string
+	RemoveTaints []string
+}
+
+func NewFitsOnOptions() *FitsOnOptions {
+	return &FitsOnOptions{}
+}
+
+func (o *FitsOnOptions) NewFitsOnAnalyzer() (*scheduler.FitsOnAnalyzer, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &scheduler.FitsOnAnalyzer{
+		ClientSet:    clientset,
+		NodeName:     o.NodeName,
+		Namespace:    o.Namespace,
+		Templates:    o.Templates,
+		Uncordon:     o.Uncordon,
+		SetLabels:    o.SetLabels,
+		RemoveTaints: o.RemoveTaints,
+	}, nil
+}
+
+func (o *FitsOnOptions) Validate() error {
+
+	if o.NodeName == "" {
+		return fmt.Errorf("node name is required")
+	}
+
+	return nil
+}
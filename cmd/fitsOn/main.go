@@ -0,0 +1,57 @@
+package fitsOn
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/cmd/fitsOn/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewFitsOnCommand() *cobra.Command {
+	opts := options.NewFitsOnOptions()
+	cmd := &cobra.Command{
+		Use:   "fits-on nodename [-n namespace] [--templates]",
+		Short: "show which pending pods could run on a given node",
+		Long: `run the scheduling checks in reverse: evaluate every Pending pod, and optionally every workload template,
+against one node's labels, taints, allocatable and existing pods. --uncordon, --set-label and --remove-taint
+evaluate the node as it would be after the change`,
+		SilenceUsage: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// 无参数时打印帮助信息
+			if len(args) == 0 {
+				cmd.Help()
+				return fmt.Errorf("node name is required")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.NodeName = args[0]
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "", "only evaluate pods and templates in specific namespace")
+	cmd.Flags().BoolVar(&opts.Templates, "templates", false, "also evaluate Deployment and StatefulSet pod templates")
+	cmd.Flags().BoolVar(&opts.Uncordon, "uncordon", false, "evaluate the node as if it was uncordoned")
+	cmd.Flags().StringToStringVar(&opts.SetLabels, "set-label", nil, "evaluate the node with these labels added or changed (e.g. pool=gpu)")
+	cmd.Flags().StringSliceVar(&opts.RemoveTaints, "remove-taint", nil, "evaluate the node without the taints of these keys")
+
+	return cmd
+}
+
+func run(opts *options.FitsOnOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	analyzer, err := opts.NewFitsOnAnalyzer()
+	if err != nil {
+		return err
+	}
+
+	return analyzer.FitsOn()
+}
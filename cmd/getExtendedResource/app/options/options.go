@@ -0,0 +1,37 @@
+package options
+
+import (
+	"github.com/ops-tool/pkg/nodes"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type ExtendedResourceOptions struct {
+	Kubeconfig string
+	Resource   string
+	Node       string
+}
+
+func NewExtendedResourceOptions() *ExtendedResourceOptions {
+	return &ExtendedResourceOptions{}
+}
+
+func (o *ExtendedResourceOptions) NewExtendedResourceReporter() (*nodes.ExtendedResourceReporter, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nodes.ExtendedResourceReporter{
+		ClientSet: clientset,
+		Resource:  o.Resource,
+		Node:      o.Node,
+	}, nil
+
+}
@@ -0,0 +1,41 @@
+package getExtendedResource
+
+import (
+	"github.com/ops-tool/cmd/getExtendedResource/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewGetExtendedResourceCommand() *cobra.Command {
+	opts := options.NewExtendedResourceOptions()
+	cmd := &cobra.Command{
+		Use:          "get-extended-resource [resource_name]",
+		Short:        "show which pods hold the units of extended resources",
+		Long:         `list per node the pods and containers holding units of an extended resource (GPU, netdevice, passthrough disks), their owners and age, flagging terminated pods still bound and capacity below the allocation`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				opts.Resource = args[0]
+			}
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+
+		Args: cobra.MaximumNArgs(1),
+	}
+
+	cmd.Flags().StringVar(&opts.Node, "node", "", "only show the holders on this node")
+
+	return cmd
+}
+
+func run(opts *options.ExtendedResourceOptions) error {
+
+	extendedResourceReporter, err := opts.NewExtendedResourceReporter()
+	if err != nil {
+		return err
+	}
+
+	return extendedResourceReporter.GetExtendedResourceHolders()
+
+}
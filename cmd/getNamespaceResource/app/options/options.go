@@ -0,0 +1,44 @@
+package options
+
+import (
+	"github.com/ops-tool/pkg/namespaces"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+type NamespaceResourceOptions struct {
+	Kubeconfig string
+	Namespace  string
+	Threshold  float64
+}
+
+func NewNamespaceResourceOptions() *NamespaceResourceOptions {
+	return &NamespaceResourceOptions{}
+}
+
+func (o *NamespaceResourceOptions) NewNamespaceResourceReporter() (*namespaces.NamespaceResourceReporter, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	metricsClient, err := metrics.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &namespaces.NamespaceResourceReporter{
+		ClientSet:    clientset,
+		MetricClient: metricsClient,
+		Namespace:    o.Namespace,
+		Threshold:    o.Threshold,
+	}, nil
+
+}
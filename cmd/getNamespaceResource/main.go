@@ -0,0 +1,39 @@
+package getNamespaceResource
+
+import (
+	"github.com/ops-tool/cmd/getNamespaceResource/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewGetNamespaceResourceCommand() *cobra.Command {
+	opts := options.NewNamespaceResourceOptions()
+	cmd := &cobra.Command{
+		Use:          "get-namespace-resource",
+		Short:        "get quota utilization and resource usage of namespaces",
+		Long:         `show ResourceQuota hard vs used, the sum of pod requests/limits, metrics-server usage and the share of the cluster allocatable for each namespace`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "", "get resource of specific namespace")
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", 80, "highlight namespaces using at least this percentage of a quota")
+
+	return cmd
+}
+
+func run(opts *options.NamespaceResourceOptions) error {
+
+	namespaceResourceReporter, err := opts.NewNamespaceResourceReporter()
+	if err != nil {
+		return err
+	}
+
+	return namespaceResourceReporter.GetNamespaceResource()
+
+}
@@ -1,20 +1,68 @@
 package options
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/ops-tool/pkg/nodes"
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 type NodeResourceOptions struct {
-	Kubeconfig string
+	Kubeconfig     string
+	ResourceConfig string
+	GroupBy        string
+	View           string
+	Shapes         []string
+	Warn           map[string]int
+	Critical       map[string]int
+	Top            int
+	Configz        bool
 }
 
 func NewNodeResourceOptions() *NodeResourceOptions {
 	return &NodeResourceOptions{}
 }
+func (n *NodeResourceOptions) Validate() error {
+
+	if !slices.Contains(nodes.Views, n.View) {
+		return fmt.Errorf("invalid view %q, supported views: %s", n.View, strings.Join(nodes.Views, ", "))
+	}
+	if n.GroupBy != "" && n.View != nodes.ViewResources {
+		return fmt.Errorf("--group-by is only supported by the %s view", nodes.ViewResources)
+	}
+	for name, warn := range n.Warn {
+		if critical, ok := n.Critical[name]; ok && critical < warn {
+			return fmt.Errorf("critical threshold of %s (%d%%) is lower than the warn threshold (%d%%)", name, critical, warn)
+		}
+	}
+	if n.Top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+
+	return nil
+}
+
 func (n *NodeResourceOptions) NodeResourceReporter() (*nodes.NodeResourceReporter, error) {
 
+	if n.ResourceConfig != "" {
+		profile, err := framework.LoadProfile(n.ResourceConfig)
+		if err != nil {
+			return nil, err
+		}
+		framework.SetProfile(profile)
+	}
+
+	shapes, err := framework.ParseShapes(n.Shapes)
+	if err != nil {
+		return nil, err
+	}
+
 	config, err := clientcmd.BuildConfigFromFlags("", n.Kubeconfig)
 	if err != nil {
 		return nil, err
@@ -25,8 +73,27 @@ func (n *NodeResourceOptions) NodeResourceReporter() (*nodes.NodeResourceReporte
 		return nil, err
 	}
 
+	metricsClient, err := metrics.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return &nodes.NodeResourceReporter{
-		ClientSet: clientset,
+		ClientSet:     clientset,
+		MetricClient:  metricsClient,
+		DynamicClient: dynamicClient,
+		GroupBy:       n.GroupBy,
+		View:          n.View,
+		Shapes:        shapes,
+		Warn:          n.Warn,
+		Critical:      n.Critical,
+		Top:           n.Top,
+		Configz:       n.Configz,
 	}, nil
 
 }
@@ -1,7 +1,11 @@
 package getNodeResource
 
 import (
+	"strings"
+
 	"github.com/ops-tool/cmd/getNodeResource/app/options"
+	"github.com/ops-tool/pkg/nodes"
+	"github.com/ops-tool/pkg/scheduler/framework"
 	"github.com/spf13/cobra"
 )
 
@@ -23,11 +27,33 @@ func NewGetNodeResourceCommand() *cobra.Command {
 		Args: cobra.NoArgs,
 	}
 
+	cmd.Flags().StringVar(&opts.ResourceConfig, "resource-config", "", "YAML file setting the resource columns, their aliases and units, "+
+		"extended resources found on nodes are added unless discoverExtended is false")
+	cmd.Flags().StringVar(&opts.GroupBy, "group-by", "", "aggregate nodes by the value of this label (e.g. topology.kubernetes.io/zone), "+
+		"printing a subtotal per group and a cluster total")
+	cmd.Flags().StringVar(&opts.View, "view", nodes.ViewResources, "report to print: "+strings.Join(nodes.Views, ", "))
+	cmd.Flags().StringArrayVar(&opts.Shapes, "shape", framework.DefaultShapes, "pod shape counted by the fragmentation view, "+
+		"using format name=resource:quantity,... (e.g. 8c32g=cpu:8,memory:32Gi,nvidia.com/gpu:1)")
+
+	cmd.Flags().StringToIntVar(&opts.Warn, "warn", framework.DefaultOvercommitWarn, "limits/allocatable percentage per resource "+
+		"from which the overcommit view warns, e.g. memory=150")
+	cmd.Flags().StringToIntVar(&opts.Critical, "critical", framework.DefaultOvercommitCritical, "limits/allocatable percentage per resource "+
+		"from which the overcommit view reports the node as critical, e.g. memory=200")
+	cmd.Flags().IntVar(&opts.Top, "top", 5, "number of pods with the most burstable headroom listed per overcommitted node")
+
+	cmd.Flags().BoolVar(&opts.Configz, "configz", false, "read the CPU manager policy and reserved cpus of every kubelet "+
+		"through the node proxy for the cpumanager view, otherwise reserved cpus are estimated from allocatable")
+
 	return cmd
 }
 
 func run(opts *options.NodeResourceOptions) error {
 
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
 	nodeResourceReporter, err := opts.NodeResourceReporter()
 	if err != nil {
 		return err
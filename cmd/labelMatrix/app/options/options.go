@@ -0,0 +1,55 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/nodes"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type LabelMatrixOptions struct {
+	Kubeconfig string
+	Selector   string
+	Keys       []string
+	Prefix     string
+	PoolLabel  string
+}
+
+func NewLabelMatrixOptions() *LabelMatrixOptions {
+	return &LabelMatrixOptions{}
+}
+
+func (o *LabelMatrixOptions) Validate() error {
+
+	if len(o.Keys) > 0 && o.Prefix != "" {
+		return fmt.Errorf("--key and --prefix cannot be used together")
+	}
+	if o.PoolLabel == "" {
+		return fmt.Errorf("pool label is required")
+	}
+
+	return nil
+}
+
+func (o *LabelMatrixOptions) NewLabelMatrixReporter() (*nodes.LabelMatrixReporter, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nodes.LabelMatrixReporter{
+		ClientSet: clientset,
+		Selector:  o.Selector,
+		Keys:      o.Keys,
+		Prefix:    o.Prefix,
+		PoolLabel: o.PoolLabel,
+	}, nil
+
+}
@@ -0,0 +1,47 @@
+package labelMatrix
+
+import (
+	"github.com/ops-tool/cmd/labelMatrix/app/options"
+	"github.com/ops-tool/pkg/nodes"
+	"github.com/spf13/cobra"
+)
+
+func NewLabelMatrixCommand() *cobra.Command {
+	opts := options.NewLabelMatrixOptions()
+	cmd := &cobra.Command{
+		Use:          "label-matrix",
+		Short:        "show node labels and taints as a matrix",
+		Long:         `show a matrix of nodes × label keys and their taints, highlighting keys present on only some nodes of a pool, values differing within a pool and taints no workload in the cluster tolerates`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVarP(&opts.Selector, "selector", "l", "", "only show the nodes matching this label selector")
+	cmd.Flags().StringSliceVar(&opts.Keys, "key", nil, "label keys shown as columns, every key when empty")
+	cmd.Flags().StringVar(&opts.Prefix, "prefix", "", "only show the label keys starting with this prefix (e.g. cloudbed.abcstack.com/)")
+	cmd.Flags().StringVar(&opts.PoolLabel, "pool-label", nodes.DefaultPoolLabel, "label grouping nodes into pools, drift is looked for within a pool")
+
+	return cmd
+}
+
+func run(opts *options.LabelMatrixOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	labelMatrixReporter, err := opts.NewLabelMatrixReporter()
+	if err != nil {
+		return err
+	}
+
+	return labelMatrixReporter.GetLabelMatrix()
+
+}
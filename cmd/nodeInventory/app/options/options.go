@@ -0,0 +1,52 @@
+package options
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ops-tool/pkg/nodes"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type NodeInventoryOptions struct {
+	Kubeconfig string
+	Selector   string
+	Labels     []string
+	Output     string
+}
+
+func NewNodeInventoryOptions() *NodeInventoryOptions {
+	return &NodeInventoryOptions{}
+}
+
+func (o *NodeInventoryOptions) Validate() error {
+
+	if !slices.Contains(nodes.Outputs, o.Output) {
+		return fmt.Errorf("invalid output %q, supported outputs: %s", o.Output, strings.Join(nodes.Outputs, ", "))
+	}
+
+	return nil
+}
+
+func (o *NodeInventoryOptions) NewNodeInventoryReporter() (*nodes.NodeInventoryReporter, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nodes.NodeInventoryReporter{
+		ClientSet: clientset,
+		Selector:  o.Selector,
+		Labels:    o.Labels,
+		Output:    o.Output,
+	}, nil
+
+}
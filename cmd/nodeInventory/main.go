@@ -0,0 +1,48 @@
+package nodeInventory
+
+import (
+	"strings"
+
+	"github.com/ops-tool/cmd/nodeInventory/app/options"
+	"github.com/ops-tool/pkg/nodes"
+	"github.com/spf13/cobra"
+)
+
+func NewNodeInventoryCommand() *cobra.Command {
+	opts := options.NewNodeInventoryOptions()
+	cmd := &cobra.Command{
+		Use:          "node-inventory",
+		Short:        "list kubelet, kube-proxy, runtime, OS and kernel versions of the nodes",
+		Long:         `list the NodeInfo of every node with the selected labels, flag kubelet/kube-proxy versions outside the skew policy and count the nodes per version, exportable as csv, markdown or json`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVarP(&opts.Selector, "selector", "l", "", "only list the nodes matching this label selector")
+	cmd.Flags().StringSliceVar(&opts.Labels, "label", nodes.DefaultInventoryLabels, "node labels shown as columns")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", nodes.OutputTable, "output format: "+strings.Join(nodes.Outputs, ", "))
+
+	return cmd
+}
+
+func run(opts *options.NodeInventoryOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	nodeInventoryReporter, err := opts.NewNodeInventoryReporter()
+	if err != nil {
+		return err
+	}
+
+	return nodeInventoryReporter.GetNodeInventory()
+
+}
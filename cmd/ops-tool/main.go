@@ -1,9 +1,25 @@
 package main
 
 import (
+	"github.com/ops-tool/cmd/admissionCheck"
+	"github.com/ops-tool/cmd/capacity"
+	"github.com/ops-tool/cmd/daemonsetCoverage"
+	"github.com/ops-tool/cmd/drainPlan"
+	"github.com/ops-tool/cmd/feasibility"
+	"github.com/ops-tool/cmd/fitsOn"
+	"github.com/ops-tool/cmd/getExtendedResource"
+	"github.com/ops-tool/cmd/getNamespaceResource"
 	"github.com/ops-tool/cmd/getNodeResource"
 	"github.com/ops-tool/cmd/getPodResource"
+	"github.com/ops-tool/cmd/labelMatrix"
+	"github.com/ops-tool/cmd/nodeInventory"
+	"github.com/ops-tool/cmd/resilience"
 	"github.com/ops-tool/cmd/why"
+	"github.com/ops-tool/cmd/whyNotReady"
+	"github.com/ops-tool/cmd/whyNotRunning"
+	"github.com/ops-tool/cmd/whyPVC"
+	"github.com/ops-tool/cmd/whyRollout"
+	"github.com/ops-tool/cmd/whyTerminating"
 	"github.com/ops-tool/pkg/version"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/util/homedir"
@@ -32,6 +48,22 @@ func main() {
 	rootCmd.AddCommand(getNodeResource.NewGetNodeResourceCommand())
 	rootCmd.AddCommand(getPodResource.NewGetPodResourceCommand())
 	rootCmd.AddCommand(why.NewWhyCommand())
+	rootCmd.AddCommand(capacity.NewCapacityCommand())
+	rootCmd.AddCommand(drainPlan.NewDrainPlanCommand())
+	rootCmd.AddCommand(resilience.NewResilienceCommand())
+	rootCmd.AddCommand(daemonsetCoverage.NewDaemonSetCoverageCommand())
+	rootCmd.AddCommand(fitsOn.NewFitsOnCommand())
+	rootCmd.AddCommand(whyNotRunning.NewWhyNotRunningCommand())
+	rootCmd.AddCommand(whyTerminating.NewWhyTerminatingCommand())
+	rootCmd.AddCommand(whyPVC.NewWhyPVCCommand())
+	rootCmd.AddCommand(whyNotReady.NewWhyNotReadyCommand())
+	rootCmd.AddCommand(whyRollout.NewWhyRolloutCommand())
+	rootCmd.AddCommand(admissionCheck.NewAdmissionCheckCommand())
+	rootCmd.AddCommand(getNamespaceResource.NewGetNamespaceResourceCommand())
+	rootCmd.AddCommand(getExtendedResource.NewGetExtendedResourceCommand())
+	rootCmd.AddCommand(nodeInventory.NewNodeInventoryCommand())
+	rootCmd.AddCommand(labelMatrix.NewLabelMatrixCommand())
+	rootCmd.AddCommand(feasibility.NewFeasibilityCommand())
 	version.AddFlags(rootCmd.PersistentFlags())
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
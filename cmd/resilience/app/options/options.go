@@ -0,0 +1,70 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/resilience"
+	"github.com/ops-tool/pkg/workloads"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type ResilienceOptions struct {
+	Kubeconfig      string
+	Domain          string
+	ZoneLabel       string
+	Namespaces      []string
+	Workloads       []string
+	PriorityClasses []string
+}
+
+func NewResilienceOptions() *ResilienceOptions {
+	return &ResilienceOptions{}
+}
+
+func (o *ResilienceOptions) NewResilienceChecker() (*resilience.ResilienceChecker, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resilience.ResilienceChecker{
+		ClientSet:       clientset,
+		Domain:          o.Domain,
+		ZoneLabel:       o.ZoneLabel,
+		Namespaces:      o.Namespaces,
+		Workloads:       o.Workloads,
+		PriorityClasses: o.PriorityClasses,
+	}, nil
+}
+
+func (o *ResilienceOptions) Validate() error {
+
+	switch o.Domain {
+	case resilience.DomainNode, resilience.DomainZone, resilience.DomainAll:
+	default:
+		return fmt.Errorf("invalid failure domain %s, supported: node, zone, all", o.Domain)
+	}
+
+	if o.Domain != resilience.DomainNode && o.ZoneLabel == "" {
+		return fmt.Errorf("zone label is required")
+	}
+
+	for _, ref := range o.Workloads {
+		kind, _, err := workloads.ParseRef(ref)
+		if err != nil {
+			return err
+		}
+		if _, ok := workloads.KindOf(kind); !ok {
+			return fmt.Errorf("unsupported workload kind in %s", ref)
+		}
+	}
+
+	return nil
+}
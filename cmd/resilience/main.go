@@ -0,0 +1,48 @@
+package resilience
+
+import (
+	"github.com/ops-tool/cmd/resilience/app/options"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+)
+
+func NewResilienceCommand() *cobra.Command {
+	opts := options.NewResilienceOptions()
+	cmd := &cobra.Command{
+		Use:   "resilience [--domain node|zone|all] [-n namespace] [--workload kind/name] [--priority-class name]",
+		Short: "check that losing any single node or zone leaves no selected pod unschedulable",
+		Long: `check N+1 and zone-failure resilience: remove each failure domain in turn, try to re-place the displaced
+pods of the selected namespaces/workloads/priority classes on the remaining nodes and report the worst-case domain
+and the shortfall per resource`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Domain, "domain", "all", "failure domain to simulate: node, zone or all")
+	cmd.Flags().StringVar(&opts.ZoneLabel, "zone-label", v1.LabelTopologyZone, "node label defining zones")
+	cmd.Flags().StringSliceVarP(&opts.Namespaces, "namespace", "n", nil, "only re-place pods of these namespaces")
+	cmd.Flags().StringSliceVarP(&opts.Workloads, "workload", "w", nil, "only re-place pods of these workloads (e.g. deploy/foo, sts/bar, or a bare name of any kind)")
+	cmd.Flags().StringSliceVar(&opts.PriorityClasses, "priority-class", nil, "only re-place pods of these priority classes")
+
+	return cmd
+}
+
+func run(opts *options.ResilienceOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	checker, err := opts.NewResilienceChecker()
+	if err != nil {
+		return err
+	}
+
+	return checker.Check()
+}
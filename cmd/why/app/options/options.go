@@ -3,6 +3,7 @@ package options
 import (
 	"fmt"
 	"github.com/ops-tool/pkg/scheduler"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -11,6 +12,8 @@ type WhyFailedOptions struct {
 	Kubeconfig string
 	Namespace  string
 	PodName    string
+	Configz    bool
+	NUMA       bool
 }
 
 func NewWhyFailedOptions() *WhyFailedOptions {
@@ -33,6 +36,19 @@ func (o *WhyFailedOptions) NewAnalyzer() (*scheduler.Analyzer, error) {
 	if err != nil {
 		return nil, err
 	}
+	if o.Configz {
+		analyzer.LoadCPUManagerConfigs()
+	}
+
+	if o.NUMA {
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		if err := analyzer.LoadNodeTopologies(dynamicClient); err != nil {
+			fmt.Printf("%v, NUMA alignment is not checked\n", err)
+		}
+	}
 
 	return analyzer, nil
 
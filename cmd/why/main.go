@@ -31,6 +31,10 @@ func NewWhyCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "default", "get pod resource in specific namespace")
+	cmd.Flags().BoolVar(&opts.Configz, "configz", false, "read the kubelet CPU manager policy of every node through the node proxy "+
+		"and check whether the integer cpus of a Guaranteed pod can be pinned")
+	cmd.Flags().BoolVar(&opts.NUMA, "numa", false, "read the NodeResourceTopology objects and check whether the pod fits a single NUMA zone "+
+		"on nodes with the single-numa-node topology manager policy")
 
 	return cmd
 }
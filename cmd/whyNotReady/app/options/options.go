@@ -0,0 +1,45 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/diagnosis"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type WhyNotReadyOptions struct {
+	Kubeconfig string
+	NodeName   string
+}
+
+func NewWhyNotReadyOptions() *WhyNotReadyOptions {
+	return &WhyNotReadyOptions{}
+}
+
+func (o *WhyNotReadyOptions) NewNodeDiagnoser() (*diagnosis.NodeDiagnoser, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diagnosis.NodeDiagnoser{
+		ClientSet: clientset,
+		NodeName:  o.NodeName,
+	}, nil
+}
+
+func (o *WhyNotReadyOptions) Validate() error {
+
+	if o.NodeName == "" {
+		return fmt.Errorf("node name is required")
+	}
+
+	return nil
+}
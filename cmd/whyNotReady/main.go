@@ -0,0 +1,51 @@
+package whyNotReady
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/cmd/whyNotReady/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewWhyNotReadyCommand() *cobra.Command {
+	opts := options.NewWhyNotReadyOptions()
+	cmd := &cobra.Command{
+		Use:   "why-not-ready nodename",
+		Short: "show why a node is NotReady and which pods are affected",
+		Long: `read the node conditions and their transitions, the node Lease renew time, lifecycle taints, kubelet version
+skew with the control plane and recent node events, list the pods to be evicted and the pods with ReadWriteOnce
+volumes stuck on the node, and print a short verdict`,
+		SilenceUsage: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// 无参数时打印帮助信息
+			if len(args) == 0 {
+				cmd.Help()
+				return fmt.Errorf("node name is required")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.NodeName = args[0]
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	return cmd
+}
+
+func run(opts *options.WhyNotReadyOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	diagnoser, err := opts.NewNodeDiagnoser()
+	if err != nil {
+		return err
+	}
+
+	return diagnoser.WhyNotReady()
+}
@@ -0,0 +1,47 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/diagnosis"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type WhyNotRunningOptions struct {
+	Kubeconfig string
+	Namespace  string
+	PodName    string
+}
+
+func NewWhyNotRunningOptions() *WhyNotRunningOptions {
+	return &WhyNotRunningOptions{}
+}
+
+func (o *WhyNotRunningOptions) NewPodDiagnoser() (*diagnosis.PodDiagnoser, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diagnosis.PodDiagnoser{
+		ClientSet: clientset,
+		Namespace: o.Namespace,
+		PodName:   o.PodName,
+	}, nil
+}
+
+func (o *WhyNotRunningOptions) Validate() error {
+
+	if o.PodName == "" {
+		return fmt.Errorf("pod name is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package whyNotRunning
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/cmd/whyNotRunning/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewWhyNotRunningCommand() *cobra.Command {
+	opts := options.NewWhyNotRunningOptions()
+	cmd := &cobra.Command{
+		Use:   "why-not-running podname -n namespace",
+		Short: "show why a scheduled pod is not running or not ready",
+		Long: `inspect container waiting reasons, last termination states, referenced ConfigMaps/Secrets/ServiceAccounts,
+imagePullSecrets, volume mount events, init containers and readiness probes, and print a single root-cause verdict`,
+		SilenceUsage: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// 无参数时打印帮助信息
+			if len(args) == 0 {
+				cmd.Help()
+				return fmt.Errorf("pod name is required")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.PodName = args[0]
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "default", "get pod in specific namespace")
+
+	return cmd
+}
+
+func run(opts *options.WhyNotRunningOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	diagnoser, err := opts.NewPodDiagnoser()
+	if err != nil {
+		return err
+	}
+
+	return diagnoser.WhyNotRunning()
+}
@@ -0,0 +1,47 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/diagnosis"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type WhyPVCOptions struct {
+	Kubeconfig string
+	Namespace  string
+	PVCName    string
+}
+
+func NewWhyPVCOptions() *WhyPVCOptions {
+	return &WhyPVCOptions{}
+}
+
+func (o *WhyPVCOptions) NewPVCDiagnoser() (*diagnosis.PVCDiagnoser, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diagnosis.PVCDiagnoser{
+		ClientSet: clientset,
+		Namespace: o.Namespace,
+		PVCName:   o.PVCName,
+	}, nil
+}
+
+func (o *WhyPVCOptions) Validate() error {
+
+	if o.PVCName == "" {
+		return fmt.Errorf("pvc name is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package whyPVC
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/cmd/whyPVC/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewWhyPVCCommand() *cobra.Command {
+	opts := options.NewWhyPVCOptions()
+	cmd := &cobra.Command{
+		Use:   "why-pvc pvcname -n namespace",
+		Short: "show why a PersistentVolumeClaim is not bound",
+		Long: `resolve the StorageClass (or the default one), check the provisioner has a running controller, look for
+matching static PVs (capacity, access modes, selector, storageClassName, volumeMode, node affinity), surface the
+provisioning events and explain the WaitForFirstConsumer state`,
+		SilenceUsage: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// 无参数时打印帮助信息
+			if len(args) == 0 {
+				cmd.Help()
+				return fmt.Errorf("pvc name is required")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.PVCName = args[0]
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "default", "get pvc in specific namespace")
+
+	return cmd
+}
+
+func run(opts *options.WhyPVCOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	diagnoser, err := opts.NewPVCDiagnoser()
+	if err != nil {
+		return err
+	}
+
+	return diagnoser.WhyPending()
+}
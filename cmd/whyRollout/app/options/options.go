@@ -0,0 +1,47 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/diagnosis"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type WhyRolloutOptions struct {
+	Kubeconfig string
+	Namespace  string
+	Ref        string
+}
+
+func NewWhyRolloutOptions() *WhyRolloutOptions {
+	return &WhyRolloutOptions{}
+}
+
+func (o *WhyRolloutOptions) NewRolloutDiagnoser() (*diagnosis.RolloutDiagnoser, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diagnosis.RolloutDiagnoser{
+		ClientSet: clientset,
+		Namespace: o.Namespace,
+		Ref:       o.Ref,
+	}, nil
+}
+
+func (o *WhyRolloutOptions) Validate() error {
+
+	if o.Ref == "" {
+		return fmt.Errorf("workload reference is required")
+	}
+
+	return nil
+}
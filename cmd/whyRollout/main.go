@@ -0,0 +1,53 @@
+package whyRollout
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/cmd/whyRollout/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewWhyRolloutCommand() *cobra.Command {
+	opts := options.NewWhyRolloutOptions()
+	cmd := &cobra.Command{
+		Use:   "why-rollout deployment/name|statefulset/name -n namespace",
+		Short: "show why a deployment or statefulset rollout is stuck",
+		Long: `inspect the workload conditions, the maxSurge/maxUnavailable arithmetic, new and old ReplicaSets, ResourceQuota
+and LimitRange admission failures, the StatefulSet ordered-readiness blocker and unbound volumeClaimTemplates claims,
+then run the scheduling diagnosis on the first Pending new-revision pod`,
+		SilenceUsage: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// 无参数时打印帮助信息
+			if len(args) == 0 {
+				cmd.Help()
+				return fmt.Errorf("workload reference is required")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Ref = args[0]
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "default", "get workload in specific namespace")
+
+	return cmd
+}
+
+func run(opts *options.WhyRolloutOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	diagnoser, err := opts.NewRolloutDiagnoser()
+	if err != nil {
+		return err
+	}
+
+	return diagnoser.WhyRollout()
+}
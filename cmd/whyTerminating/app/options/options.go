@@ -0,0 +1,70 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/pkg/diagnosis"
+	"github.com/ops-tool/pkg/workloads"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type WhyTerminatingOptions struct {
+	Kubeconfig string
+	Namespace  string
+	Ref        string
+
+	kind string
+	name string
+}
+
+func NewWhyTerminatingOptions() *WhyTerminatingOptions {
+	return &WhyTerminatingOptions{}
+}
+
+func (o *WhyTerminatingOptions) NewTerminatingDiagnoser() (*diagnosis.TerminatingDiagnoser, error) {
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diagnosis.TerminatingDiagnoser{
+		ClientSet:     clientset,
+		DynamicClient: dynamicClient,
+		Kind:          o.kind,
+		Name:          o.name,
+		Namespace:     o.Namespace,
+	}, nil
+}
+
+func (o *WhyTerminatingOptions) Validate() error {
+
+	kind, name, err := workloads.ParseRef(o.Ref)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "pod", "pods", "po":
+		o.kind = "pod"
+	case "namespace", "namespaces", "ns":
+		o.kind = "namespace"
+	default:
+		return fmt.Errorf("unsupported kind %q, expected pod or namespace", kind)
+	}
+	o.name = name
+
+	return nil
+}
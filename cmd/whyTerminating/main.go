@@ -0,0 +1,52 @@
+package whyTerminating
+
+import (
+	"fmt"
+
+	"github.com/ops-tool/cmd/whyTerminating/app/options"
+	"github.com/spf13/cobra"
+)
+
+func NewWhyTerminatingCommand() *cobra.Command {
+	opts := options.NewWhyTerminatingOptions()
+	cmd := &cobra.Command{
+		Use:   "why-terminating pod/name -n namespace | namespace/name",
+		Short: "show why a pod or a namespace is stuck in Terminating",
+		Long: `for pods check the deletionTimestamp age, the node Ready state, finalizers and volume detach status;
+for namespaces discover every namespaced resource still present with its finalizers and read the deletion conditions`,
+		SilenceUsage: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// 无参数时打印帮助信息
+			if len(args) == 0 {
+				cmd.Help()
+				return fmt.Errorf("pod or namespace reference is required")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Ref = args[0]
+			opts.Kubeconfig = cmd.Root().PersistentFlags().Lookup("kubeconfig").Value.String()
+			return run(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "default", "namespace of the pod")
+
+	return cmd
+}
+
+func run(opts *options.WhyTerminatingOptions) error {
+
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	diagnoser, err := opts.NewTerminatingDiagnoser()
+	if err != nil {
+		return err
+	}
+
+	return diagnoser.WhyTerminating()
+}
@@ -0,0 +1,154 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+)
+
+// Simulator runs the LimitRanger and ResourceQuota admission of a pod without creating it.
+type Simulator struct {
+	ClientSet *kubernetes.Clientset
+
+	Namespace string
+	// Ref is a kind/name reference to an existing pod or workload.
+	Ref string
+	// Filename is a manifest holding pods or workloads, used instead of Ref.
+	Filename string
+}
+
+// Result is the admission outcome of one pod.
+type Result struct {
+	Pod        *v1.Pod
+	Defaulted  []Defaulted
+	Violations []Violation
+	Headrooms  []*QuotaHeadroom
+}
+
+func (s *Simulator) pods() ([]*v1.Pod, error) {
+	if s.Filename != "" {
+		return workloads.PodsFromManifest(s.Filename, s.Namespace)
+	}
+	pod, err := workloads.PodFromRef(s.ClientSet, s.Namespace, s.Ref)
+	if err != nil {
+		return nil, err
+	}
+	return []*v1.Pod{pod}, nil
+}
+
+func (s *Simulator) Simulate() error {
+	pods, err := s.pods()
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no pod or workload found in %s", s.Filename)
+	}
+
+	for _, pod := range pods {
+		result, err := s.Admit(pod)
+		if err != nil {
+			return err
+		}
+		printResult(result)
+	}
+	return nil
+}
+
+// Admit applies the LimitRanges of the pod's namespace to a copy of the pod, then charges it against the quotas.
+func (s *Simulator) Admit(pod *v1.Pod) (*Result, error) {
+	ranges, err := s.ClientSet.CoreV1().LimitRanges(pod.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list limitranges in %s: %w", pod.Namespace, err)
+	}
+	quotas, err := s.ClientSet.CoreV1().ResourceQuotas(pod.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resourcequotas in %s: %w", pod.Namespace, err)
+	}
+	return AdmitPod(pod, ranges.Items, quotas.Items), nil
+}
+
+func AdmitPod(pod *v1.Pod, ranges []v1.LimitRange, quotas []v1.ResourceQuota) *Result {
+	pod = pod.DeepCopy()
+	result := &Result{Pod: pod}
+	result.Defaulted, result.Violations = ApplyLimitRanges(pod, ranges)
+	// the quota is only charged once the LimitRanger admitted the pod, but reporting both saves a round trip
+	quotaViolations, headrooms := CheckQuotas(pod, quotas)
+	result.Violations = append(result.Violations, quotaViolations...)
+	result.Headrooms = headrooms
+	return result
+}
+
+func printResult(result *Result) {
+	pod := result.Pod
+	fmt.Printf("pod %s/%s (%s)\n", pod.Namespace, pod.Name, workloads.OwnerString(pod))
+	if len(result.Violations) == 0 {
+		fmt.Printf("Verdict: %s\n", util.NewGreenText("admitted"))
+	} else {
+		fmt.Printf("Verdict: %s\n", util.NewRedText(fmt.Sprintf("rejected by %s", result.Violations[0].Source)))
+	}
+
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+
+	if len(result.Defaulted) > 0 {
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetTitle("defaults applied")
+		t.AppendHeader(table.Row{"source", "container", "field", "value"})
+		for _, d := range result.Defaulted {
+			t.AppendRow(table.Row{d.Source, d.Container, d.Field, d.Value.String()})
+		}
+		t.SetStyle(style)
+		t.Render()
+	}
+
+	if len(result.Violations) > 0 {
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetTitle("rejected by")
+		t.AppendHeader(table.Row{"source", "resource", "reason"})
+		for _, v := range result.Violations {
+			t.AppendRow(table.Row{v.Source, v.Resource, util.NewRedText(v.Message).String()})
+		}
+		t.SetStyle(style)
+		t.SetColumnConfigs([]table.ColumnConfig{{Number: 3, WidthMax: 100}})
+		t.Render()
+	}
+
+	if len(result.Headrooms) > 0 {
+		sort.SliceStable(result.Headrooms, func(i, j int) bool {
+			if result.Headrooms[i].Source != result.Headrooms[j].Source {
+				return result.Headrooms[i].Source < result.Headrooms[j].Source
+			}
+			return result.Headrooms[i].Resource < result.Headrooms[j].Resource
+		})
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetTitle("quota headroom")
+		t.AppendHeader(table.Row{"source", "resource", "pod", "used", "hard", "fit"})
+		for _, h := range result.Headrooms {
+			fit := "-"
+			if n := h.Fits(); n >= 0 {
+				fit = fmt.Sprintf("%d", n)
+			}
+			if h.Fits() == 0 {
+				fit = util.NewRedText(fit).String()
+			}
+			t.AppendRow(table.Row{h.Source, h.Resource, h.Want.String(), h.Used.String(), h.Hard.String(), fit})
+		}
+		t.SetStyle(style)
+		t.Render()
+	}
+	fmt.Println()
+}
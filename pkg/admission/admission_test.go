@@ -0,0 +1,115 @@
+package admission
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAdmitPod(t *testing.T) {
+	newPod := func(priorityClass string, requests, limits v1.ResourceList) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+			Spec: v1.PodSpec{
+				PriorityClassName: priorityClass,
+				Containers: []v1.Container{{
+					Name:      "app",
+					Resources: v1.ResourceRequirements{Requests: requests, Limits: limits},
+				}},
+			},
+		}
+	}
+	cpu := func(value string) v1.ResourceList {
+		return v1.ResourceList{v1.ResourceCPU: resource.MustParse(value)}
+	}
+	limitRange := v1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "limits"},
+		Spec: v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{{
+			Type:           v1.LimitTypeContainer,
+			Default:        cpu("1"),
+			DefaultRequest: cpu("500m"),
+			Max:            cpu("2"),
+		}}},
+	}
+	quota := func(name string, hard, used string, selector *v1.ScopeSelector) v1.ResourceQuota {
+		return v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.ResourceQuotaSpec{
+				Hard:          v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse(hard)},
+				ScopeSelector: selector,
+			},
+			Status: v1.ResourceQuotaStatus{Used: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse(used)}},
+		}
+	}
+	highPriority := &v1.ScopeSelector{MatchExpressions: []v1.ScopedResourceSelectorRequirement{{
+		ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpIn, Values: []string{"high"},
+	}}}
+
+	existing := newPod("", cpu("2"), nil)
+	existing.UID = "6f1c2a"
+
+	tests := []struct {
+		name          string
+		pod           *v1.Pod
+		quotas        []v1.ResourceQuota
+		wantDefaulted int
+		wantSources   []string
+	}{
+		{
+			name:          "defaults fit",
+			pod:           newPod("", nil, nil),
+			quotas:        []v1.ResourceQuota{quota("compute", "4", "3", nil)},
+			wantDefaulted: 2,
+		},
+		{
+			name:          "limit over max",
+			pod:           newPod("", cpu("1"), cpu("3")),
+			wantDefaulted: 0,
+			wantSources:   []string{"LimitRange/limits"},
+		},
+		{
+			name:          "quota exceeded",
+			pod:           newPod("", cpu("2"), nil),
+			quotas:        []v1.ResourceQuota{quota("compute", "4", "3", nil)},
+			wantDefaulted: 1,
+			wantSources:   []string{"ResourceQuota/compute"},
+		},
+		{
+			name:          "existing pod already counted in used",
+			pod:           existing,
+			quotas:        []v1.ResourceQuota{quota("compute", "4", "3", nil)},
+			wantDefaulted: 1,
+		},
+		{
+			name:          "scoped quota not matching",
+			pod:           newPod("low", cpu("2"), nil),
+			quotas:        []v1.ResourceQuota{quota("high", "1", "1", highPriority)},
+			wantDefaulted: 1,
+		},
+		{
+			name:          "scoped quota matching",
+			pod:           newPod("high", cpu("2"), nil),
+			quotas:        []v1.ResourceQuota{quota("high", "1", "1", highPriority)},
+			wantDefaulted: 1,
+			wantSources:   []string{"ResourceQuota/high"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AdmitPod(tt.pod, []v1.LimitRange{limitRange}, tt.quotas)
+			if len(result.Defaulted) != tt.wantDefaulted {
+				t.Errorf("AdmitPod() defaulted %v, want %d values", result.Defaulted, tt.wantDefaulted)
+			}
+			if len(result.Violations) != len(tt.wantSources) {
+				t.Fatalf("AdmitPod() violations = %v, want sources %v", result.Violations, tt.wantSources)
+			}
+			for i, violation := range result.Violations {
+				if violation.Source != tt.wantSources[i] {
+					t.Errorf("AdmitPod() violation %d source = %s, want %s", i, violation.Source, tt.wantSources[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package admission
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+)
+
+// Violation is one constraint of a LimitRange or a ResourceQuota that rejects the pod.
+type Violation struct {
+	// Source is kind/name of the object enforcing the constraint.
+	Source   string
+	Resource string
+	Message  string
+}
+
+// Defaulted is a value set on a container by a LimitRange.
+type Defaulted struct {
+	Source    string
+	Container string
+	Field     string
+	Value     resource.Quantity
+}
+
+// ApplyLimitRanges sets the LimitRange default requests and limits on the pod's containers the way
+// the LimitRanger admission plugin does, then validates the min/max/ratio constraints.
+func ApplyLimitRanges(pod *v1.Pod, ranges []v1.LimitRange) ([]Defaulted, []Violation) {
+	var defaulted []Defaulted
+	var violations []Violation
+
+	for i := range pod.Spec.InitContainers {
+		defaultRequestFromLimit(&pod.Spec.InitContainers[i])
+	}
+	for i := range pod.Spec.Containers {
+		defaultRequestFromLimit(&pod.Spec.Containers[i])
+	}
+
+	for _, lr := range ranges {
+		source := "LimitRange/" + lr.Name
+		for _, item := range lr.Spec.Limits {
+			if item.Type != v1.LimitTypeContainer {
+				continue
+			}
+			for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+				for i := range containers {
+					defaulted = append(defaulted, applyDefaults(source, &containers[i], item)...)
+				}
+			}
+		}
+	}
+
+	for _, lr := range ranges {
+		source := "LimitRange/" + lr.Name
+		for _, item := range lr.Spec.Limits {
+			switch item.Type {
+			case v1.LimitTypeContainer:
+				for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+					for _, container := range containers {
+						what := "container " + container.Name
+						violations = append(violations, validate(source, what, item, container.Resources.Requests, container.Resources.Limits)...)
+					}
+				}
+			case v1.LimitTypePod:
+				requests, limits := resourcehelper.PodRequestsAndLimits(pod)
+				violations = append(violations, validate(source, "pod", item, requests, limits)...)
+			}
+		}
+	}
+	return defaulted, violations
+}
+
+// defaultRequestFromLimit mirrors API defaulting: a container with a limit but no request requests its limit.
+func defaultRequestFromLimit(container *v1.Container) {
+	for name, limit := range container.Resources.Limits {
+		if _, ok := container.Resources.Requests[name]; ok {
+			continue
+		}
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = v1.ResourceList{}
+		}
+		container.Resources.Requests[name] = limit.DeepCopy()
+	}
+}
+
+func applyDefaults(source string, container *v1.Container, item v1.LimitRangeItem) []Defaulted {
+	var result []Defaulted
+	for name, value := range item.Default {
+		if _, ok := container.Resources.Limits[name]; ok {
+			continue
+		}
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = v1.ResourceList{}
+		}
+		container.Resources.Limits[name] = value.DeepCopy()
+		result = append(result, Defaulted{Source: source, Container: container.Name, Field: "limits." + string(name), Value: value})
+	}
+	for name, value := range item.DefaultRequest {
+		if _, ok := container.Resources.Requests[name]; ok {
+			continue
+		}
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = v1.ResourceList{}
+		}
+		container.Resources.Requests[name] = value.DeepCopy()
+		result = append(result, Defaulted{Source: source, Container: container.Name, Field: "requests." + string(name), Value: value})
+	}
+	return result
+}
+
+func validate(source, what string, item v1.LimitRangeItem, requests, limits v1.ResourceList) []Violation {
+	var result []Violation
+	for name, min := range item.Min {
+		request, ok := requests[name]
+		switch {
+		case !ok:
+			result = append(result, Violation{source, string(name), fmt.Sprintf("minimum %s usage per %s is %s, %s has no request", name, item.Type, min.String(), what)})
+		case request.Cmp(min) < 0:
+			result = append(result, Violation{source, string(name), fmt.Sprintf("minimum %s usage per %s is %s, %s requests %s: %s short", name, item.Type, min.String(), what, request.String(), difference(min, request))})
+		}
+	}
+	for name, max := range item.Max {
+		limit, ok := limits[name]
+		switch {
+		case !ok:
+			result = append(result, Violation{source, string(name), fmt.Sprintf("maximum %s usage per %s is %s, %s has no limit", name, item.Type, max.String(), what)})
+		case limit.Cmp(max) > 0:
+			result = append(result, Violation{source, string(name), fmt.Sprintf("maximum %s usage per %s is %s, %s limit is %s: %s over", name, item.Type, max.String(), what, limit.String(), difference(limit, max))})
+		}
+	}
+	for name, ratio := range item.MaxLimitRequestRatio {
+		request, hasRequest := requests[name]
+		limit, hasLimit := limits[name]
+		if !hasLimit || !hasRequest || request.IsZero() {
+			if hasLimit && !limit.IsZero() {
+				result = append(result, Violation{source, string(name), fmt.Sprintf("%s max limit to request ratio per %s is %s, %s has no request", name, item.Type, ratio.String(), what)})
+			}
+			continue
+		}
+		actual := float64(limit.MilliValue()) / float64(request.MilliValue())
+		if actual > float64(ratio.MilliValue())/1000 {
+			result = append(result, Violation{source, string(name), fmt.Sprintf("%s max limit to request ratio per %s is %s, %s has %.2f (limit %s, request %s)", name, item.Type, ratio.String(), what, actual, limit.String(), request.String())})
+		}
+	}
+	return result
+}
+
+func difference(a, b resource.Quantity) string {
+	d := a.DeepCopy()
+	d.Sub(b)
+	return d.String()
+}
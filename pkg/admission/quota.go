@@ -0,0 +1,205 @@
+package admission
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	v1qos "k8s.io/kubectl/pkg/util/qos"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+)
+
+// computeResources are the quota resources every container must set once a quota tracks them.
+var computeResources = []v1.ResourceName{
+	v1.ResourceRequestsCPU, v1.ResourceRequestsMemory, v1.ResourceLimitsCPU, v1.ResourceLimitsMemory,
+	v1.ResourceCPU, v1.ResourceMemory,
+}
+
+// PodUsage returns what the pod is charged against a ResourceQuota.
+func PodUsage(pod *v1.Pod) v1.ResourceList {
+	requests, limits := resourcehelper.PodRequestsAndLimits(pod)
+	usage := v1.ResourceList{
+		v1.ResourcePods:               resource.MustParse("1"),
+		v1.ResourceName("count/pods"): resource.MustParse("1"),
+	}
+	for name, quantity := range requests {
+		usage[v1.ResourceName("requests."+string(name))] = quantity
+		if name == v1.ResourceCPU || name == v1.ResourceMemory || name == v1.ResourceEphemeralStorage {
+			usage[name] = quantity
+		}
+	}
+	for name, quantity := range limits {
+		usage[v1.ResourceName("limits."+string(name))] = quantity
+	}
+	return usage
+}
+
+// QuotaMatchesPod reports whether the pod falls into the scopes of the quota.
+func QuotaMatchesPod(quota *v1.ResourceQuota, pod *v1.Pod) bool {
+	for _, scope := range quota.Spec.Scopes {
+		if !scopeMatches(v1.ScopedResourceSelectorRequirement{ScopeName: scope, Operator: v1.ScopeSelectorOpExists}, pod) {
+			return false
+		}
+	}
+	if quota.Spec.ScopeSelector != nil {
+		for _, requirement := range quota.Spec.ScopeSelector.MatchExpressions {
+			if !scopeMatches(requirement, pod) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func scopeMatches(requirement v1.ScopedResourceSelectorRequirement, pod *v1.Pod) bool {
+	switch requirement.ScopeName {
+	case v1.ResourceQuotaScopeTerminating:
+		return pod.Spec.ActiveDeadlineSeconds != nil && *pod.Spec.ActiveDeadlineSeconds >= 0
+	case v1.ResourceQuotaScopeNotTerminating:
+		return pod.Spec.ActiveDeadlineSeconds == nil || *pod.Spec.ActiveDeadlineSeconds < 0
+	case v1.ResourceQuotaScopeBestEffort:
+		return v1qos.GetPodQOS(pod) == v1.PodQOSBestEffort
+	case v1.ResourceQuotaScopeNotBestEffort:
+		return v1qos.GetPodQOS(pod) != v1.PodQOSBestEffort
+	case v1.ResourceQuotaScopePriorityClass:
+		values := sets.New(requirement.Values...)
+		switch requirement.Operator {
+		case v1.ScopeSelectorOpExists:
+			return pod.Spec.PriorityClassName != ""
+		case v1.ScopeSelectorOpDoesNotExist:
+			return pod.Spec.PriorityClassName == ""
+		case v1.ScopeSelectorOpIn:
+			return values.Has(pod.Spec.PriorityClassName)
+		case v1.ScopeSelectorOpNotIn:
+			return !values.Has(pod.Spec.PriorityClassName)
+		}
+	case v1.ResourceQuotaScopeCrossNamespacePodAffinity:
+		return usesCrossNamespaceAffinity(pod)
+	}
+	return false
+}
+
+func usesCrossNamespaceAffinity(pod *v1.Pod) bool {
+	affinity := pod.Spec.Affinity
+	if affinity == nil {
+		return false
+	}
+	var terms []v1.PodAffinityTerm
+	if affinity.PodAffinity != nil {
+		terms = append(terms, affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		for _, weighted := range affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms = append(terms, weighted.PodAffinityTerm)
+		}
+	}
+	if affinity.PodAntiAffinity != nil {
+		terms = append(terms, affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		for _, weighted := range affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms = append(terms, weighted.PodAffinityTerm)
+		}
+	}
+	for _, term := range terms {
+		if len(term.Namespaces) > 0 || term.NamespaceSelector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// QuotaHeadroom is what is left of one quota resource after admitting the pod.
+type QuotaHeadroom struct {
+	Source   string
+	Resource v1.ResourceName
+	Want     resource.Quantity
+	Used     resource.Quantity
+	Hard     resource.Quantity
+}
+
+// Fits returns how many pods asking Want still fit in the quota.
+func (h *QuotaHeadroom) Fits() int64 {
+	if h.Want.IsZero() {
+		return -1
+	}
+	left := h.Hard.DeepCopy()
+	left.Sub(h.Used)
+	if left.Sign() <= 0 {
+		return 0
+	}
+	return left.MilliValue() / h.Want.MilliValue()
+}
+
+// CheckQuotas charges the pod against every quota whose scopes it matches, and returns the
+// violations along with the headroom of each tracked resource. A pod read from the cluster
+// is already part of the quota usage, it is taken out before being charged again.
+func CheckQuotas(pod *v1.Pod, quotas []v1.ResourceQuota) ([]Violation, []*QuotaHeadroom) {
+	usage := PodUsage(pod)
+	existing := pod.UID != "" && pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed
+	var violations []Violation
+	var headrooms []*QuotaHeadroom
+	for i := range quotas {
+		quota := &quotas[i]
+		if !QuotaMatchesPod(quota, pod) {
+			continue
+		}
+		source := "ResourceQuota/" + quota.Name
+
+		var missing []string
+		for _, name := range computeResources {
+			if _, tracked := quota.Spec.Hard[name]; !tracked {
+				continue
+			}
+			if containers := containersMissing(pod, name); len(containers) > 0 {
+				missing = append(missing, name.String())
+				violations = append(violations, Violation{source, name.String(),
+					fmt.Sprintf("must specify %s for: %s", name, strings.Join(containers, ","))})
+			}
+		}
+
+		for name, hard := range quota.Spec.Hard {
+			want, ok := usage[name]
+			if !ok || sets.New(missing...).Has(name.String()) {
+				continue
+			}
+			used := quota.Status.Used[name].DeepCopy()
+			if existing {
+				used.Sub(want)
+				if used.Sign() < 0 {
+					used.Set(0)
+				}
+			}
+			headrooms = append(headrooms, &QuotaHeadroom{Source: source, Resource: name, Want: want, Used: used, Hard: hard})
+
+			total := used.DeepCopy()
+			total.Add(want)
+			if total.Cmp(hard) > 0 {
+				violations = append(violations, Violation{source, name.String(),
+					fmt.Sprintf("exceeded quota: requested %s, used %s, limited %s: %s over", want.String(), used.String(), hard.String(), difference(total, hard))})
+			}
+		}
+	}
+	return violations, headrooms
+}
+
+// containersMissing returns the containers not setting the compute resource a quota tracks.
+func containersMissing(pod *v1.Pod, name v1.ResourceName) []string {
+	var result []string
+	for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			var list v1.ResourceList
+			resourceName := name
+			switch {
+			case strings.HasPrefix(string(name), "limits."):
+				list, resourceName = container.Resources.Limits, v1.ResourceName(strings.TrimPrefix(string(name), "limits."))
+			case strings.HasPrefix(string(name), "requests."):
+				list, resourceName = container.Resources.Requests, v1.ResourceName(strings.TrimPrefix(string(name), "requests."))
+			default:
+				list = container.Resources.Requests
+			}
+			if _, ok := list[resourceName]; !ok {
+				result = append(result, container.Name)
+			}
+		}
+	}
+	return result
+}
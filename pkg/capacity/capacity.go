@@ -0,0 +1,201 @@
+package capacity
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+)
+
+type CapacityEstimator struct {
+	ClientSet *kubernetes.Clientset
+
+	Namespace string
+	// Template is a kind/name reference to an existing pod or workload, used instead of an ad-hoc shape.
+	Template string
+	CPU      string
+	Memory   string
+	Extended map[string]string
+	// Replicas is the number of copies asked for, 0 only prints what fits.
+	Replicas int64
+}
+
+type NodeCapacity struct {
+	NodeName  string
+	Fit       int64
+	LimitedBy string
+	Left      *framework.Resource
+	Reason    util.ColorTextList
+}
+
+func (nc *NodeCapacity) ToStringList() []string {
+	limitedBy, left := "-", "-"
+	if nc.LimitedBy != "" {
+		limitedBy = nc.LimitedBy
+	}
+	if nc.Left != nil {
+		left = nc.Left.String()
+	}
+	return []string{nc.NodeName, fmt.Sprintf("%d", nc.Fit), limitedBy, left, nc.Reason.String()}
+}
+
+// BuildPod returns the pod whose copies are counted, either from the template or from the ad-hoc shape.
+func (c *CapacityEstimator) BuildPod() (*v1.Pod, error) {
+	adHoc := c.CPU != "" || c.Memory != "" || len(c.Extended) > 0
+	if c.Template != "" && adHoc {
+		return nil, fmt.Errorf("use either a template or --cpu/--memory/--extended, not both")
+	}
+
+	if c.Template != "" {
+		pod, err := workloads.PodFromRef(c.ClientSet, c.Namespace, c.Template)
+		if err != nil {
+			return nil, err
+		}
+		pod = pod.DeepCopy()
+		pod.Spec.NodeName = ""
+		return pod, nil
+	}
+
+	if !adHoc {
+		return nil, fmt.Errorf("a template or at least one of --cpu, --memory, --extended is required")
+	}
+
+	requests := v1.ResourceList{}
+	shape := map[string]string{}
+	for name, value := range c.Extended {
+		shape[name] = value
+	}
+	if c.CPU != "" {
+		shape[string(v1.ResourceCPU)] = c.CPU
+	}
+	if c.Memory != "" {
+		shape[string(v1.ResourceMemory)] = c.Memory
+	}
+	for name, value := range shape {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for %s: %w", value, name, err)
+		}
+		requests[v1.ResourceName(name)] = quantity
+	}
+
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "capacity-probe", Namespace: c.Namespace},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name:      "probe",
+				Resources: v1.ResourceRequirements{Requests: requests, Limits: requests.DeepCopy()},
+			}},
+		},
+	}, nil
+}
+
+func (c *CapacityEstimator) Estimate() error {
+
+	pod, err := c.BuildPod()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := scheduler.TakeSnapshot(c.ClientSet)
+	if err != nil {
+		return err
+	}
+
+	result := EstimateOnSnapshot(c.ClientSet, pod, snapshot)
+	printCapacity(result)
+
+	var total int64
+	for _, nc := range result {
+		total += nc.Fit
+	}
+	fmt.Printf("\n%d more replicas of %s/%s fit in the cluster\n", total, pod.Namespace, pod.Name)
+	if c.Replicas > 0 {
+		if total >= c.Replicas {
+			fmt.Println(util.NewGreenText(fmt.Sprintf("%d replicas requested: OK", c.Replicas)))
+		} else {
+			fmt.Println(util.NewRedText(fmt.Sprintf("%d replicas requested: short by %d", c.Replicas, c.Replicas-total)))
+		}
+	}
+	return nil
+}
+
+// EstimateOnSnapshot counts per node how many copies of pod still fit. Nodes ruled out by
+// the analyzer's filters fit 0 and carry the reason, the others are bounded by what is left
+// of each requested resource and of the node's pod capacity.
+func EstimateOnSnapshot(clientSet kubernetes.Interface, pod *v1.Pod, snapshot *scheduler.Snapshot) []*NodeCapacity {
+
+	analyzer := scheduler.NewAnalyzerForPod(clientSet, pod, snapshot)
+	want := framework.BuildPodResourceList(pod)
+	want[string(v1.ResourcePods)] = &framework.Resource{Name: string(v1.ResourcePods), Requests: 1000}
+	onePerNode := hasSelfHostnameAntiAffinity(pod)
+
+	var result []*NodeCapacity
+	for i := range snapshot.Nodes {
+		node := &snapshot.Nodes[i]
+		nc := &NodeCapacity{NodeName: strings.Split(node.Name, "-")[0]}
+		result = append(result, nc)
+
+		report := analyzer.DiagnoseNodeChecks(node, scheduler.CheckUnschedulable, scheduler.CheckNodeSelector,
+			scheduler.CheckNodeAffinity, scheduler.CheckToleration, scheduler.CheckPV, scheduler.CheckPodAffinity)
+		if !report.Feasible() {
+			nc.Reason = report.FailedReasons()
+			continue
+		}
+
+		have := snapshot.NodeResources[node.Name]
+		nc.Fit, nc.LimitedBy = have.MaxFit(want)
+		if h, ok := have[nc.LimitedBy]; ok {
+			nc.Left = &framework.Resource{Name: h.Name, Requests: h.Left}
+		}
+		if onePerNode && nc.Fit > 1 {
+			nc.Fit, nc.LimitedBy = 1, "podAntiAffinity"
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Fit > result[j].Fit
+	})
+	return result
+}
+
+// hasSelfHostnameAntiAffinity reports whether replicas of the pod repel each other per node,
+// in which case a node never takes more than one of them.
+func hasSelfHostnameAntiAffinity(pod *v1.Pod) bool {
+	terms, err := framework.GetAffinityTerms(pod, framework.GetPodAntiAffinityTerms(pod.Spec.Affinity))
+	if err != nil {
+		return false
+	}
+	for _, term := range terms {
+		if term.TopologyKey == v1.LabelHostname && term.Matches(pod, nil) {
+			return true
+		}
+	}
+	return false
+}
+
+func printCapacity(result []*NodeCapacity) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"nodeName", "fit", "limitedBy", "left", "reason"})
+	for _, nc := range result {
+		t.AppendRow(util.ListToRow(nc.ToStringList()))
+	}
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
@@ -0,0 +1,90 @@
+package capacity
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/scheduler/schedulertest"
+)
+
+func TestEstimateOnSnapshot(t *testing.T) {
+	cordoned := schedulertest.Node("node4", map[string]string{v1.LabelHostname: "node4"}, "8", "16Gi")
+	cordoned.Spec.Unschedulable = true
+	nodes := []v1.Node{
+		schedulertest.Node("node1", map[string]string{v1.LabelHostname: "node1"}, "4", "16Gi"),
+		schedulertest.Node("node2", map[string]string{v1.LabelHostname: "node2"}, "8", "4Gi"),
+		schedulertest.Node("node3", map[string]string{v1.LabelHostname: "node3"}, "8", "16Gi"),
+		cordoned,
+	}
+	// node3 has room for one more pod only
+	nodes[2].Status.Allocatable[v1.ResourcePods] = resource.MustParse("2")
+	pods := []v1.Pod{
+		schedulertest.Pod("used-1", "node1", nil, "1", "1Gi"),
+		schedulertest.Pod("used-3", "node3", nil, "1", "1Gi"),
+	}
+	antiAffinity := schedulertest.Pod("web", "", map[string]string{"app": "web"}, "1", "1Gi")
+	antiAffinity.Spec.Affinity = &v1.Affinity{PodAntiAffinity: &v1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			TopologyKey:   v1.LabelHostname,
+		}},
+	}}
+
+	type fit struct {
+		NodeName  string
+		Fit       int64
+		LimitedBy string
+		Reason    string
+	}
+	type args struct {
+		pod v1.Pod
+	}
+	tests := []struct {
+		name string
+		args args
+		want []fit
+	}{
+		{
+			name: "limited by each resource",
+			args: args{pod: schedulertest.Pod("probe", "", nil, "1", "1Gi")},
+			want: []fit{
+				{NodeName: "node2", Fit: 4, LimitedBy: "memory"},
+				{NodeName: "node1", Fit: 3, LimitedBy: "cpu"},
+				{NodeName: "node3", Fit: 1, LimitedBy: "pods"},
+				{NodeName: "node4", Reason: "Unschedulable: pod not tolerate unschedulable"},
+			},
+		},
+		{
+			name: "one replica per node with hostname anti affinity",
+			args: args{pod: antiAffinity},
+			want: []fit{
+				{NodeName: "node1", Fit: 1, LimitedBy: "podAntiAffinity"},
+				{NodeName: "node2", Fit: 1, LimitedBy: "podAntiAffinity"},
+				{NodeName: "node3", Fit: 1, LimitedBy: "pods"},
+				{NodeName: "node4", Reason: "Unschedulable: pod not tolerate unschedulable"},
+			},
+		},
+	}
+	clientSet := schedulertest.ClientSet()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []fit
+			for _, nc := range EstimateOnSnapshot(clientSet, &tt.args.pod, scheduler.NewSnapshot(clientSet, nodes, pods)) {
+				var reasons []string
+				for _, ct := range nc.Reason {
+					reasons = append(reasons, ct.Text)
+				}
+				got = append(got, fit{NodeName: nc.NodeName, Fit: nc.Fit, LimitedBy: nc.LimitedBy, Reason: strings.Join(reasons, " ")})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EstimateOnSnapshot() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
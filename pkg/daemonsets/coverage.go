@@ -0,0 +1,243 @@
+package daemonsets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	componenthelpers "k8s.io/component-helpers/scheduling/corev1"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+)
+
+const (
+	NodeMissing  = "Missing"
+	NodeExcluded = "Excluded"
+)
+
+type CoverageReporter struct {
+	ClientSet *kubernetes.Clientset
+
+	Namespace    string
+	Name         string
+	ShowExcluded bool
+}
+
+type NodeCoverage struct {
+	NodeName string
+	Status   string
+	Reason   util.ColorTextList
+}
+
+type DaemonSetCoverage struct {
+	DaemonSet *appsv1.DaemonSet
+	Covered   int
+	Nodes     []*NodeCoverage
+}
+
+func (c *DaemonSetCoverage) count(status string) int {
+	n := 0
+	for _, node := range c.Nodes {
+		if node.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *DaemonSetCoverage) ToStringList() []string {
+	missing := util.NewGreenText("0")
+	if n := c.count(NodeMissing); n > 0 {
+		missing = util.NewRedText(fmt.Sprintf("%d", n))
+	}
+	status := c.DaemonSet.Status
+	return []string{
+		fmt.Sprintf("%s/%s", c.DaemonSet.Namespace, c.DaemonSet.Name),
+		fmt.Sprintf("%d", status.DesiredNumberScheduled),
+		fmt.Sprintf("%d", status.CurrentNumberScheduled),
+		fmt.Sprintf("%d", status.NumberReady),
+		fmt.Sprintf("%d", c.Covered),
+		missing.String(),
+		fmt.Sprintf("%d", c.count(NodeExcluded)),
+	}
+}
+
+// DaemonPodTolerations are the tolerations the DaemonSet controller adds to every pod it creates.
+func DaemonPodTolerations(pod *v1.Pod) []v1.Toleration {
+	tolerations := []v1.Toleration{
+		{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+		{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+		{Key: v1.TaintNodeDiskPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: v1.TaintNodeMemoryPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: v1.TaintNodePIDPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: v1.TaintNodeUnschedulable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	}
+	if pod.Spec.HostNetwork {
+		tolerations = append(tolerations, v1.Toleration{Key: v1.TaintNodeNetworkUnavailable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule})
+	}
+	return tolerations
+}
+
+func (c *CoverageReporter) GetCoverage() error {
+
+	listOptions := metav1.ListOptions{}
+	if c.Name != "" {
+		listOptions.FieldSelector = fmt.Sprintf("metadata.name=%s", c.Name)
+	}
+	daemonSets, err := c.ClientSet.AppsV1().DaemonSets(c.Namespace).List(context.TODO(), listOptions)
+	if err != nil {
+		return fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	if len(daemonSets.Items) == 0 {
+		return fmt.Errorf("no daemonset found")
+	}
+
+	snapshot, err := scheduler.TakeSnapshot(c.ClientSet)
+	if err != nil {
+		return err
+	}
+
+	var coverages []*DaemonSetCoverage
+	for i := range daemonSets.Items {
+		coverages = append(coverages, CoverageOnSnapshot(c.ClientSet, &daemonSets.Items[i], snapshot))
+	}
+
+	printSummary(coverages)
+	for _, coverage := range coverages {
+		printNodes(coverage, c.ShowExcluded)
+	}
+	return nil
+}
+
+// CoverageOnSnapshot compares the nodes running a pod of ds with every node of the cluster and
+// runs the analyzer's nodeSelector, node affinity, taint and resource checks with the pod
+// template on the nodes without one.
+func CoverageOnSnapshot(clientSet kubernetes.Interface, ds *appsv1.DaemonSet, snapshot *scheduler.Snapshot) *DaemonSetCoverage {
+	coverage := &DaemonSetCoverage{DaemonSet: ds}
+
+	running, pending := map[string]bool{}, map[string]string{}
+	for i := range snapshot.Pods {
+		pod := &snapshot.Pods[i]
+		if ref := metav1.GetControllerOf(pod); ref == nil || ref.UID != ds.UID || workloads.IsTerminated(pod) {
+			continue
+		}
+		if pod.Spec.NodeName != "" {
+			running[pod.Spec.NodeName] = true
+		} else if target := targetNode(pod); target != "" {
+			pending[target] = pod.Name
+		}
+	}
+
+	pod := workloads.PodFromTemplate(ds.Namespace, ds.Name, &ds.Spec.Template)
+	pod.Spec.Tolerations = append(pod.Spec.Tolerations, DaemonPodTolerations(pod)...)
+	analyzer := scheduler.NewAnalyzerForPod(clientSet, pod, snapshot)
+
+	for i := range snapshot.Nodes {
+		node := &snapshot.Nodes[i]
+		if running[node.Name] {
+			coverage.Covered++
+			continue
+		}
+
+		nodeCoverage := &NodeCoverage{NodeName: strings.Split(node.Name, "-")[0], Status: NodeExcluded}
+		report := analyzer.DiagnoseNodeChecks(node, scheduler.CheckNodeSelector, scheduler.CheckNodeAffinity,
+			scheduler.CheckToleration, scheduler.CheckResource)
+		static := report.NodeSelectorReason.HasRed() || report.NodeAffinityReason.HasRed() || untoleratedTaint(node, pod.Spec.Tolerations)
+		if !static {
+			// only PreferNoSchedule taints are left, the controller ignores them
+			report.TolerationReason = nil
+		}
+		nodeCoverage.Reason = report.FailedReasons()
+		if !static {
+			nodeCoverage.Status = NodeMissing
+			if name, ok := pending[node.Name]; ok {
+				nodeCoverage.Reason = append(util.ColorTextList{util.NewRedText(fmt.Sprintf("pod %s is Pending", name))}, nodeCoverage.Reason...)
+			}
+			if len(nodeCoverage.Reason) == 0 {
+				nodeCoverage.Reason = util.ColorTextList{util.NewRedText("all checks pass, the controller should create a pod here")}
+			}
+		}
+		coverage.Nodes = append(coverage.Nodes, nodeCoverage)
+	}
+	return coverage
+}
+
+// untoleratedTaint reports whether the node has a NoSchedule or NoExecute taint the tolerations do
+// not tolerate, the effects the DaemonSet controller honors.
+func untoleratedTaint(node *v1.Node, tolerations []v1.Toleration) bool {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !componenthelpers.TolerationsTolerateTaint(tolerations, taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// targetNode returns the node an unscheduled DaemonSet pod is pinned to by the controller's
+// metadata.name node affinity.
+func targetNode(pod *v1.Pod) string {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil || pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, field := range term.MatchFields {
+			if field.Key == metav1.ObjectNameField && len(field.Values) == 1 {
+				return field.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+func printSummary(coverages []*DaemonSetCoverage) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Namespace/DaemonSet", "Desired", "Current", "Ready", "Covered", "Missing", "Excluded"})
+	for _, coverage := range coverages {
+		t.AppendRow(util.ListToRow(coverage.ToStringList()))
+	}
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Render()
+}
+
+func printNodes(coverage *DaemonSetCoverage, showExcluded bool) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"nodeName", "Status", "Reason"})
+	rows := 0
+	for _, node := range coverage.Nodes {
+		if node.Status == NodeExcluded && !showExcluded {
+			continue
+		}
+		status := util.ColorText{Text: node.Status}
+		if node.Status == NodeMissing {
+			status = util.NewRedText(node.Status)
+		}
+		t.AppendRow(table.Row{node.NodeName, status.String(), node.Reason.String()})
+		rows++
+	}
+	if rows == 0 {
+		return
+	}
+	fmt.Printf("\n%s/%s\n", coverage.DaemonSet.Namespace, coverage.DaemonSet.Name)
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
@@ -0,0 +1,127 @@
+package daemonsets
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/scheduler/schedulertest"
+)
+
+func TestCoverageOnSnapshot(t *testing.T) {
+	worker := map[string]string{"role": "worker"}
+	ds := newTestDaemonSet("agent", worker, "500m")
+	tainted := schedulertest.Node("node5", worker, "4", "8Gi")
+	tainted.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}}
+	notReady := schedulertest.Node("node7", worker, "4", "8Gi")
+	notReady.Spec.Taints = []v1.Taint{{Key: v1.TaintNodeNotReady, Effect: v1.TaintEffectNoExecute}}
+	preferred := schedulertest.Node("node8", worker, "4", "8Gi")
+	preferred.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "batch", Effect: v1.TaintEffectPreferNoSchedule}}
+	nodes := []v1.Node{
+		schedulertest.Node("node1", worker, "4", "8Gi"),
+		schedulertest.Node("node2", worker, "4", "8Gi"),
+		schedulertest.Node("node3", worker, "4", "8Gi"),
+		schedulertest.Node("node4", map[string]string{"role": "master"}, "4", "8Gi"),
+		tainted,
+		schedulertest.Node("node6", worker, "250m", "8Gi"),
+		notReady,
+		preferred,
+	}
+	pods := []v1.Pod{
+		newTestDaemonPod(ds, "agent-a", "node1", v1.PodRunning),
+		newTestDaemonPod(ds, "agent-b", "node3", v1.PodPending),
+		newTestDaemonPod(ds, "agent-c", "node2", v1.PodFailed),
+	}
+
+	type node struct {
+		NodeName string
+		Status   string
+		Reason   string
+	}
+	tests := []struct {
+		name        string
+		wantCovered int
+		want        []node
+	}{
+		{
+			name:        "coverage gaps",
+			wantCovered: 1,
+			want: []node{
+				{NodeName: "node2", Status: NodeMissing, Reason: "all checks pass, the controller should create a pod here"},
+				{NodeName: "node3", Status: NodeMissing, Reason: "pod agent-b is Pending"},
+				{NodeName: "node4", Status: NodeExcluded, Reason: "nodeSelector: role:worker"},
+				{NodeName: "node5", Status: NodeExcluded, Reason: "Toleration: dedicated,gpu,NoSchedule"},
+				{NodeName: "node6", Status: NodeMissing, Reason: "resource: cpu: want 500m, have 250m left"},
+				{NodeName: "node7", Status: NodeMissing, Reason: "all checks pass, the controller should create a pod here"},
+				{NodeName: "node8", Status: NodeMissing, Reason: "all checks pass, the controller should create a pod here"},
+			},
+		},
+	}
+	clientSet := schedulertest.ClientSet()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CoverageOnSnapshot(clientSet, ds, scheduler.NewSnapshot(clientSet, nodes, pods))
+			if got.Covered != tt.wantCovered {
+				t.Errorf("CoverageOnSnapshot() covered = %d, want %d", got.Covered, tt.wantCovered)
+			}
+			var gotNodes []node
+			for _, nc := range got.Nodes {
+				reason := ""
+				for _, ct := range nc.Reason {
+					if ct.Text != "" {
+						reason = ct.Text
+						break
+					}
+				}
+				gotNodes = append(gotNodes, node{NodeName: nc.NodeName, Status: nc.Status, Reason: reason})
+			}
+			if !reflect.DeepEqual(gotNodes, tt.want) {
+				t.Errorf("CoverageOnSnapshot() nodes = %+v, want %+v", gotNodes, tt.want)
+			}
+		})
+	}
+}
+
+// newTestDaemonSet returns a DaemonSet in kube-system selecting the nodes by nodeSelector, its
+// pods request cpu.
+func newTestDaemonSet(name string, nodeSelector map[string]string, cpu string) *appsv1.DaemonSet {
+	requests := v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)}
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: name, UID: types.UID(name)},
+		Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+			Spec: v1.PodSpec{
+				NodeSelector: nodeSelector,
+				Containers:   []v1.Container{{Name: name, Resources: v1.ResourceRequirements{Requests: requests, Limits: requests}}},
+			},
+		}},
+	}
+}
+
+// newTestDaemonPod returns a pod of ds, bound to nodeName when running or pinned to it by the
+// controller's node affinity when pending.
+func newTestDaemonPod(ds *appsv1.DaemonSet, name, nodeName string, phase v1.PodPhase) v1.Pod {
+	controller := true
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ds.Namespace, Name: name, UID: types.UID(name), Labels: ds.Spec.Template.Labels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: ds.Name, UID: ds.UID, Controller: &controller}}},
+		Spec:   *ds.Spec.Template.Spec.DeepCopy(),
+		Status: v1.PodStatus{Phase: phase},
+	}
+	if phase != v1.PodPending {
+		pod.Spec.NodeName = nodeName
+		return pod
+	}
+	pod.Spec.Affinity = &v1.Affinity{NodeAffinity: &v1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+		NodeSelectorTerms: []v1.NodeSelectorTerm{{MatchFields: []v1.NodeSelectorRequirement{
+			{Key: metav1.ObjectNameField, Operator: v1.NodeSelectorOpIn, Values: []string{nodeName}},
+		}}},
+	}}}
+	return pod
+}
@@ -0,0 +1,85 @@
+package diagnosis
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func newTestNode(name string, ready v1.ConditionStatus) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: ready, Reason: "KubeletReady"},
+		}},
+	}
+}
+
+func newTestPod(namespace, name, nodeName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       v1.PodSpec{NodeName: nodeName, Containers: []v1.Container{{Name: "main"}}},
+	}
+}
+
+// newTestOwnedPod returns a pod controlled by owner, running on nodeName and ready or not, or
+// pending when nodeName is empty.
+func newTestOwnedPod(owner metav1.Object, kind, name, nodeName string, ready bool) *v1.Pod {
+	pod := newTestPod(owner.GetNamespace(), name, nodeName)
+	pod.UID = types.UID(name)
+	pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, appsv1.SchemeGroupVersion.WithKind(kind))}
+	if nodeName == "" {
+		pod.Status.Phase = v1.PodPending
+		return pod
+	}
+	readyStatus := v1.ConditionFalse
+	if ready {
+		readyStatus = v1.ConditionTrue
+	}
+	pod.Status.Phase = v1.PodRunning
+	pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: readyStatus}}
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{Name: "main", Ready: ready, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}}}
+	return pod
+}
+
+// newTestDeployment returns a Deployment at revision rolling one pod at a time: maxSurge 1,
+// maxUnavailable 0.
+func newTestDeployment(name string, replicas int32, revision string) *appsv1.Deployment {
+	maxSurge, maxUnavailable := intstr.FromInt32(1), intstr.FromInt32(0)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: name, UID: types.UID(name),
+			Annotations: map[string]string{annDeploymentRevision: revision}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Strategy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge, MaxUnavailable: &maxUnavailable},
+			},
+		},
+	}
+}
+
+func newTestReplicaSet(deploy *appsv1.Deployment, revision string, replicas int32) *appsv1.ReplicaSet {
+	name := deploy.Name + "-" + revision
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: deploy.Namespace, Name: name, UID: types.UID(name),
+			Annotations:     map[string]string{annDeploymentRevision: revision},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deploy, appsv1.SchemeGroupVersion.WithKind("Deployment"))}},
+		Spec:   appsv1.ReplicaSetSpec{Replicas: &replicas},
+		Status: appsv1.ReplicaSetStatus{Replicas: replicas},
+	}
+}
+
+// newTestStatefulSet returns an OrderedReady StatefulSet rolling from revision <name>-1 to <name>-2.
+func newTestStatefulSet(name string, replicas int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: name, UID: types.UID(name)},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:       &replicas,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+		},
+		Status: appsv1.StatefulSetStatus{CurrentRevision: name + "-1", UpdateRevision: name + "-2"},
+	}
+}
@@ -0,0 +1,266 @@
+package diagnosis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	nodeLeaseNamespace = "kube-node-lease"
+	// default tolerationSeconds the DefaultTolerationSeconds admission plugin adds for not-ready/unreachable
+	defaultTolerationSeconds = 300
+	// evidence lists longer than this are cut
+	maxEvidence = 20
+)
+
+// lifecycleTaints are the taints set by the node lifecycle controller and the kubelet.
+var lifecycleTaints = []string{
+	v1.TaintNodeNotReady,
+	v1.TaintNodeUnreachable,
+	v1.TaintNodeUnschedulable,
+	v1.TaintNodeMemoryPressure,
+	v1.TaintNodeDiskPressure,
+	v1.TaintNodePIDPressure,
+	v1.TaintNodeNetworkUnavailable,
+	v1.TaintNodeOutOfService,
+}
+
+var nodeEventReasons = []string{
+	"NodeNotReady", "NodeReady", "NodeStatusUnknown", "Rebooted", "NodeHasDiskPressure", "NodeHasInsufficientMemory",
+	"NodeHasInsufficientPID", "SystemOOM", "OOMKilling", "EvictionThresholdMet", "FreeDiskSpaceFailed",
+	"ImageGCFailed", "ContainerGCFailed", "KubeletSetupFailed", "InvalidDiskCapacity",
+}
+
+// NodeDiagnoser explains why a node is NotReady and what is affected by it.
+type NodeDiagnoser struct {
+	ClientSet kubernetes.Interface
+
+	NodeName string
+}
+
+func (n *NodeDiagnoser) WhyNotReady() error {
+	node, err := n.ClientSet.CoreV1().Nodes().Get(context.TODO(), n.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node %s: %w", n.NodeName, err)
+	}
+
+	n.diagnose(node).Print()
+	return nil
+}
+
+func (n *NodeDiagnoser) diagnose(node *v1.Node) *Diagnosis {
+	ready := readyCondition(node)
+	status := "Unknown"
+	if ready != nil {
+		status = string(ready.Status)
+	}
+	d := NewDiagnosis(fmt.Sprintf("node %s (Ready=%s, kubelet %s)", node.Name, status, node.Status.NodeInfo.KubeletVersion))
+
+	leaseExpired := n.checkLease(node, d)
+	n.checkConditions(node, ready, leaseExpired, d)
+	checkLifecycleTaints(node, d)
+	n.checkVersionSkew(node, d)
+
+	events := ListEvents(n.ClientSet, "", "Node", node.Name)
+	if history := EventsWithReason(events, nodeEventReasons...); len(history) > 0 {
+		d.Add(PriorityWarning, "NodeEvents", "recent node events", tail(history)...)
+	}
+
+	if ready == nil || ready.Status != v1.ConditionTrue {
+		n.checkAffectedPods(node, d)
+	}
+	if ready != nil && ready.Status == v1.ConditionTrue {
+		d.Add(PriorityHealthy, "Ready", fmt.Sprintf("node is Ready since %s ago", Age(ready.LastTransitionTime.Time)))
+	}
+	return d
+}
+
+func readyCondition(node *v1.Node) *v1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == v1.NodeReady {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// checkLease reports whether the kubelet stopped renewing its node lease.
+func (n *NodeDiagnoser) checkLease(node *v1.Node, d *Diagnosis) bool {
+	lease, err := n.ClientSet.CoordinationV1().Leases(nodeLeaseNamespace).Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		d.Add(PriorityRuntime, "NoLease", fmt.Sprintf("lease %s/%s does not exist, the kubelet never registered a heartbeat", nodeLeaseNamespace, node.Name))
+		return true
+	}
+	if err != nil || lease.Spec.RenewTime == nil {
+		return false
+	}
+	duration := 40 * time.Second
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	if time.Since(lease.Spec.RenewTime.Time) <= duration {
+		return false
+	}
+	d.Add(PriorityRuntime, "LeaseExpired",
+		fmt.Sprintf("kubelet last renewed its lease %s ago: the kubelet is down, the node is powered off or cannot reach the apiserver", Age(lease.Spec.RenewTime.Time)),
+		fmt.Sprintf("lease duration %s", duration))
+	return true
+}
+
+func (n *NodeDiagnoser) checkConditions(node *v1.Node, ready *v1.NodeCondition, leaseExpired bool, d *Diagnosis) {
+	var history []string
+	for _, condition := range node.Status.Conditions {
+		history = append(history, fmt.Sprintf("%s=%s since %s ago, last heartbeat %s ago: %s",
+			condition.Type, condition.Status, Age(condition.LastTransitionTime.Time), Age(condition.LastHeartbeatTime.Time), condition.Reason))
+
+		if condition.Type != v1.NodeReady && condition.Status == v1.ConditionTrue {
+			d.Add(PriorityConfig, string(condition.Type), condition.Message, fmt.Sprintf("since %s ago", Age(condition.LastTransitionTime.Time)))
+		}
+	}
+
+	switch {
+	case ready == nil:
+		d.Add(PriorityBlocker, "NoReadyCondition", "node has no Ready condition, the kubelet never posted its status", history...)
+	case ready.Status == v1.ConditionUnknown:
+		priority := PrioritySymptom
+		if !leaseExpired {
+			// the heartbeat is alive, the status updates are what is failing
+			priority = PriorityRuntime
+		}
+		d.Add(priority, "NodeStatusUnknown", fmt.Sprintf("node controller lost the kubelet's status %s ago: %s", Age(ready.LastTransitionTime.Time), ready.Message), history...)
+	case ready.Status == v1.ConditionFalse:
+		// the kubelet is alive and reports itself not ready, its message names the failing part
+		d.Add(PriorityRuntime-5, "KubeletNotReady", ready.Message, history...)
+	default:
+		d.Add(PriorityHealthy+1, "Conditions", "node conditions", history...)
+	}
+}
+
+func checkLifecycleTaints(node *v1.Node, d *Diagnosis) {
+	var taints []string
+	for _, taint := range node.Spec.Taints {
+		for _, key := range lifecycleTaints {
+			if taint.Key != key {
+				continue
+			}
+			line := fmt.Sprintf("%s:%s", taint.Key, taint.Effect)
+			if taint.TimeAdded != nil {
+				line += fmt.Sprintf(" added %s ago", Age(taint.TimeAdded.Time))
+			}
+			taints = append(taints, line)
+		}
+	}
+	if len(taints) > 0 {
+		d.Add(PriorityWarning, "LifecycleTaints", "taints set by the node lifecycle controller and kubelet", taints...)
+	}
+}
+
+func (n *NodeDiagnoser) checkVersionSkew(node *v1.Node, d *Diagnosis) {
+	server, err := n.ClientSet.Discovery().ServerVersion()
+	if err != nil {
+		fmt.Printf("error fetching server version: %v\n", err)
+		return
+	}
+	if skew := util.KubeletSkew(node.Status.NodeInfo.KubeletVersion, server.GitVersion); skew != "" {
+		d.Add(PriorityConfig+5, "VersionSkew", skew)
+	}
+}
+
+// checkAffectedPods lists the pods that will be evicted from the node, and the pods whose
+// ReadWriteOnce volumes stay attached to it.
+func (n *NodeDiagnoser) checkAffectedPods(node *v1.Node, d *Diagnosis) {
+	pods, err := n.ClientSet.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node.Name).String(),
+	})
+	if err != nil {
+		fmt.Printf("error listing pods on node %s: %v\n", node.Name, err)
+		return
+	}
+
+	var noExecute *v1.Taint
+	for i, taint := range node.Spec.Taints {
+		if taint.Effect == v1.TaintEffectNoExecute && (taint.Key == v1.TaintNodeNotReady || taint.Key == v1.TaintNodeUnreachable) {
+			noExecute = &node.Spec.Taints[i]
+		}
+	}
+
+	var evicted, rwo []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if workloads.IsTerminated(pod) || workloads.IsMirrorPod(pod) {
+			continue
+		}
+		if noExecute != nil {
+			if line := evictionOf(pod, noExecute); line != "" {
+				evicted = append(evicted, line)
+			}
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			pvc, err := n.ClientSet.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(context.TODO(), volume.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+			for _, mode := range pvc.Spec.AccessModes {
+				if mode == v1.ReadWriteOnce || mode == v1.ReadWriteOncePod {
+					rwo = append(rwo, fmt.Sprintf("%s/%s: pvc %s (%s)", pod.Namespace, pod.Name, pvc.Name, workloads.OwnerString(pod)))
+					break
+				}
+			}
+		}
+	}
+
+	if len(evicted) > 0 {
+		d.Add(PrioritySymptom+5, "PodsEvicted", fmt.Sprintf("%d pods are or will be evicted by the %s taint", len(evicted), noExecute.Key), tail(evicted)...)
+	}
+	if len(rwo) > 0 {
+		d.Add(PrioritySymptom+5, "RWOVolumesAttached",
+			fmt.Sprintf("%d pods hold ReadWriteOnce volumes, replacements elsewhere cannot attach them until the node is back or tainted %s", len(rwo), v1.TaintNodeOutOfService),
+			tail(rwo)...)
+	}
+}
+
+// evictionOf describes when the pod is evicted because of a NoExecute taint, empty if it tolerates it forever.
+func evictionOf(pod *v1.Pod, taint *v1.Taint) string {
+	seconds := int64(-1)
+	for _, toleration := range pod.Spec.Tolerations {
+		if !toleration.ToleratesTaint(taint) {
+			continue
+		}
+		if toleration.TolerationSeconds == nil {
+			return ""
+		}
+		seconds = *toleration.TolerationSeconds
+	}
+	if seconds < 0 {
+		// pods created before the admission plugin or bypassing it
+		seconds = defaultTolerationSeconds
+	}
+	name := fmt.Sprintf("%s/%s (%s)", pod.Namespace, pod.Name, workloads.OwnerString(pod))
+	if taint.TimeAdded == nil {
+		return fmt.Sprintf("%s after %ds", name, seconds)
+	}
+	deadline := taint.TimeAdded.Add(time.Duration(seconds) * time.Second)
+	if time.Now().After(deadline) {
+		return fmt.Sprintf("%s, evicted %s ago", name, Age(deadline))
+	}
+	return fmt.Sprintf("%s in %s", name, Age(time.Now().Add(-time.Until(deadline))))
+}
+
+func tail(lines []string) []string {
+	if len(lines) <= maxEvidence {
+		return lines
+	}
+	return append([]string{fmt.Sprintf("... %d more", len(lines)-maxEvidence)}, lines[len(lines)-maxEvidence:]...)
+}
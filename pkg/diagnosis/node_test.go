@@ -0,0 +1,244 @@
+package diagnosis
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNodeDiagnoser_checkConditions(t *testing.T) {
+	since := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+	condition := func(conditionType v1.NodeConditionType, status v1.ConditionStatus) v1.NodeCondition {
+		return v1.NodeCondition{Type: conditionType, Status: status, LastTransitionTime: since, Message: string(conditionType) + " message"}
+	}
+
+	tests := []struct {
+		name         string
+		conditions   []v1.NodeCondition
+		leaseExpired bool
+		wantVerdict  string
+		wantPriority int
+	}{
+		{
+			name:         "no ready condition",
+			wantVerdict:  "NoReadyCondition",
+			wantPriority: PriorityBlocker,
+		},
+		{
+			name:         "status unknown and lease expired",
+			conditions:   []v1.NodeCondition{condition(v1.NodeReady, v1.ConditionUnknown)},
+			leaseExpired: true,
+			wantVerdict:  "NodeStatusUnknown",
+			wantPriority: PrioritySymptom,
+		},
+		{
+			name:         "status unknown and lease renewed",
+			conditions:   []v1.NodeCondition{condition(v1.NodeReady, v1.ConditionUnknown)},
+			wantVerdict:  "NodeStatusUnknown",
+			wantPriority: PriorityRuntime,
+		},
+		{
+			name:         "kubelet not ready",
+			conditions:   []v1.NodeCondition{condition(v1.NodeReady, v1.ConditionFalse)},
+			wantVerdict:  "KubeletNotReady",
+			wantPriority: PriorityRuntime - 5,
+		},
+		{
+			name:         "pressure on a ready node",
+			conditions:   []v1.NodeCondition{condition(v1.NodeReady, v1.ConditionTrue), condition(v1.NodeMemoryPressure, v1.ConditionTrue)},
+			wantVerdict:  "MemoryPressure",
+			wantPriority: PriorityConfig,
+		},
+		{
+			name:         "ready",
+			conditions:   []v1.NodeCondition{condition(v1.NodeReady, v1.ConditionTrue), condition(v1.NodeDiskPressure, v1.ConditionFalse)},
+			wantVerdict:  "Conditions",
+			wantPriority: PriorityHealthy + 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Status: v1.NodeStatus{Conditions: tt.conditions}}
+			d := NewDiagnosis("node")
+			(&NodeDiagnoser{}).checkConditions(node, readyCondition(node), tt.leaseExpired, d)
+			verdict := d.Verdict()
+			if verdict.Reason != tt.wantVerdict || verdict.Priority != tt.wantPriority {
+				t.Errorf("checkConditions() verdict = %s (%d), want %s (%d)", verdict.Reason, verdict.Priority, tt.wantVerdict, tt.wantPriority)
+			}
+		})
+	}
+}
+
+func TestNodeDiagnoser_checkLease(t *testing.T) {
+	lease := func(renewed time.Duration, durationSeconds int32) *coordinationv1.Lease {
+		renewTime := metav1.NewMicroTime(time.Now().Add(-renewed))
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: nodeLeaseNamespace, Name: "node1"},
+			Spec:       coordinationv1.LeaseSpec{RenewTime: &renewTime, LeaseDurationSeconds: &durationSeconds},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		lease       *coordinationv1.Lease
+		wantExpired bool
+		wantReason  string
+	}{
+		{
+			name:        "no lease",
+			wantExpired: true,
+			wantReason:  "NoLease",
+		},
+		{
+			name:  "renewed",
+			lease: lease(10*time.Second, 40),
+		},
+		{
+			name:        "stale",
+			lease:       lease(5*time.Minute, 40),
+			wantExpired: true,
+			wantReason:  "LeaseExpired",
+		},
+		{
+			name:  "within a longer lease duration",
+			lease: lease(5*time.Minute, 600),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset()
+			if tt.lease != nil {
+				clientSet = fake.NewSimpleClientset(tt.lease)
+			}
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+			d := NewDiagnosis("node")
+			if expired := (&NodeDiagnoser{ClientSet: clientSet}).checkLease(node, d); expired != tt.wantExpired {
+				t.Errorf("checkLease() = %v, want %v", expired, tt.wantExpired)
+			}
+			reason := ""
+			if verdict := d.Verdict(); verdict != nil {
+				reason = verdict.Reason
+			}
+			if reason != tt.wantReason {
+				t.Errorf("checkLease() verdict = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestNodeDiagnoser_checkVersionSkew(t *testing.T) {
+	tests := []struct {
+		name    string
+		kubelet string
+		want    string
+	}{
+		{name: "supported", kubelet: "v1.27.3"},
+		{name: "too old", kubelet: "v1.24.0", want: "kubelet v1.24.0 is 4 minor versions older than apiserver v1.28.2, at most 3 is supported"},
+		{name: "newer", kubelet: "v1.29.0", want: "kubelet v1.29.0 is newer than apiserver v1.28.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset()
+			clientSet.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.28.2"}
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+			node.Status.NodeInfo.KubeletVersion = tt.kubelet
+			d := NewDiagnosis("node")
+			(&NodeDiagnoser{ClientSet: clientSet}).checkVersionSkew(node, d)
+			got := ""
+			if verdict := d.Verdict(); verdict != nil {
+				got = verdict.Message
+			}
+			if got != tt.want {
+				t.Errorf("checkVersionSkew() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeDiagnoser_checkAffectedPods(t *testing.T) {
+	added := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	unreachable := v1.Taint{Key: v1.TaintNodeUnreachable, Effect: v1.TaintEffectNoExecute, TimeAdded: &added}
+	forever := v1.Toleration{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute}
+	hour := int64(3600)
+
+	withClaim := func(pod *v1.Pod, claim string) *v1.Pod {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{Name: claim, VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+		}})
+		return pod
+	}
+	rwo := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"},
+		Spec: v1.PersistentVolumeClaimSpec{AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}}}
+	rwx := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shared"},
+		Spec: v1.PersistentVolumeClaimSpec{AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteMany}}}
+
+	evictedDefault := newTestPod("default", "web-0", "node1")
+	toleratesForever := newTestPod("default", "agent-0", "node1")
+	toleratesForever.Spec.Tolerations = []v1.Toleration{forever}
+	toleratesHour := newTestPod("default", "cache-0", "node1")
+	toleratesHour.Spec.Tolerations = []v1.Toleration{{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: &hour}}
+	completed := newTestPod("default", "job-0", "node1")
+	completed.Status.Phase = v1.PodSucceeded
+	mirror := newTestPod("kube-system", "etcd-node1", "node1")
+	mirror.Annotations = map[string]string{v1.MirrorPodAnnotationKey: "hash"}
+	database := withClaim(newTestPod("default", "db-0", "node1"), "data")
+	database.Spec.Tolerations = []v1.Toleration{forever}
+	shared := withClaim(newTestPod("default", "files-0", "node1"), "shared")
+	shared.Spec.Tolerations = []v1.Toleration{forever}
+
+	tests := []struct {
+		name   string
+		taints []v1.Taint
+		// wantEvicted are the evidence lines without their time, by pod name
+		wantEvicted  []string
+		wantAttached []string
+	}{
+		{
+			name:   "unreachable",
+			taints: []v1.Taint{unreachable},
+			wantEvicted: []string{
+				"default/cache-0 (-) in ",
+				"default/web-0 (-), evicted ",
+			},
+			wantAttached: []string{"default/db-0: pvc data (-)"},
+		},
+		{
+			name:         "not tainted yet",
+			wantAttached: []string{"default/db-0: pvc data (-)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Spec: v1.NodeSpec{Taints: tt.taints}}
+			clientSet := fake.NewSimpleClientset(node, rwo, rwx, evictedDefault, toleratesForever, toleratesHour, completed, mirror, database, shared)
+			d := NewDiagnosis("node")
+			(&NodeDiagnoser{ClientSet: clientSet}).checkAffectedPods(node, d)
+
+			evidence := map[string][]string{}
+			for _, finding := range d.Findings {
+				evidence[finding.Reason] = finding.Evidence
+			}
+			evicted := append([]string{}, evidence["PodsEvicted"]...)
+			sort.Strings(evicted)
+			if len(evicted) != len(tt.wantEvicted) {
+				t.Fatalf("checkAffectedPods() evicted = %v, want %v", evicted, tt.wantEvicted)
+			}
+			for i := range evicted {
+				if !strings.HasPrefix(evicted[i], tt.wantEvicted[i]) {
+					t.Errorf("checkAffectedPods() evicted = %v, want %v", evicted, tt.wantEvicted)
+				}
+			}
+			if got := evidence["RWOVolumesAttached"]; strings.Join(got, "\n") != strings.Join(tt.wantAttached, "\n") {
+				t.Errorf("checkAffectedPods() attached = %v, want %v", got, tt.wantAttached)
+			}
+		})
+	}
+}
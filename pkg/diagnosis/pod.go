@@ -0,0 +1,277 @@
+package diagnosis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PodDiagnoser explains why a scheduled pod is not Running and Ready.
+type PodDiagnoser struct {
+	ClientSet *kubernetes.Clientset
+
+	Namespace string
+	PodName   string
+}
+
+func (p *PodDiagnoser) WhyNotRunning() error {
+	pod, err := p.ClientSet.CoreV1().Pods(p.Namespace).Get(context.TODO(), p.PodName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get pod %s/%s: %w", p.Namespace, p.PodName, err)
+	}
+
+	DiagnosePod(p.ClientSet, pod).Print()
+	return nil
+}
+
+func DiagnosePod(clientSet kubernetes.Interface, pod *v1.Pod) *Diagnosis {
+	d := NewDiagnosis(fmt.Sprintf("pod %s/%s (%s, node %s)", pod.Namespace, pod.Name, pod.Status.Phase, pod.Spec.NodeName))
+	events := ListEvents(clientSet, pod.Namespace, "Pod", pod.Name)
+
+	if pod.DeletionTimestamp != nil {
+		d.Add(PriorityBlocker, "Terminating", fmt.Sprintf("pod is being deleted since %s ago", Age(pod.DeletionTimestamp.Time)),
+			"see why-terminating pod/"+pod.Name)
+	}
+	if pod.Spec.NodeName == "" {
+		d.Add(PriorityBlocker, "Unscheduled", "pod is not bound to any node, run schedule-detect for the scheduling diagnosis",
+			EventsWithReason(events, "FailedScheduling")...)
+		return d
+	}
+
+	checkReferences(clientSet, pod, d)
+	checkInitContainers(pod, d)
+	checkContainers(pod, events, d)
+	checkVolumeEvents(events, d)
+
+	if len(d.Findings) == 0 {
+		if pod.Status.Phase == v1.PodRunning && podReady(pod) {
+			d.Add(PriorityHealthy, "Running", "all containers are running and ready")
+		} else if pod.Status.Phase == v1.PodSucceeded {
+			d.Add(PriorityHealthy, "Succeeded", "all containers terminated successfully")
+		} else {
+			warnings := warningEvents(events)
+			d.Add(PrioritySymptom, string(pod.Status.Phase), fmt.Sprintf("pod is %s without a known cause: %s", pod.Status.Phase, pod.Status.Message), warnings...)
+		}
+	}
+	return d
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func warningEvents(events []v1.Event) []string {
+	var result []string
+	for i := range events {
+		if events[i].Type == v1.EventTypeWarning {
+			result = append(result, FormatEvent(&events[i]))
+		}
+	}
+	return result
+}
+
+// checkReferences looks up every ConfigMap, Secret, ServiceAccount and PVC the spec refers to.
+func checkReferences(clientSet kubernetes.Interface, pod *v1.Pod, d *Diagnosis) {
+	configMaps, secrets := map[string]bool{}, map[string]bool{}
+	addRef := func(refs map[string]bool, name string, optional *bool) {
+		if name == "" {
+			return
+		}
+		isOptional := optional != nil && *optional
+		// a name referenced as required anywhere is required
+		refs[name] = refs[name] || !isOptional
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.ConfigMap != nil {
+			addRef(configMaps, volume.ConfigMap.Name, volume.ConfigMap.Optional)
+		}
+		if volume.Secret != nil {
+			addRef(secrets, volume.Secret.SecretName, volume.Secret.Optional)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					addRef(configMaps, source.ConfigMap.Name, source.ConfigMap.Optional)
+				}
+				if source.Secret != nil {
+					addRef(secrets, source.Secret.Name, source.Secret.Optional)
+				}
+			}
+		}
+	}
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				addRef(configMaps, ref.Name, ref.Optional)
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				addRef(secrets, ref.Name, ref.Optional)
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if ref := envFrom.ConfigMapRef; ref != nil {
+				addRef(configMaps, ref.Name, ref.Optional)
+			}
+			if ref := envFrom.SecretRef; ref != nil {
+				addRef(secrets, ref.Name, ref.Optional)
+			}
+		}
+	}
+
+	for name, required := range configMaps {
+		_, err := clientSet.CoreV1().ConfigMaps(pod.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) && required {
+			d.Add(PriorityConfig, "MissingConfigMap", fmt.Sprintf("configmap %s/%s referenced by the pod does not exist", pod.Namespace, name))
+		}
+	}
+	for name, required := range secrets {
+		_, err := clientSet.CoreV1().Secrets(pod.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) && required {
+			d.Add(PriorityConfig, "MissingSecret", fmt.Sprintf("secret %s/%s referenced by the pod does not exist", pod.Namespace, name))
+		}
+	}
+	for _, ref := range pod.Spec.ImagePullSecrets {
+		_, err := clientSet.CoreV1().Secrets(pod.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			d.Add(PriorityRuntime+5, "MissingImagePullSecret", fmt.Sprintf("imagePullSecret %s/%s does not exist, private images cannot be pulled", pod.Namespace, ref.Name))
+		}
+	}
+	if sa := pod.Spec.ServiceAccountName; sa != "" {
+		_, err := clientSet.CoreV1().ServiceAccounts(pod.Namespace).Get(context.TODO(), sa, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			d.Add(PriorityConfig, "MissingServiceAccount", fmt.Sprintf("serviceaccount %s/%s does not exist", pod.Namespace, sa))
+		}
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		name := volume.PersistentVolumeClaim.ClaimName
+		pvc, err := clientSet.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			d.Add(PriorityConfig, "MissingPVC", fmt.Sprintf("pvc %s/%s does not exist", pod.Namespace, name))
+		} else if err == nil && pvc.Status.Phase != v1.ClaimBound {
+			d.Add(PriorityConfig, "UnboundPVC", fmt.Sprintf("pvc %s/%s is %s, run why-pvc for details", pod.Namespace, name, pvc.Status.Phase))
+		}
+	}
+}
+
+func checkInitContainers(pod *v1.Pod, d *Diagnosis) {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Ready {
+			continue
+		}
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "PodInitializing" {
+			evidence := terminationEvidence(status.LastTerminationState.Terminated)
+			d.Add(PriorityRuntime, "InitContainer"+waiting.Reason,
+				fmt.Sprintf("init container %s is waiting: %s %s", status.Name, waiting.Reason, waiting.Message), evidence...)
+			return
+		}
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			d.Add(PriorityRuntime, "InitContainerFailed",
+				fmt.Sprintf("init container %s failed", status.Name), terminationEvidence(terminated)...)
+			return
+		}
+		if status.State.Running != nil {
+			d.Add(PrioritySymptom, "InitContainerRunning", fmt.Sprintf("init container %s is still running", status.Name))
+			return
+		}
+	}
+}
+
+func checkContainers(pod *v1.Pod, events []v1.Event, d *Diagnosis) {
+	for _, status := range pod.Status.ContainerStatuses {
+		lastTerminated := status.LastTerminationState.Terminated
+		if waiting := status.State.Waiting; waiting != nil {
+			message := fmt.Sprintf("container %s is waiting: %s", status.Name, waiting.Message)
+			switch waiting.Reason {
+			case "ImagePullBackOff", "ErrImagePull", "InvalidImageName", "ErrImageNeverPull":
+				evidence := append([]string{fmt.Sprintf("image %s", status.Image)}, EventsWithReason(events, "Failed", "BackOff")...)
+				d.Add(PriorityRuntime, waiting.Reason, message, evidence...)
+			case "CreateContainerConfigError", "CreateContainerError", "RunContainerError":
+				d.Add(PriorityRuntime, waiting.Reason, message, EventsWithReason(events, "Failed")...)
+			case "CrashLoopBackOff":
+				if lastTerminated != nil && lastTerminated.Reason == "OOMKilled" {
+					d.Add(PriorityRuntime, "OOMKilled", fmt.Sprintf("container %s is crash looping after being OOM killed, memory limit %s",
+						status.Name, containerMemoryLimit(pod, status.Name)), terminationEvidence(lastTerminated)...)
+				} else {
+					d.Add(PriorityRuntime+5, waiting.Reason, fmt.Sprintf("container %s keeps exiting, restarted %d times", status.Name, status.RestartCount),
+						terminationEvidence(lastTerminated)...)
+				}
+			case "ContainerCreating", "PodInitializing":
+				d.Add(PrioritySymptom, waiting.Reason, fmt.Sprintf("container %s is being created", status.Name),
+					EventsWithReason(events, "FailedCreatePodSandBox", "FailedMount", "FailedAttachVolume")...)
+			default:
+				d.Add(PrioritySymptom, waiting.Reason, message, terminationEvidence(lastTerminated)...)
+			}
+			continue
+		}
+
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			d.Add(PriorityRuntime, "ContainerFailed", fmt.Sprintf("container %s terminated", status.Name), terminationEvidence(terminated)...)
+			continue
+		}
+
+		if status.State.Running != nil && !status.Ready {
+			probes := EventsWithReason(events, "Unhealthy")
+			if len(probes) > 0 {
+				d.Add(PrioritySymptom-5, "ReadinessProbeFailed", fmt.Sprintf("container %s is running but not ready", status.Name), probes...)
+			} else {
+				d.Add(PrioritySymptom, "NotReady", fmt.Sprintf("container %s is running but not ready yet", status.Name))
+			}
+		}
+		if lastTerminated != nil && lastTerminated.Reason == "OOMKilled" {
+			d.Add(PriorityWarning, "OOMKilled", fmt.Sprintf("container %s was OOM killed before, memory limit %s",
+				status.Name, containerMemoryLimit(pod, status.Name)), terminationEvidence(lastTerminated)...)
+		}
+	}
+}
+
+// checkVolumeEvents turns mount/attach/sandbox failures into findings, they are the cause
+// of pods stuck in ContainerCreating.
+func checkVolumeEvents(events []v1.Event, d *Diagnosis) {
+	if mounts := EventsWithReason(events, "FailedMount", "FailedAttachVolume"); len(mounts) > 0 {
+		d.Add(PriorityRuntime, "VolumeMountFailed", "volumes cannot be attached or mounted", mounts...)
+	}
+	if sandbox := EventsWithReason(events, "FailedCreatePodSandBox"); len(sandbox) > 0 {
+		d.Add(PriorityRuntime, "SandboxCreationFailed", "the runtime cannot create the pod sandbox, usually a CNI problem on the node", sandbox...)
+	}
+}
+
+func terminationEvidence(terminated *v1.ContainerStateTerminated) []string {
+	if terminated == nil {
+		return nil
+	}
+	evidence := []string{fmt.Sprintf("last terminated: reason %s, exit code %d, finished %s ago",
+		terminated.Reason, terminated.ExitCode, Age(terminated.FinishedAt.Time))}
+	if message := strings.TrimSpace(terminated.Message); message != "" {
+		evidence = append(evidence, message)
+	}
+	return evidence
+}
+
+func containerMemoryLimit(pod *v1.Pod, name string) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			if limit, ok := container.Resources.Limits[v1.ResourceMemory]; ok {
+				return limit.String()
+			}
+		}
+	}
+	return "<none>"
+}
@@ -0,0 +1,125 @@
+package diagnosis
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiagnosePod(t *testing.T) {
+	now := metav1.NewTime(time.Now())
+	withStatus := func(pod *v1.Pod, phase v1.PodPhase, ready bool, states ...v1.ContainerStatus) *v1.Pod {
+		pod.Status.Phase = phase
+		pod.Status.ContainerStatuses = states
+		readyStatus := v1.ConditionFalse
+		if ready {
+			readyStatus = v1.ConditionTrue
+		}
+		pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: readyStatus}}
+		return pod
+	}
+	waiting := func(reason string, lastReason string) v1.ContainerStatus {
+		status := v1.ContainerStatus{Name: "main", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason}}}
+		if lastReason != "" {
+			status.LastTerminationState.Terminated = &v1.ContainerStateTerminated{Reason: lastReason, ExitCode: 137}
+		}
+		return status
+	}
+	running := func(ready bool, lastReason string) v1.ContainerStatus {
+		status := v1.ContainerStatus{Name: "main", Ready: ready, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}}
+		if lastReason != "" {
+			status.LastTerminationState.Terminated = &v1.ContainerStateTerminated{Reason: lastReason, ExitCode: 137}
+		}
+		return status
+	}
+	event := func(reason string) *v1.Event {
+		return &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "web." + reason},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web"},
+			Type:           v1.EventTypeWarning, Reason: reason, LastTimestamp: now,
+		}
+	}
+
+	missingConfigMap := withStatus(newTestPod("default", "web", "node1"), v1.PodRunning, false, waiting("CrashLoopBackOff", "Error"))
+	missingConfigMap.Spec.Containers[0].EnvFrom = []v1.EnvFromSource{{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "web-config"}}}}
+
+	oomKilled := withStatus(newTestPod("default", "web", "node1"), v1.PodRunning, false, waiting("CrashLoopBackOff", "OOMKilled"))
+	oomKilled.Spec.Containers[0].Resources.Limits = v1.ResourceList{v1.ResourceMemory: resource.MustParse("256Mi")}
+	oomKilled.Spec.ImagePullSecrets = []v1.LocalObjectReference{{Name: "registry"}}
+
+	terminating := withStatus(newTestPod("default", "web", "node1"), v1.PodRunning, false, waiting("ImagePullBackOff", ""))
+	terminating.DeletionTimestamp = &now
+
+	type args struct {
+		pod     *v1.Pod
+		objects []runtime.Object
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "unscheduled",
+			args: args{pod: newTestPod("default", "web", "")},
+			want: "Unscheduled",
+		},
+		{
+			name: "terminating before runtime errors",
+			args: args{pod: terminating},
+			want: "Terminating",
+		},
+		{
+			name: "missing config before crash loop",
+			args: args{pod: missingConfigMap},
+			want: "MissingConfigMap",
+		},
+		{
+			name: "oom kill before missing pull secret",
+			args: args{pod: oomKilled},
+			want: "OOMKilled",
+		},
+		{
+			name: "mount failure before not ready",
+			args: args{pod: withStatus(newTestPod("default", "web", "node1"), v1.PodRunning, false, running(false, "")), objects: []runtime.Object{event("FailedMount")}},
+			want: "VolumeMountFailed",
+		},
+		{
+			name: "failing probe before not ready",
+			args: args{pod: withStatus(newTestPod("default", "web", "node1"), v1.PodRunning, false, running(false, "")), objects: []runtime.Object{event("Unhealthy")}},
+			want: "ReadinessProbeFailed",
+		},
+		{
+			name: "past oom kill is a warning",
+			args: args{pod: withStatus(newTestPod("default", "web", "node1"), v1.PodRunning, true, running(true, "OOMKilled"))},
+			want: "OOMKilled",
+		},
+		{
+			name: "healthy",
+			args: args{pod: withStatus(newTestPod("default", "web", "node1"), v1.PodRunning, true, running(true, ""))},
+			want: "Running",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DiagnosePod(fake.NewSimpleClientset(tt.args.objects...), tt.args.pod)
+			verdict := d.Verdict()
+			if verdict == nil {
+				t.Fatalf("DiagnosePod() verdict = nil, want %s", tt.want)
+			}
+			if verdict.Reason != tt.want {
+				t.Errorf("DiagnosePod() verdict = %s, want %s", verdict.Reason, tt.want)
+			}
+			for i := 1; i < len(d.Findings); i++ {
+				if d.Findings[i-1].Priority > d.Findings[i].Priority {
+					t.Errorf("Verdict() left findings out of priority order: %s before %s", d.Findings[i-1].Reason, d.Findings[i].Reason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package diagnosis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	componenthelpers "k8s.io/component-helpers/scheduling/corev1"
+)
+
+const (
+	annDefaultStorageClass = "storageclass.kubernetes.io/is-default-class"
+	annStorageProvisioner  = "volume.kubernetes.io/storage-provisioner"
+	annSelectedNode        = "volume.kubernetes.io/selected-node"
+	noProvisioner          = "kubernetes.io/no-provisioner"
+
+	// leases of a provisioner not renewed for this long are treated as abandoned
+	leaseExpiry = 2 * time.Minute
+)
+
+// PVCDiagnoser explains why a PersistentVolumeClaim is not Bound.
+type PVCDiagnoser struct {
+	ClientSet kubernetes.Interface
+
+	Namespace string
+	PVCName   string
+}
+
+func (p *PVCDiagnoser) WhyPending() error {
+	pvc, err := p.ClientSet.CoreV1().PersistentVolumeClaims(p.Namespace).Get(context.TODO(), p.PVCName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get pvc %s/%s: %w", p.Namespace, p.PVCName, err)
+	}
+
+	p.diagnose(pvc).Print()
+	return nil
+}
+
+func (p *PVCDiagnoser) diagnose(pvc *v1.PersistentVolumeClaim) *Diagnosis {
+	request := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	d := NewDiagnosis(fmt.Sprintf("pvc %s/%s (%s, %s)", pvc.Namespace, pvc.Name, pvc.Status.Phase, request.String()))
+	if pvc.Status.Phase == v1.ClaimBound {
+		d.Add(PriorityHealthy, "Bound", fmt.Sprintf("pvc is bound to pv %s", pvc.Spec.VolumeName))
+		return d
+	}
+	if pvc.Status.Phase == v1.ClaimLost {
+		d.Add(PriorityBlocker, "Lost", fmt.Sprintf("pv %s bound to the pvc no longer exists", pvc.Spec.VolumeName))
+		return d
+	}
+
+	events := ListEvents(p.ClientSet, pvc.Namespace, "PersistentVolumeClaim", pvc.Name)
+	if failed := EventsWithReason(events, "ProvisioningFailed"); len(failed) > 0 {
+		d.Add(PriorityRuntime, "ProvisioningFailed", "the provisioner failed to create a volume", failed...)
+	}
+
+	if pvc.Spec.VolumeName != "" {
+		p.checkPreBound(pvc, d)
+		return d
+	}
+
+	class, className := p.resolveStorageClass(pvc, d)
+	if class == nil && className != "" {
+		return d
+	}
+
+	waitForFirstConsumer := class != nil && class.VolumeBindingMode != nil && *class.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+	matched := p.checkStaticPVs(pvc, className, waitForFirstConsumer, d)
+	if class == nil || class.Provisioner == noProvisioner {
+		if !matched {
+			d.Add(PriorityConfig, "NoMatchingPV", fmt.Sprintf("storageClass %q has no dynamic provisioner and no Available pv matches the claim", className))
+		}
+	} else {
+		p.checkProvisioner(pvc, class, d)
+	}
+	if waitForFirstConsumer {
+		p.checkWaitForFirstConsumer(pvc, d)
+	}
+
+	if progress := EventsWithReason(events, "ExternalProvisioning", "Provisioning", "WaitForPodScheduled"); len(progress) > 0 {
+		d.Add(PriorityWarning, "ProvisioningEvents", "recent provisioning events", progress...)
+	}
+	return d
+}
+
+func (p *PVCDiagnoser) checkPreBound(pvc *v1.PersistentVolumeClaim, d *Diagnosis) {
+	pv, err := p.ClientSet.CoreV1().PersistentVolumes().Get(context.TODO(), pvc.Spec.VolumeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		d.Add(PriorityConfig, "PreBoundPVMissing", fmt.Sprintf("pvc requests pv %s by volumeName, which does not exist", pvc.Spec.VolumeName))
+		return
+	}
+	if err != nil {
+		fmt.Printf("error fetching pv %s: %v\n", pvc.Spec.VolumeName, err)
+		return
+	}
+	if ref := pv.Spec.ClaimRef; ref != nil && (ref.Namespace != pvc.Namespace || ref.Name != pvc.Name) {
+		d.Add(PriorityConfig, "PVClaimedByOther", fmt.Sprintf("pv %s is reserved for pvc %s/%s", pv.Name, ref.Namespace, ref.Name))
+		return
+	}
+	if mismatches := PVMismatches(pvc, pv, pv.Spec.StorageClassName, nil); len(mismatches) > 0 {
+		d.Add(PriorityConfig, "PreBoundPVMismatch", fmt.Sprintf("pv %s requested by volumeName does not satisfy the claim", pv.Name), mismatches...)
+		return
+	}
+	d.Add(PrioritySymptom, "BindingInProgress", fmt.Sprintf("pv %s is %s, waiting for the pv controller to bind it", pv.Name, pv.Status.Phase))
+}
+
+// resolveStorageClass returns the class of the claim, or the default class when none is set.
+func (p *PVCDiagnoser) resolveStorageClass(pvc *v1.PersistentVolumeClaim, d *Diagnosis) (*storagev1.StorageClass, string) {
+	if pvc.Spec.StorageClassName != nil {
+		name := *pvc.Spec.StorageClassName
+		if name == "" {
+			d.Add(PriorityWarning, "StaticOnly", `storageClassName is "", only pre-provisioned pvs without a class can be bound`)
+			return nil, ""
+		}
+		class, err := p.ClientSet.StorageV1().StorageClasses().Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			d.Add(PriorityConfig, "StorageClassNotFound", fmt.Sprintf("storageClass %s does not exist", name))
+			return nil, name
+		}
+		if err != nil {
+			fmt.Printf("error fetching storageclass %s: %v\n", name, err)
+			return nil, name
+		}
+		return class, name
+	}
+
+	classes, err := p.ClientSet.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("error listing storageclasses: %v\n", err)
+		return nil, ""
+	}
+	var defaults []*storagev1.StorageClass
+	for i := range classes.Items {
+		if classes.Items[i].Annotations[annDefaultStorageClass] == "true" {
+			defaults = append(defaults, &classes.Items[i])
+		}
+	}
+	if len(defaults) == 0 {
+		d.Add(PriorityConfig, "NoDefaultStorageClass", "pvc has no storageClassName and there is no default storageClass")
+		return nil, ""
+	}
+	// the newest default class wins, as in the DefaultStorageClass admission plugin
+	sort.Slice(defaults, func(i, j int) bool {
+		return defaults[i].CreationTimestamp.After(defaults[j].CreationTimestamp.Time)
+	})
+	evidence := []string{fmt.Sprintf("provisioner %s", defaults[0].Provisioner)}
+	if len(defaults) > 1 {
+		evidence = append(evidence, fmt.Sprintf("%d storageClasses are marked as default", len(defaults)))
+	}
+	d.Add(PriorityWarning, "DefaultStorageClass", fmt.Sprintf("pvc uses the default storageClass %s", defaults[0].Name), evidence...)
+	return defaults[0], defaults[0].Name
+}
+
+// checkProvisioner looks for a running controller of the class's provisioner: a renewed leader
+// election lease, or running pods referring to the provisioner name.
+func (p *PVCDiagnoser) checkProvisioner(pvc *v1.PersistentVolumeClaim, class *storagev1.StorageClass, d *Diagnosis) {
+	provisioner := class.Provisioner
+	if strings.HasPrefix(provisioner, "kubernetes.io/") {
+		// in-tree provisioners run inside kube-controller-manager
+		return
+	}
+
+	leases, err := p.ClientSet.CoordinationV1().Leases("").List(context.TODO(), metav1.ListOptions{})
+	if err == nil {
+		leaseName := strings.NewReplacer("/", "-", ".", "-").Replace(provisioner)
+		for _, lease := range leases.Items {
+			if !strings.Contains(strings.ReplaceAll(lease.Name, ".", "-"), leaseName) || lease.Spec.RenewTime == nil {
+				continue
+			}
+			holder := ""
+			if lease.Spec.HolderIdentity != nil {
+				holder = *lease.Spec.HolderIdentity
+			}
+			if time.Since(lease.Spec.RenewTime.Time) < leaseExpiry {
+				return
+			}
+			d.Add(PriorityRuntime, "ProvisionerNotRunning",
+				fmt.Sprintf("leader lease of provisioner %s was last renewed %s ago", provisioner, Age(lease.Spec.RenewTime.Time)),
+				fmt.Sprintf("lease %s/%s held by %s", lease.Namespace, lease.Name, holder))
+			return
+		}
+	}
+
+	pods, err := p.ClientSet.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("error listing pods: %v\n", err)
+		return
+	}
+	var running, notRunning []string
+	for _, pod := range pods.Items {
+		if !podRefersTo(&pod, provisioner) {
+			continue
+		}
+		name := fmt.Sprintf("%s/%s on %s: %s", pod.Namespace, pod.Name, pod.Spec.NodeName, pod.Status.Phase)
+		if pod.Status.Phase == v1.PodRunning && podReady(&pod) {
+			running = append(running, name)
+		} else {
+			notRunning = append(notRunning, name)
+		}
+	}
+	if len(running) > 0 {
+		return
+	}
+	if len(notRunning) > 0 {
+		d.Add(PriorityRuntime, "ProvisionerNotRunning", fmt.Sprintf("controller pods of provisioner %s are not ready", provisioner), notRunning...)
+		return
+	}
+	evidence := []string{}
+	if pvc.Annotations[annStorageProvisioner] != "" {
+		evidence = append(evidence, fmt.Sprintf("pvc is waiting for %s", pvc.Annotations[annStorageProvisioner]))
+	}
+	d.Add(PriorityRuntime+5, "ProvisionerNotFound", fmt.Sprintf("no running controller found for provisioner %s", provisioner), evidence...)
+}
+
+func podRefersTo(pod *v1.Pod, provisioner string) bool {
+	for _, container := range pod.Spec.Containers {
+		for _, value := range append(append([]string{}, container.Args...), container.Command...) {
+			if strings.Contains(value, provisioner) {
+				return true
+			}
+		}
+		for _, env := range container.Env {
+			if env.Value == provisioner {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkWaitForFirstConsumer explains a claim waiting for a pod to be scheduled before provisioning.
+func (p *PVCDiagnoser) checkWaitForFirstConsumer(pvc *v1.PersistentVolumeClaim, d *Diagnosis) {
+	if node := pvc.Annotations[annSelectedNode]; node != "" {
+		d.Add(PrioritySymptom, "WaitForFirstConsumer", fmt.Sprintf("the scheduler selected node %s, the volume is being provisioned there", node))
+		return
+	}
+	consumers := p.consumers(pvc)
+	if len(consumers) == 0 {
+		d.Add(PrioritySymptom+5, "WaitForFirstConsumer",
+			"volumeBindingMode is WaitForFirstConsumer and no pod uses the claim yet, it will be bound once a pod using it is scheduled")
+		return
+	}
+	var evidence []string
+	for _, pod := range consumers {
+		evidence = append(evidence, fmt.Sprintf("pod %s is %s, run schedule-detect %s -n %s", pod.Name, pod.Status.Phase, pod.Name, pod.Namespace))
+	}
+	d.Add(PrioritySymptom, "WaitForFirstConsumer", "volumeBindingMode is WaitForFirstConsumer, the claim is waiting for its pods to be scheduled", evidence...)
+}
+
+func (p *PVCDiagnoser) consumers(pvc *v1.PersistentVolumeClaim) []v1.Pod {
+	pods, err := p.ClientSet.CoreV1().Pods(pvc.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("error listing pods in %s: %v\n", pvc.Namespace, err)
+		return nil
+	}
+	var result []v1.Pod
+	for _, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvc.Name {
+				result = append(result, pod)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// checkStaticPVs looks for pre-provisioned pvs the claim could bind to, and reports why the
+// closest candidates don't match. It returns whether one matched. With waitForFirstConsumer
+// the pv controller only binds once the scheduler selected a node for the claim.
+func (p *PVCDiagnoser) checkStaticPVs(pvc *v1.PersistentVolumeClaim, className string, waitForFirstConsumer bool, d *Diagnosis) bool {
+	pvs, err := p.ClientSet.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("error listing pvs: %v\n", err)
+		return false
+	}
+
+	var node *v1.Node
+	if name := pvc.Annotations[annSelectedNode]; name != "" {
+		node, _ = p.ClientSet.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+	}
+
+	var candidates []string
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if pv.Status.Phase != v1.VolumeAvailable || pv.Spec.StorageClassName != className {
+			continue
+		}
+		if ref := pv.Spec.ClaimRef; ref != nil && (ref.Namespace != pvc.Namespace || ref.Name != pvc.Name) {
+			continue
+		}
+		mismatches := PVMismatches(pvc, pv, className, node)
+		if len(mismatches) == 0 && waitForFirstConsumer && pvc.Annotations[annSelectedNode] == "" {
+			d.Add(PriorityWarning, "MatchingPV", fmt.Sprintf("pv %s matches the claim, it is bound once the first pod using the claim is scheduled", pv.Name))
+			return true
+		}
+		if len(mismatches) == 0 {
+			d.Add(PriorityWarning, "MatchingPV", fmt.Sprintf("pv %s matches the claim and should be bound by the pv controller", pv.Name))
+			return true
+		}
+		candidates = append(candidates, fmt.Sprintf("%s: %s", pv.Name, strings.Join(mismatches, "; ")))
+	}
+	if len(candidates) > 0 {
+		d.Add(PrioritySymptom, "PVMismatch", fmt.Sprintf("%d Available pvs of storageClass %q don't match the claim", len(candidates), className), candidates...)
+	}
+	return false
+}
+
+// PVMismatches returns why a pv cannot satisfy a claim of the given storage class. node is the
+// node selected for the claim, if any.
+func PVMismatches(pvc *v1.PersistentVolumeClaim, pv *v1.PersistentVolume, className string, node *v1.Node) []string {
+	var result []string
+	if pv.Spec.StorageClassName != className {
+		result = append(result, fmt.Sprintf("storageClassName %q, want %q", pv.Spec.StorageClassName, className))
+	}
+
+	request := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	capacity := pv.Spec.Capacity[v1.ResourceStorage]
+	if capacity.Cmp(request) < 0 {
+		result = append(result, fmt.Sprintf("capacity %s, want %s", capacity.String(), request.String()))
+	}
+
+	have := map[v1.PersistentVolumeAccessMode]bool{}
+	for _, mode := range pv.Spec.AccessModes {
+		have[mode] = true
+	}
+	for _, mode := range pvc.Spec.AccessModes {
+		if !have[mode] {
+			result = append(result, fmt.Sprintf("access mode %s not supported", mode))
+		}
+	}
+
+	if volumeMode(pv.Spec.VolumeMode) != volumeMode(pvc.Spec.VolumeMode) {
+		result = append(result, fmt.Sprintf("volumeMode %s, want %s", volumeMode(pv.Spec.VolumeMode), volumeMode(pvc.Spec.VolumeMode)))
+	}
+
+	if pvc.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(pvc.Spec.Selector)
+		if err != nil {
+			result = append(result, fmt.Sprintf("invalid selector: %v", err))
+		} else if !selector.Matches(labels.Set(pv.Labels)) {
+			result = append(result, fmt.Sprintf("labels don't match selector %s", selector.String()))
+		}
+	}
+
+	if node != nil && pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		matches, err := componenthelpers.MatchNodeSelectorTerms(node, pv.Spec.NodeAffinity.Required)
+		if err != nil || !matches {
+			result = append(result, fmt.Sprintf("node affinity doesn't match selected node %s", node.Name))
+		}
+	}
+	return result
+}
+
+func volumeMode(mode *v1.PersistentVolumeMode) v1.PersistentVolumeMode {
+	if mode == nil {
+		return v1.PersistentVolumeFilesystem
+	}
+	return *mode
+}
@@ -0,0 +1,146 @@
+package diagnosis
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPVMismatches(t *testing.T) {
+	block := v1.PersistentVolumeBlock
+	pvc := &v1.PersistentVolumeClaim{
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			Resources: v1.VolumeResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"disk": "ssd"}},
+		},
+	}
+	newPV := func(size string, modes ...v1.PersistentVolumeAccessMode) *v1.PersistentVolume {
+		return &v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"disk": "ssd"}},
+			Spec: v1.PersistentVolumeSpec{
+				StorageClassName: "local",
+				Capacity:         v1.ResourceList{v1.ResourceStorage: resource.MustParse(size)},
+				AccessModes:      modes,
+			},
+		}
+	}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"kubernetes.io/hostname": "node1"}}}
+
+	tests := []struct {
+		name   string
+		pv     func() *v1.PersistentVolume
+		node   *v1.Node
+		wanted int
+	}{
+		{
+			name:   "match",
+			pv:     func() *v1.PersistentVolume { return newPV("20Gi", v1.ReadWriteOnce, v1.ReadOnlyMany) },
+			wanted: 0,
+		},
+		{
+			name:   "too small and missing access mode",
+			pv:     func() *v1.PersistentVolume { return newPV("5Gi", v1.ReadOnlyMany) },
+			wanted: 2,
+		},
+		{
+			name: "volume mode and labels",
+			pv: func() *v1.PersistentVolume {
+				pv := newPV("10Gi", v1.ReadWriteOnce)
+				pv.Spec.VolumeMode = &block
+				pv.Labels = nil
+				return pv
+			},
+			wanted: 2,
+		},
+		{
+			name: "node affinity",
+			pv: func() *v1.PersistentVolume {
+				pv := newPV("10Gi", v1.ReadWriteOnce)
+				pv.Spec.NodeAffinity = &v1.VolumeNodeAffinity{Required: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{{MatchExpressions: []v1.NodeSelectorRequirement{{
+						Key: "kubernetes.io/hostname", Operator: v1.NodeSelectorOpIn, Values: []string{"node2"},
+					}}}},
+				}}
+				return pv
+			},
+			node:   node,
+			wanted: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PVMismatches(pvc, tt.pv(), "local", tt.node)
+			if len(got) != tt.wanted {
+				t.Errorf("PVMismatches() = %v, want %d mismatches", got, tt.wanted)
+			}
+		})
+	}
+}
+
+func TestPVCDiagnoser_checkStaticPVs(t *testing.T) {
+	newPVC := func(selectedNode string) *v1.PersistentVolumeClaim {
+		pvc := &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				Resources:   v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")}},
+			},
+		}
+		if selectedNode != "" {
+			pvc.Annotations = map[string]string{annSelectedNode: selectedNode}
+		}
+		return pvc
+	}
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+		Spec: v1.PersistentVolumeSpec{
+			StorageClassName: "local",
+			Capacity:         v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")},
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+		},
+		Status: v1.PersistentVolumeStatus{Phase: v1.VolumeAvailable},
+	}
+
+	tests := []struct {
+		name                 string
+		pvc                  *v1.PersistentVolumeClaim
+		waitForFirstConsumer bool
+		want                 string
+	}{
+		{
+			name: "immediate binding",
+			pvc:  newPVC(""),
+			want: "pv local-pv matches the claim and should be bound by the pv controller",
+		},
+		{
+			name:                 "waiting for the first consumer",
+			pvc:                  newPVC(""),
+			waitForFirstConsumer: true,
+			want:                 "pv local-pv matches the claim, it is bound once the first pod using the claim is scheduled",
+		},
+		{
+			name:                 "node selected",
+			pvc:                  newPVC("node1"),
+			waitForFirstConsumer: true,
+			want:                 "pv local-pv matches the claim and should be bound by the pv controller",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PVCDiagnoser{ClientSet: fake.NewSimpleClientset(pv, newTestNode("node1", v1.ConditionTrue))}
+			d := NewDiagnosis("pvc")
+			if !p.checkStaticPVs(tt.pvc, "local", tt.waitForFirstConsumer, d) {
+				t.Fatalf("checkStaticPVs() found no matching pv")
+			}
+			if got := d.Verdict().Message; got != tt.want {
+				t.Errorf("checkStaticPVs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
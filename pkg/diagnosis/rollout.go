@@ -0,0 +1,337 @@
+package diagnosis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/workloads"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+const annDeploymentRevision = "deployment.kubernetes.io/revision"
+
+// RolloutDiagnoser explains why the rollout of a Deployment or a StatefulSet does not complete.
+type RolloutDiagnoser struct {
+	ClientSet kubernetes.Interface
+
+	Namespace string
+	Ref       string
+}
+
+func (r *RolloutDiagnoser) WhyRollout() error {
+	kind, name, err := workloads.ParseRef(r.Ref)
+	if err != nil {
+		return err
+	}
+
+	var d *Diagnosis
+	var pending *v1.Pod
+	switch kind {
+	case "deployment", "deployments", "deploy":
+		d, pending, err = r.diagnoseDeployment(name)
+	case "statefulset", "statefulsets", "sts":
+		d, pending, err = r.diagnoseStatefulSet(name)
+	default:
+		return fmt.Errorf("unsupported kind %q, expected deployment or statefulset", kind)
+	}
+	if err != nil {
+		return err
+	}
+	d.Print()
+
+	if pending == nil {
+		return nil
+	}
+	fmt.Printf("\nscheduling diagnosis of pending pod %s/%s:\n", pending.Namespace, pending.Name)
+	analyzer, err := scheduler.NewAnalyzer(r.ClientSet, pending.Namespace, pending.Name)
+	if err != nil {
+		return err
+	}
+	return analyzer.Why()
+}
+
+func (r *RolloutDiagnoser) diagnoseDeployment(name string) (*Diagnosis, *v1.Pod, error) {
+	deploy, err := r.ClientSet.AppsV1().Deployments(r.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get deployment %s/%s: %w", r.Namespace, name, err)
+	}
+	replicas := int32(1)
+	if deploy.Spec.Replicas != nil {
+		replicas = *deploy.Spec.Replicas
+	}
+	status := deploy.Status
+	d := NewDiagnosis(fmt.Sprintf("deployment %s/%s (replicas %d, updated %d, ready %d, available %d)",
+		deploy.Namespace, deploy.Name, replicas, status.UpdatedReplicas, status.ReadyReplicas, status.AvailableReplicas))
+
+	if deploy.Spec.Paused {
+		d.Add(PriorityBlocker, "Paused", "deployment is paused, run kubectl rollout resume")
+	}
+	for _, condition := range status.Conditions {
+		switch {
+		case condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded":
+			d.Add(PrioritySymptom, "ProgressDeadlineExceeded", condition.Message, fmt.Sprintf("since %s ago", Age(condition.LastTransitionTime.Time)))
+		case condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == v1.ConditionTrue:
+			d.Add(PriorityConfig, "ReplicaFailure", condition.Message, fmt.Sprintf("since %s ago", Age(condition.LastTransitionTime.Time)))
+		}
+	}
+
+	newRS, oldRSs := r.replicaSets(deploy)
+	if deploy.Spec.Strategy.Type == appsv1.RollingUpdateDeploymentStrategyType {
+		surgeArithmetic(deploy, replicas, d)
+	}
+
+	var rsEvidence []string
+	for _, rs := range oldRSs {
+		rsEvidence = append(rsEvidence, replicaSetLine("old", rs))
+	}
+	if newRS == nil {
+		d.Add(PrioritySymptom, "NoNewReplicaSet", "the ReplicaSet of the current template is not created yet", rsEvidence...)
+		return d, nil, nil
+	}
+	rsEvidence = append([]string{replicaSetLine("new", newRS)}, rsEvidence...)
+	d.Add(PriorityHealthy+1, "ReplicaSets", fmt.Sprintf("revision %s", newRS.Annotations[annDeploymentRevision]), rsEvidence...)
+
+	events := ListEvents(r.ClientSet, r.Namespace, "ReplicaSet", newRS.Name)
+	if failed := EventsWithReason(events, "FailedCreate"); len(failed) > 0 {
+		d.Add(PriorityConfig, "FailedCreate", fmt.Sprintf("replicaset %s cannot create pods, usually rejected by a ResourceQuota or LimitRange", newRS.Name), tail(failed)...)
+	}
+
+	pods := r.ownedPods(newRS.UID)
+	pending := r.checkNewPods(pods, d)
+	if status.UpdatedReplicas == replicas && status.AvailableReplicas == replicas && status.Replicas == replicas {
+		d.Add(PriorityHealthy, "Complete", "rollout is complete")
+	} else if d.Verdict().Priority >= PriorityHealthy {
+		d.Add(PrioritySymptom, "Progressing", "rollout is not complete yet, no blocker found")
+	}
+	return d, pending, nil
+}
+
+// surgeArithmetic explains how many pods the rolling update may create and take down.
+func surgeArithmetic(deploy *appsv1.Deployment, replicas int32, d *Diagnosis) {
+	rolling := deploy.Spec.Strategy.RollingUpdate
+	defaultValue := intstr.FromString("25%")
+	maxSurge, maxUnavailable := &defaultValue, &defaultValue
+	if rolling != nil && rolling.MaxSurge != nil {
+		maxSurge = rolling.MaxSurge
+	}
+	if rolling != nil && rolling.MaxUnavailable != nil {
+		maxUnavailable = rolling.MaxUnavailable
+	}
+	// rounding as the deployment controller does: surge up, unavailable down
+	surge, err := intstr.GetScaledValueFromIntOrPercent(maxSurge, int(replicas), true)
+	if err != nil {
+		return
+	}
+	unavailable, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, int(replicas), false)
+	if err != nil {
+		return
+	}
+	if surge == 0 && unavailable == 0 {
+		unavailable = 1
+	}
+	status := deploy.Status
+	maxTotal := int(replicas) + surge
+	minAvailable := int(replicas) - unavailable
+	evidence := []string{
+		fmt.Sprintf("maxSurge %s -> at most %d pods, currently %d", maxSurge.String(), maxTotal, status.Replicas),
+		fmt.Sprintf("maxUnavailable %s -> at least %d available, currently %d", maxUnavailable.String(), minAvailable, status.AvailableReplicas),
+	}
+	if int(status.Replicas) >= maxTotal && int(status.AvailableReplicas) <= minAvailable && status.UpdatedReplicas < replicas {
+		d.Add(PrioritySymptom-5, "RollingUpdateBlocked",
+			"no surge left and no more pods may become unavailable, the rollout waits for new pods to become available", evidence...)
+		return
+	}
+	d.Add(PriorityHealthy+1, "RollingUpdate", "rolling update budget", evidence...)
+}
+
+// replicaSets returns the ReplicaSet of the deployment's current revision and the older ones still having pods.
+func (r *RolloutDiagnoser) replicaSets(deploy *appsv1.Deployment) (*appsv1.ReplicaSet, []*appsv1.ReplicaSet) {
+	list, err := r.ClientSet.AppsV1().ReplicaSets(deploy.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("error listing replicasets in %s: %v\n", deploy.Namespace, err)
+		return nil, nil
+	}
+	revision := deploy.Annotations[annDeploymentRevision]
+	var newRS *appsv1.ReplicaSet
+	var oldRSs []*appsv1.ReplicaSet
+	for i := range list.Items {
+		rs := &list.Items[i]
+		if ref := metav1.GetControllerOf(rs); ref == nil || ref.UID != deploy.UID {
+			continue
+		}
+		if rs.Annotations[annDeploymentRevision] == revision {
+			newRS = rs
+		} else if rs.Status.Replicas > 0 {
+			oldRSs = append(oldRSs, rs)
+		}
+	}
+	sort.Slice(oldRSs, func(i, j int) bool {
+		a, _ := strconv.Atoi(oldRSs[i].Annotations[annDeploymentRevision])
+		b, _ := strconv.Atoi(oldRSs[j].Annotations[annDeploymentRevision])
+		return a > b
+	})
+	return newRS, oldRSs
+}
+
+func replicaSetLine(generation string, rs *appsv1.ReplicaSet) string {
+	desired := int32(0)
+	if rs.Spec.Replicas != nil {
+		desired = *rs.Spec.Replicas
+	}
+	return fmt.Sprintf("%s %s (revision %s): desired %d, current %d, ready %d, available %d", generation, rs.Name,
+		rs.Annotations[annDeploymentRevision], desired, rs.Status.Replicas, rs.Status.ReadyReplicas, rs.Status.AvailableReplicas)
+}
+
+func (r *RolloutDiagnoser) ownedPods(owner types.UID) []v1.Pod {
+	pods, err := r.ClientSet.CoreV1().Pods(r.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("error listing pods in %s: %v\n", r.Namespace, err)
+		return nil
+	}
+	var result []v1.Pod
+	for _, pod := range pods.Items {
+		if ref := metav1.GetControllerOf(&pod); ref != nil && ref.UID == owner {
+			result = append(result, pod)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreationTimestamp.Before(&result[j].CreationTimestamp)
+	})
+	return result
+}
+
+// checkNewPods diagnoses the first new-revision pod not running, and returns the first unscheduled one.
+func (r *RolloutDiagnoser) checkNewPods(pods []v1.Pod, d *Diagnosis) *v1.Pod {
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || (pod.Status.Phase == v1.PodRunning && podReady(pod)) {
+			continue
+		}
+		if pod.Spec.NodeName == "" {
+			d.Add(PriorityRuntime, "NewPodPending", fmt.Sprintf("new pod %s cannot be scheduled, see the scheduling diagnosis below", pod.Name),
+				EventsWithReason(ListEvents(r.ClientSet, pod.Namespace, "Pod", pod.Name), "FailedScheduling")...)
+			return pod
+		}
+		verdict := DiagnosePod(r.ClientSet, pod).Verdict()
+		if verdict != nil && verdict.Priority < PriorityHealthy {
+			evidence := append([]string{verdict.Message}, verdict.Evidence...)
+			d.Add(verdict.Priority, verdict.Reason, fmt.Sprintf("new pod %s is not ready, run why-not-running %s -n %s", pod.Name, pod.Name, pod.Namespace), evidence...)
+		}
+		return nil
+	}
+	return nil
+}
+
+func (r *RolloutDiagnoser) diagnoseStatefulSet(name string) (*Diagnosis, *v1.Pod, error) {
+	sts, err := r.ClientSet.AppsV1().StatefulSets(r.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get statefulset %s/%s: %w", r.Namespace, name, err)
+	}
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	status := sts.Status
+	d := NewDiagnosis(fmt.Sprintf("statefulset %s/%s (replicas %d, updated %d, ready %d, current revision %s, update revision %s)",
+		sts.Namespace, sts.Name, replicas, status.UpdatedReplicas, status.ReadyReplicas, status.CurrentRevision, status.UpdateRevision))
+
+	if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType && status.UpdatedReplicas < replicas {
+		d.Add(PriorityBlocker+5, "OnDelete", "updateStrategy is OnDelete, pods are only updated when deleted by hand")
+	}
+	if rolling := sts.Spec.UpdateStrategy.RollingUpdate; rolling != nil && rolling.Partition != nil && *rolling.Partition > 0 {
+		d.Add(PriorityWarning, "Partition", fmt.Sprintf("partition %d, pods with a lower ordinal stay on the current revision", *rolling.Partition))
+	}
+
+	events := ListEvents(r.ClientSet, r.Namespace, "StatefulSet", sts.Name)
+	if failed := EventsWithReason(events, "FailedCreate"); len(failed) > 0 {
+		d.Add(PriorityConfig, "FailedCreate", "statefulset cannot create pods or claims, usually rejected by a ResourceQuota or LimitRange", tail(failed)...)
+	}
+
+	pods := map[int]*v1.Pod{}
+	owned := r.ownedPods(sts.UID)
+	for i := range owned {
+		if ordinal, ok := podOrdinal(sts.Name, owned[i].Name); ok {
+			pods[ordinal] = &owned[i]
+		}
+	}
+
+	start := 0
+	if sts.Spec.Ordinals != nil {
+		start = int(sts.Spec.Ordinals.Start)
+	}
+	r.checkClaimTemplates(sts, start, replicas, d)
+
+	var pending *v1.Pod
+	ordered := sts.Spec.PodManagementPolicy != appsv1.ParallelPodManagement
+	for ordinal := start; ordinal < start+int(replicas); ordinal++ {
+		podName := fmt.Sprintf("%s-%d", sts.Name, ordinal)
+		pod, ok := pods[ordinal]
+		if !ok {
+			if ordered {
+				d.Add(PrioritySymptom, "PodMissing", fmt.Sprintf("pod %s does not exist", podName))
+				break
+			}
+			continue
+		}
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == v1.PodRunning && podReady(pod) {
+			continue
+		}
+		revision := pod.Labels[appsv1.StatefulSetRevisionLabel]
+		if pod.Spec.NodeName == "" && pending == nil && revision == status.UpdateRevision {
+			pending = pod
+			d.Add(PriorityRuntime, "PodPending", fmt.Sprintf("pod %s cannot be scheduled, see the scheduling diagnosis below", pod.Name),
+				EventsWithReason(ListEvents(r.ClientSet, pod.Namespace, "Pod", pod.Name), "FailedScheduling")...)
+		} else if verdict := DiagnosePod(r.ClientSet, pod).Verdict(); verdict != nil && verdict.Priority < PriorityHealthy {
+			evidence := append([]string{verdict.Message, "revision " + revision}, verdict.Evidence...)
+			d.Add(verdict.Priority, verdict.Reason, fmt.Sprintf("pod %s is not ready, run why-not-running %s -n %s", pod.Name, pod.Name, pod.Namespace), evidence...)
+		}
+		if ordered {
+			d.Add(PrioritySymptom-5, "OrderedReadyBlocked",
+				fmt.Sprintf("podManagementPolicy is OrderedReady, pods after %s wait until it is Running and Ready", pod.Name))
+			break
+		}
+	}
+
+	if status.UpdatedReplicas == replicas && status.ReadyReplicas == replicas && status.CurrentRevision == status.UpdateRevision {
+		d.Add(PriorityHealthy, "Complete", "rollout is complete")
+	}
+	return d, pending, nil
+}
+
+// checkClaimTemplates reports the claims created from volumeClaimTemplates that are missing or not bound.
+func (r *RolloutDiagnoser) checkClaimTemplates(sts *appsv1.StatefulSet, start int, replicas int32, d *Diagnosis) {
+	var unbound []string
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		for ordinal := start; ordinal < start+int(replicas); ordinal++ {
+			claimName := fmt.Sprintf("%s-%s-%d", template.Name, sts.Name, ordinal)
+			pvc, err := r.ClientSet.CoreV1().PersistentVolumeClaims(sts.Namespace).Get(context.TODO(), claimName, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err == nil && pvc.Status.Phase != v1.ClaimBound {
+				unbound = append(unbound, fmt.Sprintf("%s: %s, run why-pvc %s -n %s", claimName, pvc.Status.Phase, claimName, sts.Namespace))
+			}
+		}
+	}
+	if len(unbound) > 0 {
+		d.Add(PriorityConfig+5, "UnboundClaims", "claims created from volumeClaimTemplates are not bound", unbound...)
+	}
+}
+
+func podOrdinal(setName, podName string) (int, bool) {
+	prefix := setName + "-"
+	if len(podName) <= len(prefix) || podName[:len(prefix)] != prefix {
+		return 0, false
+	}
+	ordinal, err := strconv.Atoi(podName[len(prefix):])
+	return ordinal, err == nil
+}
@@ -0,0 +1,177 @@
+package diagnosis
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRolloutDiagnoser_diagnoseDeployment(t *testing.T) {
+	withStatus := func(deploy *appsv1.Deployment, replicas, updated, available int32) *appsv1.Deployment {
+		deploy.Status = appsv1.DeploymentStatus{Replicas: replicas, UpdatedReplicas: updated, ReadyReplicas: available, AvailableReplicas: available}
+		return deploy
+	}
+
+	paused := withStatus(newTestDeployment("web", 4, "2"), 5, 1, 4)
+	paused.Spec.Paused = true
+	blocked := withStatus(newTestDeployment("web", 4, "2"), 5, 1, 4)
+	deadline := withStatus(newTestDeployment("web", 4, "2"), 4, 0, 4)
+	deadline.Spec.Strategy.RollingUpdate = nil
+	deadline.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: v1.ConditionFalse,
+		Reason: "ProgressDeadlineExceeded", Message: `ReplicaSet "web-2" has timed out progressing.`}}
+	complete := withStatus(newTestDeployment("web", 4, "2"), 4, 4, 4)
+	failedCreate := &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "web-2.quota"},
+		InvolvedObject: v1.ObjectReference{Kind: "ReplicaSet", Namespace: "default", Name: "web-2"},
+		Type:           v1.EventTypeWarning, Reason: "FailedCreate", LastTimestamp: metav1.NewTime(time.Now()),
+		Message: `pods "web-2-x" is forbidden: exceeded quota: compute`,
+	}
+
+	tests := []struct {
+		name        string
+		deploy      *appsv1.Deployment
+		objects     []runtime.Object
+		wantVerdict string
+		wantPending string
+	}{
+		{
+			name:        "paused",
+			deploy:      paused,
+			objects:     []runtime.Object{newTestReplicaSet(paused, "1", 4), newTestReplicaSet(paused, "2", 1)},
+			wantVerdict: "Paused",
+		},
+		{
+			name:   "surge used up by a new pod not ready",
+			deploy: blocked,
+			objects: []runtime.Object{newTestReplicaSet(blocked, "1", 4), newTestReplicaSet(blocked, "2", 1),
+				newTestOwnedPod(newTestReplicaSet(blocked, "2", 1), "ReplicaSet", "web-2-a", "node1", false)},
+			wantVerdict: "RollingUpdateBlocked",
+		},
+		{
+			name:   "new pod pending",
+			deploy: blocked,
+			objects: []runtime.Object{newTestReplicaSet(blocked, "1", 4), newTestReplicaSet(blocked, "2", 1),
+				newTestOwnedPod(newTestReplicaSet(blocked, "2", 1), "ReplicaSet", "web-2-a", "", false)},
+			wantVerdict: "NewPodPending",
+			wantPending: "web-2-a",
+		},
+		{
+			name:        "new pods rejected",
+			deploy:      blocked,
+			objects:     []runtime.Object{newTestReplicaSet(blocked, "1", 4), newTestReplicaSet(blocked, "2", 0), failedCreate},
+			wantVerdict: "FailedCreate",
+		},
+		{
+			name:        "progress deadline exceeded without a new replicaset",
+			deploy:      deadline,
+			objects:     []runtime.Object{newTestReplicaSet(deadline, "1", 4)},
+			wantVerdict: "ProgressDeadlineExceeded",
+		},
+		{
+			name:   "progressing",
+			deploy: withStatus(newTestDeployment("web", 4, "2"), 4, 2, 4),
+			objects: []runtime.Object{newTestReplicaSet(complete, "1", 2), newTestReplicaSet(complete, "2", 2),
+				newTestOwnedPod(newTestReplicaSet(complete, "2", 2), "ReplicaSet", "web-2-a", "node1", true)},
+			wantVerdict: "Progressing",
+		},
+		{
+			name:        "complete",
+			deploy:      complete,
+			objects:     []runtime.Object{newTestReplicaSet(complete, "2", 4)},
+			wantVerdict: "Complete",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RolloutDiagnoser{ClientSet: fake.NewSimpleClientset(append(tt.objects, tt.deploy)...), Namespace: "default"}
+			d, pending, err := r.diagnoseDeployment(tt.deploy.Name)
+			if err != nil {
+				t.Fatalf("diagnoseDeployment() error = %v", err)
+			}
+			if verdict := d.Verdict(); verdict.Reason != tt.wantVerdict {
+				t.Errorf("diagnoseDeployment() verdict = %s: %s, want %s", verdict.Reason, verdict.Message, tt.wantVerdict)
+			}
+			gotPending := ""
+			if pending != nil {
+				gotPending = pending.Name
+			}
+			if gotPending != tt.wantPending {
+				t.Errorf("diagnoseDeployment() pending = %q, want %q", gotPending, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestRolloutDiagnoser_diagnoseStatefulSet(t *testing.T) {
+	withRevision := func(pod *v1.Pod, revision string) *v1.Pod {
+		pod.Labels = map[string]string{appsv1.StatefulSetRevisionLabel: revision}
+		return pod
+	}
+	rolling := newTestStatefulSet("db", 3)
+	rolling.Status.UpdatedReplicas = 1
+	onDelete := newTestStatefulSet("db", 3)
+	onDelete.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+
+	tests := []struct {
+		name        string
+		sts         *appsv1.StatefulSet
+		objects     []runtime.Object
+		wantVerdict string
+		wantPending string
+	}{
+		{
+			name: "ordered rollout waits for a pod not ready",
+			sts:  rolling,
+			objects: []runtime.Object{
+				withRevision(newTestOwnedPod(rolling, "StatefulSet", "db-0", "node1", true), "db-1"),
+				withRevision(newTestOwnedPod(rolling, "StatefulSet", "db-1", "node2", false), "db-1"),
+				withRevision(newTestOwnedPod(rolling, "StatefulSet", "db-2", "node3", true), "db-2"),
+			},
+			wantVerdict: "OrderedReadyBlocked",
+		},
+		{
+			name: "pod of the update revision pending",
+			sts:  rolling,
+			objects: []runtime.Object{
+				withRevision(newTestOwnedPod(rolling, "StatefulSet", "db-0", "node1", true), "db-1"),
+				withRevision(newTestOwnedPod(rolling, "StatefulSet", "db-1", "", false), "db-2"),
+			},
+			wantVerdict: "PodPending",
+			wantPending: "db-1",
+		},
+		{
+			name: "on delete",
+			sts:  onDelete,
+			objects: []runtime.Object{
+				withRevision(newTestOwnedPod(onDelete, "StatefulSet", "db-0", "node1", true), "db-1"),
+				withRevision(newTestOwnedPod(onDelete, "StatefulSet", "db-1", "node2", true), "db-1"),
+				withRevision(newTestOwnedPod(onDelete, "StatefulSet", "db-2", "node3", true), "db-1"),
+			},
+			wantVerdict: "OnDelete",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RolloutDiagnoser{ClientSet: fake.NewSimpleClientset(append(tt.objects, tt.sts)...), Namespace: "default"}
+			d, pending, err := r.diagnoseStatefulSet(tt.sts.Name)
+			if err != nil {
+				t.Fatalf("diagnoseStatefulSet() error = %v", err)
+			}
+			if verdict := d.Verdict(); verdict.Reason != tt.wantVerdict {
+				t.Errorf("diagnoseStatefulSet() verdict = %s: %s, want %s", verdict.Reason, verdict.Message, tt.wantVerdict)
+			}
+			gotPending := ""
+			if pending != nil {
+				gotPending = pending.Name
+			}
+			if gotPending != tt.wantPending {
+				t.Errorf("diagnoseStatefulSet() pending = %q, want %q", gotPending, tt.wantPending)
+			}
+		})
+	}
+}
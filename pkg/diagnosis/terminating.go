@@ -0,0 +1,272 @@
+package diagnosis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// TerminatingDiagnoser explains why a pod or a namespace is stuck in Terminating.
+type TerminatingDiagnoser struct {
+	ClientSet     kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+func (t *TerminatingDiagnoser) WhyTerminating() error {
+	var d *Diagnosis
+	var err error
+	switch t.Kind {
+	case "pod":
+		d, err = t.diagnosePod()
+	case "namespace":
+		d, err = t.diagnoseNamespace()
+	default:
+		return fmt.Errorf("unsupported kind %q, expected pod or namespace", t.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	d.Print()
+	return nil
+}
+
+func (t *TerminatingDiagnoser) diagnosePod() (*Diagnosis, error) {
+	pod, err := t.ClientSet.CoreV1().Pods(t.Namespace).Get(context.TODO(), t.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s: %w", t.Namespace, t.Name, err)
+	}
+	d := NewDiagnosis(fmt.Sprintf("pod %s/%s (node %s)", pod.Namespace, pod.Name, pod.Spec.NodeName))
+	if pod.DeletionTimestamp == nil {
+		d.Add(PriorityHealthy, "NotTerminating", "pod has no deletionTimestamp")
+		return d, nil
+	}
+
+	grace := int64(0)
+	if pod.DeletionGracePeriodSeconds != nil {
+		grace = *pod.DeletionGracePeriodSeconds
+	}
+	// deletionTimestamp is already set to the time the grace period ends
+	overdue := time.Since(pod.DeletionTimestamp.Time)
+	deletion := fmt.Sprintf("deletionTimestamp %s, grace period %ds", pod.DeletionTimestamp.Format(time.RFC3339), grace)
+	if overdue < 0 {
+		d.Add(PrioritySymptom, "GracePeriod", fmt.Sprintf("pod is still within its grace period, %s left", duration.HumanDuration(-overdue)), deletion)
+	} else {
+		d.Add(PriorityWarning, "Overdue", fmt.Sprintf("grace period ended %s ago", Age(pod.DeletionTimestamp.Time)), deletion)
+	}
+
+	if len(pod.Finalizers) > 0 {
+		d.Add(PriorityBlocker+5, "Finalizers", "pod cannot be removed until its finalizers are cleared by their controllers",
+			pod.Finalizers...)
+	}
+
+	if pod.Spec.NodeName != "" {
+		t.checkPodNode(pod, d)
+		t.checkVolumeDetach(pod, d)
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil {
+			d.Add(PrioritySymptom, "ContainerRunning", fmt.Sprintf("container %s is still reported running, kubelet has not finished killing it", status.Name))
+		}
+	}
+	return d, nil
+}
+
+func (t *TerminatingDiagnoser) checkPodNode(pod *v1.Pod, d *Diagnosis) {
+	node, err := t.ClientSet.CoreV1().Nodes().Get(context.TODO(), pod.Spec.NodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		d.Add(PriorityBlocker, "NodeDeleted", fmt.Sprintf("node %s no longer exists, the pod is waiting for garbage collection", pod.Spec.NodeName),
+			"force delete: kubectl delete pod --grace-period=0 --force")
+		return
+	}
+	if err != nil {
+		fmt.Printf("error fetching node %s: %v\n", pod.Spec.NodeName, err)
+		return
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != v1.NodeReady || condition.Status == v1.ConditionTrue {
+			continue
+		}
+		d.Add(PriorityBlocker, "NodeNotReady",
+			fmt.Sprintf("node %s is %s since %s ago, its kubelet cannot confirm the containers are gone", node.Name, condition.Status, Age(condition.LastTransitionTime.Time)),
+			fmt.Sprintf("%s: %s", condition.Reason, condition.Message),
+			"force delete only after making sure the node is really down: kubectl delete pod --grace-period=0 --force")
+	}
+}
+
+// checkVolumeDetach reports VolumeAttachments of the pod's PVs that are still attached to its node.
+func (t *TerminatingDiagnoser) checkVolumeDetach(pod *v1.Pod, d *Diagnosis) {
+	pvs := map[string]string{}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := t.ClientSet.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(context.TODO(), volume.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+		if err != nil || pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pvs[pvc.Spec.VolumeName] = pvc.Name
+	}
+	if len(pvs) == 0 {
+		return
+	}
+
+	attachments, err := t.ClientSet.StorageV1().VolumeAttachments().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("error listing volumeattachments: %v\n", err)
+		return
+	}
+	var evidence []string
+	for _, attachment := range attachments.Items {
+		pv := attachment.Spec.Source.PersistentVolumeName
+		if pv == nil || attachment.Spec.NodeName != pod.Spec.NodeName {
+			continue
+		}
+		claim, ok := pvs[*pv]
+		if !ok || !attachment.Status.Attached {
+			continue
+		}
+		line := fmt.Sprintf("%s: pv %s (pvc %s) attached to %s", attachment.Name, *pv, claim, attachment.Spec.NodeName)
+		if attachment.Status.DetachError != nil {
+			line += ", detach error: " + attachment.Status.DetachError.Message
+		}
+		evidence = append(evidence, line)
+	}
+	if len(evidence) > 0 {
+		d.Add(PrioritySymptom, "VolumesAttached", "volumes of the pod are still attached to its node", evidence...)
+	}
+}
+
+// namespacedResource is a resource type that still has objects in the namespace.
+type namespacedResource struct {
+	resource   schema.GroupVersionResource
+	count      int
+	finalizers map[string]int
+}
+
+func (t *TerminatingDiagnoser) diagnoseNamespace() (*Diagnosis, error) {
+	ns, err := t.ClientSet.CoreV1().Namespaces().Get(context.TODO(), t.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %w", t.Name, err)
+	}
+	d := NewDiagnosis(fmt.Sprintf("namespace %s (%s)", ns.Name, ns.Status.Phase))
+	if ns.DeletionTimestamp == nil {
+		d.Add(PriorityHealthy, "NotTerminating", "namespace has no deletionTimestamp")
+		return d, nil
+	}
+	d.Add(PriorityWarning, "Terminating", fmt.Sprintf("namespace is being deleted since %s ago", Age(ns.DeletionTimestamp.Time)))
+
+	for _, condition := range ns.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		priority := PrioritySymptom
+		switch condition.Type {
+		case v1.NamespaceDeletionDiscoveryFailure, v1.NamespaceDeletionGVParsingFailure, v1.NamespaceDeletionContentFailure:
+			priority = PriorityBlocker
+		}
+		d.Add(priority, string(condition.Type), condition.Message, fmt.Sprintf("%s since %s ago", condition.Reason, Age(condition.LastTransitionTime.Time)))
+	}
+
+	resources, failed := t.remainingResources(ns.Name)
+	if len(failed) > 0 {
+		d.Add(PriorityBlocker, "DiscoveryFailed", "some API groups are unavailable, the namespace controller cannot delete their objects; check the APIServices",
+			failed...)
+	}
+	for _, resource := range resources {
+		var evidence []string
+		for finalizer, count := range resource.finalizers {
+			evidence = append(evidence, fmt.Sprintf("%d with finalizer %s", count, finalizer))
+		}
+		sort.Strings(evidence)
+		name := resource.resource.Resource
+		if resource.resource.Group != "" {
+			name += "." + resource.resource.Group
+		}
+		if len(evidence) > 0 {
+			d.Add(PriorityBlocker+5, "FinalizersRemaining", fmt.Sprintf("%d %s left, held by finalizers", resource.count, name), evidence...)
+		} else {
+			d.Add(PrioritySymptom, "ContentRemaining", fmt.Sprintf("%d %s left", resource.count, name))
+		}
+	}
+
+	if len(ns.Spec.Finalizers) > 0 {
+		finalizers := make([]string, 0, len(ns.Spec.Finalizers))
+		for _, finalizer := range ns.Spec.Finalizers {
+			finalizers = append(finalizers, string(finalizer))
+		}
+		d.Add(PriorityWarning, "NamespaceFinalizers", "namespace finalizers are removed once all content is gone", finalizers...)
+	}
+	return d, nil
+}
+
+// remainingResources discovers every namespaced, listable resource and returns those still having
+// objects in the namespace, together with the API groups that failed discovery.
+func (t *TerminatingDiagnoser) remainingResources(namespace string) ([]*namespacedResource, []string) {
+	var failed []string
+	lists, err := t.ClientSet.Discovery().ServerPreferredNamespacedResources()
+	if err != nil {
+		if groupErr, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+			for gv, e := range groupErr.Groups {
+				failed = append(failed, fmt.Sprintf("%s: %v", gv.String(), e))
+			}
+			sort.Strings(failed)
+		} else {
+			failed = append(failed, err.Error())
+		}
+	}
+
+	var result []*namespacedResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, apiResource := range list.APIResources {
+			if !hasVerb(apiResource.Verbs, "list") || strings.Contains(apiResource.Name, "/") {
+				continue
+			}
+			gvr := gv.WithResource(apiResource.Name)
+			objects, err := t.DynamicClient.Resource(gvr).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", gvr.String(), err))
+				continue
+			}
+			if len(objects.Items) == 0 {
+				continue
+			}
+			resource := &namespacedResource{resource: gvr, count: len(objects.Items), finalizers: map[string]int{}}
+			for _, object := range objects.Items {
+				for _, finalizer := range object.GetFinalizers() {
+					resource.finalizers[finalizer]++
+				}
+			}
+			result = append(result, resource)
+		}
+	}
+	return result, failed
+}
+
+func hasVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
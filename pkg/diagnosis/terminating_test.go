@@ -0,0 +1,88 @@
+package diagnosis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTerminatingDiagnoser_diagnosePod(t *testing.T) {
+	terminating := func(deadline time.Duration, finalizers ...string) *v1.Pod {
+		pod := newTestPod("default", "web-0", "node1")
+		deletion := metav1.NewTime(time.Now().Add(deadline))
+		grace := int64(30)
+		pod.DeletionTimestamp, pod.DeletionGracePeriodSeconds = &deletion, &grace
+		pod.Finalizers = finalizers
+		return pod
+	}
+
+	tests := []struct {
+		name        string
+		pod         *v1.Pod
+		node        *v1.Node
+		wantVerdict string
+		wantMessage string
+	}{
+		{
+			name:        "within the grace period",
+			pod:         terminating(10 * time.Minute),
+			node:        newTestNode("node1", v1.ConditionTrue),
+			wantVerdict: "GracePeriod",
+			wantMessage: "pod is still within its grace period, 9m",
+		},
+		{
+			name:        "grace period ended",
+			pod:         terminating(-10 * time.Minute),
+			node:        newTestNode("node1", v1.ConditionTrue),
+			wantVerdict: "Overdue",
+			wantMessage: "grace period ended 10m ago",
+		},
+		{
+			name:        "finalizers",
+			pod:         terminating(-10*time.Minute, "example.com/cleanup"),
+			node:        newTestNode("node1", v1.ConditionTrue),
+			wantVerdict: "Finalizers",
+		},
+		{
+			name:        "node not ready",
+			pod:         terminating(-10*time.Minute, "example.com/cleanup"),
+			node:        newTestNode("node1", v1.ConditionUnknown),
+			wantVerdict: "NodeNotReady",
+		},
+		{
+			name:        "node deleted",
+			pod:         terminating(-10 * time.Minute),
+			wantVerdict: "NodeDeleted",
+		},
+		{
+			name:        "not terminating",
+			pod:         newTestPod("default", "web-0", "node1"),
+			node:        newTestNode("node1", v1.ConditionTrue),
+			wantVerdict: "NotTerminating",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(tt.pod)
+			if tt.node != nil {
+				clientSet = fake.NewSimpleClientset(tt.pod, tt.node)
+			}
+			diagnoser := &TerminatingDiagnoser{ClientSet: clientSet, Kind: "pod", Namespace: "default", Name: "web-0"}
+			d, err := diagnoser.diagnosePod()
+			if err != nil {
+				t.Fatalf("diagnosePod() error = %v", err)
+			}
+			verdict := d.Verdict()
+			if verdict.Reason != tt.wantVerdict {
+				t.Errorf("diagnosePod() verdict = %s, want %s", verdict.Reason, tt.wantVerdict)
+			}
+			if tt.wantMessage != "" && !strings.HasPrefix(verdict.Message, tt.wantMessage) {
+				t.Errorf("diagnosePod() message = %q, want prefix %q", verdict.Message, tt.wantMessage)
+			}
+		})
+	}
+}
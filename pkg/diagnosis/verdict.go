@@ -0,0 +1,160 @@
+package diagnosis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/util"
+)
+
+// Priorities of findings, the lowest one found is the verdict. A root cause that explains
+// other symptoms must have a lower priority than the symptoms.
+const (
+	PriorityBlocker = 0
+	PriorityConfig  = 10
+	PriorityRuntime = 20
+	PrioritySymptom = 40
+	PriorityWarning = 60
+	PriorityHealthy = 100
+)
+
+type Finding struct {
+	Priority int
+	Reason   string
+	Message  string
+	Evidence []string
+}
+
+func (f *Finding) ToStringList() []string {
+	reason := util.NewRedText(f.Reason)
+	if f.Priority >= PriorityWarning {
+		reason = util.ColorText{Text: f.Reason}
+	}
+	return []string{reason.String(), f.Message, strings.Join(f.Evidence, "\n")}
+}
+
+// Diagnosis collects the findings about one object and elects the root cause among them.
+type Diagnosis struct {
+	Object   string
+	Findings []*Finding
+}
+
+func NewDiagnosis(object string) *Diagnosis {
+	return &Diagnosis{Object: object}
+}
+
+func (d *Diagnosis) Add(priority int, reason, message string, evidence ...string) *Finding {
+	finding := &Finding{Priority: priority, Reason: reason, Message: message, Evidence: evidence}
+	d.Findings = append(d.Findings, finding)
+	return finding
+}
+
+// Verdict returns the finding with the lowest priority, nil when nothing was found.
+func (d *Diagnosis) Verdict() *Finding {
+	sort.SliceStable(d.Findings, func(i, j int) bool {
+		return d.Findings[i].Priority < d.Findings[j].Priority
+	})
+	if len(d.Findings) == 0 {
+		return nil
+	}
+	return d.Findings[0]
+}
+
+func (d *Diagnosis) Print() {
+	verdict := d.Verdict()
+	fmt.Printf("%s\n", d.Object)
+	if verdict == nil {
+		fmt.Printf("Verdict: %s\n", util.NewGreenText("nothing wrong found"))
+		return
+	}
+	if verdict.Priority >= PriorityHealthy {
+		fmt.Printf("Verdict: %s\n", util.NewGreenText(fmt.Sprintf("%s: %s", verdict.Reason, verdict.Message)))
+	} else {
+		fmt.Printf("Verdict: %s\n", util.NewRedText(fmt.Sprintf("%s: %s", verdict.Reason, verdict.Message)))
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Reason", "Message", "Evidence"})
+	for _, finding := range d.Findings {
+		t.AppendRow(util.ListToRow(finding.ToStringList()))
+	}
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 2, WidthMax: 60}, {Number: 3, WidthMax: 80}})
+	t.Render()
+}
+
+// ListEvents returns the events of an object, oldest first.
+func ListEvents(clientSet kubernetes.Interface, namespace, kind, name string) []v1.Event {
+	events, err := clientSet.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, name),
+	})
+	if err != nil {
+		fmt.Printf("error fetching events of %s %s/%s: %v\n", kind, namespace, name, err)
+		return nil
+	}
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return eventTime(&events.Items[i]).Before(eventTime(&events.Items[j]))
+	})
+	return events.Items
+}
+
+func eventTime(event *v1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if event.Series != nil {
+		return event.Series.LastObservedTime.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// FormatEvent renders an event as evidence: "reason (xN, age ago): message".
+func FormatEvent(event *v1.Event) string {
+	count := event.Count
+	if event.Series != nil {
+		count = event.Series.Count
+	}
+	if count <= 0 {
+		count = 1
+	}
+	return fmt.Sprintf("%s (x%d, %s ago): %s", event.Reason, count, Age(eventTime(event)), strings.TrimSpace(event.Message))
+}
+
+// EventsWithReason returns the formatted events having one of the reasons.
+func EventsWithReason(events []v1.Event, reasons ...string) []string {
+	var result []string
+	for i := range events {
+		for _, reason := range reasons {
+			if events[i].Reason == reason {
+				result = append(result, FormatEvent(&events[i]))
+				break
+			}
+		}
+	}
+	return result
+}
+
+func Age(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t))
+}
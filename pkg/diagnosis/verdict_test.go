@@ -0,0 +1,42 @@
+package diagnosis
+
+import "testing"
+
+func TestDiagnosis_Verdict(t *testing.T) {
+	type finding struct {
+		priority int
+		reason   string
+	}
+	tests := []struct {
+		name     string
+		findings []finding
+		want     string
+	}{
+		{name: "nothing found", want: ""},
+		{
+			name:     "root cause before symptoms",
+			findings: []finding{{PrioritySymptom, "NotReady"}, {PriorityWarning, "OOMKilled"}, {PriorityConfig, "MissingSecret"}, {PriorityRuntime, "CrashLoopBackOff"}},
+			want:     "MissingSecret",
+		},
+		{
+			name:     "first found wins a tie",
+			findings: []finding{{PriorityRuntime, "ImagePullBackOff"}, {PriorityRuntime, "VolumeMountFailed"}},
+			want:     "ImagePullBackOff",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiagnosis("pod default/web")
+			for _, f := range tt.findings {
+				d.Add(f.priority, f.reason, "")
+			}
+			got := ""
+			if verdict := d.Verdict(); verdict != nil {
+				got = verdict.Reason
+			}
+			if got != tt.want {
+				t.Errorf("Verdict() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
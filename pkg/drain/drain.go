@@ -0,0 +1,269 @@
+package drain
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+)
+
+const (
+	ResultRescheduled = "Rescheduled"
+	ResultPending     = "Pending"
+	ResultPDBBlocked  = "BlockedByPDB"
+	ResultLocalPV     = "BlockedByLocalPV"
+	ResultUnmanaged   = "Unmanaged"
+	ResultSkipped     = "Skipped"
+)
+
+type DrainPlanner struct {
+	ClientSet *kubernetes.Clientset
+
+	Nodes    []string
+	Selector string
+}
+
+type PodPlan struct {
+	Pod    *v1.Pod
+	Owner  string
+	Result string
+	Detail string
+}
+
+func (p *PodPlan) ToStringList() []string {
+	result := util.NewGreenText(p.Result)
+	switch p.Result {
+	case ResultPending, ResultPDBBlocked, ResultLocalPV:
+		result = util.NewRedText(p.Result)
+	case ResultSkipped:
+		result = util.ColorText{Text: p.Result}
+	}
+	return []string{
+		fmt.Sprintf("%s/%s", p.Pod.Namespace, p.Pod.Name),
+		strings.Split(p.Pod.Spec.NodeName, "-")[0],
+		p.Owner,
+		result.String(),
+		p.Detail,
+	}
+}
+
+// TargetNodes resolves the nodes to drain from the given names or label selector.
+func (d *DrainPlanner) TargetNodes(allNodes []v1.Node) (sets.Set[string], error) {
+	targets := sets.New[string]()
+	if d.Selector != "" {
+		selector, err := labels.Parse(d.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", d.Selector, err)
+		}
+		for _, node := range allNodes {
+			if selector.Matches(labels.Set(node.Labels)) {
+				targets.Insert(node.Name)
+			}
+		}
+	}
+
+	known := sets.New[string]()
+	for _, node := range allNodes {
+		known.Insert(node.Name)
+	}
+	for _, name := range d.Nodes {
+		if !known.Has(name) {
+			return nil, fmt.Errorf("node %s not found", name)
+		}
+		targets.Insert(name)
+	}
+
+	if targets.Len() == 0 {
+		return nil, fmt.Errorf("no node matches")
+	}
+	return targets, nil
+}
+
+func (d *DrainPlanner) Plan() error {
+
+	snapshot, err := scheduler.TakeSnapshot(d.ClientSet)
+	if err != nil {
+		return err
+	}
+
+	targets, err := d.TargetNodes(snapshot.Nodes)
+	if err != nil {
+		return err
+	}
+
+	pdbs, err := d.ClientSet.PolicyV1().PodDisruptionBudgets("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list poddisruptionbudgets: %w", err)
+	}
+
+	plans := PlanOnSnapshot(d.ClientSet, snapshot, targets, pdbs.Items)
+	printPlans(plans)
+
+	counts := map[string]int{}
+	for _, plan := range plans {
+		counts[plan.Result]++
+	}
+	fmt.Printf("\ndraining %s: %d rescheduled, %d pending, %d blocked by PDB, %d blocked by local PV, %d unmanaged, %d skipped\n",
+		strings.Join(sets.List(targets), ","), counts[ResultRescheduled], counts[ResultPending], counts[ResultPDBBlocked],
+		counts[ResultLocalPV], counts[ResultUnmanaged], counts[ResultSkipped])
+	if counts[ResultPending]+counts[ResultPDBBlocked]+counts[ResultLocalPV] == 0 {
+		fmt.Println(util.NewGreenText("all evictable pods can be rescheduled"))
+	} else {
+		fmt.Println(util.NewRedText("drain would leave pods pending or blocked"))
+	}
+	return nil
+}
+
+// PlanOnSnapshot classifies every pod on the target nodes and re-places the evictable ones on
+// the rest of the cluster, biggest and most important pods first.
+func PlanOnSnapshot(clientSet kubernetes.Interface, snapshot *scheduler.Snapshot, targets sets.Set[string], pdbs []policyv1.PodDisruptionBudget) []*PodPlan {
+
+	var plans, evictable []*PodPlan
+	for i := range snapshot.Pods {
+		pod := &snapshot.Pods[i]
+		if !targets.Has(pod.Spec.NodeName) {
+			continue
+		}
+		plan := &PodPlan{Pod: pod, Owner: workloads.OwnerString(pod)}
+		plans = append(plans, plan)
+
+		switch {
+		case workloads.IsMirrorPod(pod):
+			plan.Result, plan.Detail = ResultSkipped, "mirror pod"
+		case workloads.IsDaemonSetPod(pod):
+			plan.Result, plan.Detail = ResultSkipped, "DaemonSet pod"
+		case workloads.IsTerminated(pod):
+			plan.Result, plan.Detail = ResultSkipped, fmt.Sprintf("pod %s", pod.Status.Phase)
+		case plan.Owner == "-":
+			plan.Result, plan.Detail = ResultUnmanaged, "no controller, deleted by drain --force and not recreated"
+		default:
+			if pv := localPV(clientSet, pod); pv != "" {
+				plan.Result, plan.Detail = ResultLocalPV, pv
+				continue
+			}
+			evictable = append(evictable, plan)
+		}
+	}
+
+	// the budget goes to the pods evicted first, not to the first ones listed
+	sort.SliceStable(evictable, func(i, j int) bool {
+		return scheduler.PlaceBefore(evictable[i].Pod, evictable[j].Pod)
+	})
+	blockByPDB(evictable, pdbs)
+
+	remaining := snapshot.WithoutNodes(targets)
+	for _, plan := range evictable {
+		if plan.Result != "" {
+			continue
+		}
+		pod := plan.Pod.DeepCopy()
+		pod.Spec.NodeName = ""
+		placement := remaining.Place(pod)
+		if placement.Scheduled() {
+			plan.Result, plan.Detail = ResultRescheduled, strings.Split(placement.NodeName, "-")[0]
+		} else {
+			plan.Result, plan.Detail = ResultPending, placement.Reason()
+		}
+		if hasEmptyDir(plan.Pod) {
+			plan.Detail += "\nemptyDir data is lost"
+		}
+	}
+
+	return plans
+}
+
+// blockByPDB marks the evictions a PodDisruptionBudget would refuse right now.
+func blockByPDB(plans []*PodPlan, pdbs []policyv1.PodDisruptionBudget) {
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		allowed := pdb.Status.DisruptionsAllowed
+		for _, plan := range plans {
+			if plan.Pod.Namespace != pdb.Namespace || !selector.Matches(labels.Set(plan.Pod.Labels)) {
+				continue
+			}
+			if allowed > 0 {
+				allowed--
+				continue
+			}
+			plan.Result = ResultPDBBlocked
+			plan.Detail = fmt.Sprintf("pdb %s allows %d disruptions (%d/%d healthy)", pdb.Name,
+				pdb.Status.DisruptionsAllowed, pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy)
+		}
+	}
+}
+
+// localPV returns the pvc whose pv lives on the pod's node and cannot follow the pod, if any.
+func localPV(clientSet kubernetes.Interface, pod *v1.Pod) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := clientSet.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(context.TODO(), volume.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+		if err != nil || pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := clientSet.CoreV1().PersistentVolumes().Get(context.TODO(), pvc.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		if pv.Spec.Local != nil || pv.Spec.HostPath != nil || pinnedToHost(pv) {
+			return fmt.Sprintf("pvc %s bound to local pv %s", pvc.Name, pv.Name)
+		}
+	}
+	return ""
+}
+
+// pinnedToHost reports whether the pv's node affinity selects a single host, like directpv volumes do.
+func pinnedToHost(pv *v1.PersistentVolume) bool {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return false
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == v1.LabelHostname || expression.Key == "directpv.min.io/node" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasEmptyDir(pod *v1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func printPlans(plans []*PodPlan) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Namespace/PodName", "NodeName", "Owner", "Result", "Detail"})
+	for _, plan := range plans {
+		t.AppendRow(util.ListToRow(plan.ToStringList()))
+	}
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
@@ -0,0 +1,155 @@
+package drain
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/scheduler/schedulertest"
+)
+
+func TestBlockByPDB(t *testing.T) {
+	newPlan := func(namespace, name string, labels map[string]string) *PodPlan {
+		return &PodPlan{Pod: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}}
+	}
+	pdb := policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+	}
+
+	tests := []struct {
+		name  string
+		plans []*PodPlan
+		want  []string
+	}{
+		{
+			name:  "within budget",
+			plans: []*PodPlan{newPlan("default", "web-0", map[string]string{"app": "web"})},
+			want:  []string{""},
+		},
+		{
+			name: "over budget",
+			plans: []*PodPlan{
+				newPlan("default", "web-0", map[string]string{"app": "web"}),
+				newPlan("default", "web-1", map[string]string{"app": "web"}),
+			},
+			want: []string{"", ResultPDBBlocked},
+		},
+		{
+			name: "other namespace and labels",
+			plans: []*PodPlan{
+				newPlan("other", "web-0", map[string]string{"app": "web"}),
+				newPlan("default", "db-0", map[string]string{"app": "db"}),
+			},
+			want: []string{"", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockByPDB(tt.plans, []policyv1.PodDisruptionBudget{pdb})
+			for i, plan := range tt.plans {
+				if plan.Result != tt.want[i] {
+					t.Errorf("blockByPDB() pod %s = %q, want %q", plan.Pod.Name, plan.Result, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPlanOnSnapshot(t *testing.T) {
+	web := schedulertest.OwnedBy(schedulertest.Pod("web-0", "node1", map[string]string{"app": "web"}, "1", "1Gi"), "Deployment", "web")
+	web.Spec.Volumes = []v1.Volume{{Name: "cache", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+	mirror := schedulertest.Pod("etcd-node1", "node1", nil, "100m", "128Mi")
+	mirror.Annotations = map[string]string{v1.MirrorPodAnnotationKey: "hash"}
+	completed := schedulertest.OwnedBy(schedulertest.Pod("migrate-0", "node1", nil, "100m", "128Mi"), "Job", "migrate")
+	completed.Status.Phase = v1.PodSucceeded
+	local := schedulertest.OwnedBy(schedulertest.Pod("data-0", "node1", nil, "1", "1Gi"), "StatefulSet", "data")
+	local.Spec.Volumes = []v1.Volume{{Name: "data", VolumeSource: v1.VolumeSource{
+		PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data-data-0"},
+	}}}
+	clientSet := schedulertest.ClientSet(
+		&v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data-data-0"}, Spec: v1.PersistentVolumeClaimSpec{VolumeName: "local-pv"}},
+		&v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "local-pv"}, Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{Local: &v1.LocalVolumeSource{Path: "/mnt/disk1"}},
+		}},
+	)
+
+	nodes := []v1.Node{
+		schedulertest.Node("node1", nil, "16", "32Gi"),
+		schedulertest.Node("node2", nil, "4", "8Gi"),
+	}
+	pods := []v1.Pod{
+		web,
+		schedulertest.OwnedBy(schedulertest.Pod("web-2", "node1", map[string]string{"app": "web"}, "2", "1Gi"), "Deployment", "web"),
+		schedulertest.OwnedBy(schedulertest.Pod("batch-0", "node1", nil, "8", "1Gi"), "StatefulSet", "batch"),
+		mirror,
+		schedulertest.OwnedBy(schedulertest.Pod("agent-a", "node1", nil, "100m", "128Mi"), "DaemonSet", "agent"),
+		completed,
+		schedulertest.Pod("debug", "node1", nil, "100m", "128Mi"),
+		local,
+		schedulertest.OwnedBy(schedulertest.Pod("web-1", "node2", map[string]string{"app": "web"}, "1", "1Gi"), "Deployment", "web"),
+	}
+
+	type plan struct {
+		Pod    string
+		Result string
+		Detail string
+	}
+	tests := []struct {
+		name string
+		pdbs []policyv1.PodDisruptionBudget
+		want []plan
+	}{
+		{
+			name: "classify and re-place",
+			want: []plan{
+				{Pod: "web-0", Result: ResultRescheduled, Detail: "node2\nemptyDir data is lost"},
+				{Pod: "web-2", Result: ResultRescheduled, Detail: "node2"},
+				{Pod: "batch-0", Result: ResultPending, Detail: "0/1 nodes are available: 1 resource"},
+				{Pod: "etcd-node1", Result: ResultSkipped, Detail: "mirror pod"},
+				{Pod: "agent-a", Result: ResultSkipped, Detail: "DaemonSet pod"},
+				{Pod: "migrate-0", Result: ResultSkipped, Detail: "pod Succeeded"},
+				{Pod: "debug", Result: ResultUnmanaged, Detail: "no controller, deleted by drain --force and not recreated"},
+				{Pod: "data-0", Result: ResultLocalPV, Detail: "pvc data-data-0 bound to local pv local-pv"},
+			},
+		},
+		{
+			name: "pdb budget goes to the pods evicted first",
+			pdbs: []policyv1.PodDisruptionBudget{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+				Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1, CurrentHealthy: 3, DesiredHealthy: 2},
+			}},
+			want: []plan{
+				{Pod: "web-0", Result: ResultPDBBlocked, Detail: "pdb web allows 1 disruptions (3/2 healthy)"},
+				{Pod: "web-2", Result: ResultRescheduled, Detail: "node2"},
+				{Pod: "batch-0", Result: ResultPending, Detail: "0/1 nodes are available: 1 resource"},
+				{Pod: "etcd-node1", Result: ResultSkipped, Detail: "mirror pod"},
+				{Pod: "agent-a", Result: ResultSkipped, Detail: "DaemonSet pod"},
+				{Pod: "migrate-0", Result: ResultSkipped, Detail: "pod Succeeded"},
+				{Pod: "debug", Result: ResultUnmanaged, Detail: "no controller, deleted by drain --force and not recreated"},
+				{Pod: "data-0", Result: ResultLocalPV, Detail: "pvc data-data-0 bound to local pv local-pv"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := scheduler.NewSnapshot(clientSet, nodes, pods)
+			var got []plan
+			for _, p := range PlanOnSnapshot(clientSet, snapshot, sets.New("node1"), tt.pdbs) {
+				got = append(got, plan{Pod: p.Pod.Name, Result: p.Result, Detail: p.Detail})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanOnSnapshot() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
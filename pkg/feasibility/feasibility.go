@@ -0,0 +1,274 @@
+package feasibility
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/daemonsets"
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+)
+
+type FeasibilityReporter struct {
+	ClientSet *kubernetes.Clientset
+
+	Namespace string
+	// PoolLabel groups the nodes into the columns of the matrix.
+	PoolLabel string
+}
+
+// Workload is the pod template of a Deployment, StatefulSet or DaemonSet.
+type Workload struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Replicas  int32
+	Pod       *v1.Pod
+	// Error is set when the pod cannot be built, e.g. its RuntimeClass does not exist.
+	Error string
+}
+
+func (w *Workload) String() string {
+	return fmt.Sprintf("%s/%s/%s", w.Kind, w.Namespace, w.Name)
+}
+
+// WorkloadFeasibility is the number of nodes per pool passing the static checks.
+type WorkloadFeasibility struct {
+	Workload *Workload
+	Eligible map[string]int
+	Total    int
+	// Failed counts the nodes ruled out by each check.
+	Failed map[string]int
+}
+
+// Risk returns why the eligible nodes are too few for the workload, empty when they are not.
+func (wf *WorkloadFeasibility) Risk() string {
+	w := wf.Workload
+	switch {
+	case w.Error != "":
+		return w.Error
+	case wf.Total == 0:
+		return "no eligible node"
+	case w.Kind != "DaemonSet" && int32(wf.Total) < w.Replicas:
+		return fmt.Sprintf("%d eligible nodes for %d replicas", wf.Total, w.Replicas)
+	}
+	return ""
+}
+
+// FailedSummary lists the checks ruling nodes out, the most frequent first.
+func (wf *WorkloadFeasibility) FailedSummary() string {
+	var checks []string
+	for check := range wf.Failed {
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		if wf.Failed[checks[i]] != wf.Failed[checks[j]] {
+			return wf.Failed[checks[i]] > wf.Failed[checks[j]]
+		}
+		return checks[i] < checks[j]
+	})
+	var result []string
+	for _, check := range checks {
+		result = append(result, fmt.Sprintf("%s(%d)", check, wf.Failed[check]))
+	}
+	return strings.Join(result, ", ")
+}
+
+// Evaluate runs the analyzer's static checks for every workload on every node of the snapshot.
+func Evaluate(clientSet kubernetes.Interface, workloadList []*Workload, snapshot *scheduler.Snapshot, pools map[string]string) []*WorkloadFeasibility {
+	var result []*WorkloadFeasibility
+	for _, w := range workloadList {
+		wf := &WorkloadFeasibility{Workload: w, Eligible: make(map[string]int), Failed: make(map[string]int)}
+		result = append(result, wf)
+		if w.Error != "" {
+			continue
+		}
+		analyzer := scheduler.NewAnalyzerForPod(clientSet, w.Pod, snapshot)
+		for i := range snapshot.Nodes {
+			node := &snapshot.Nodes[i]
+			report := analyzer.DiagnoseNodeChecks(node, scheduler.StaticChecks...)
+			if failed := report.FailedChecks(); len(failed) > 0 {
+				for _, check := range failed {
+					wf.Failed[check]++
+				}
+				continue
+			}
+			wf.Eligible[pools[node.Name]]++
+			wf.Total++
+		}
+	}
+	return result
+}
+
+// listWorkloads builds the pods of the Deployments, StatefulSets and DaemonSets with their
+// RuntimeClass scheduling constraints merged in.
+func listWorkloads(clientSet kubernetes.Interface, namespace string) ([]*Workload, error) {
+	ctx := context.TODO()
+	var result []*Workload
+	replicasOf := func(replicas *int32) int32 {
+		if replicas == nil {
+			return 1
+		}
+		return *replicas
+	}
+
+	deployments, err := clientSet.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		result = append(result, &Workload{Kind: "Deployment", Namespace: d.Namespace, Name: d.Name, Replicas: replicasOf(d.Spec.Replicas),
+			Pod: workloads.PodFromTemplate(d.Namespace, d.Name, &d.Spec.Template)})
+	}
+	statefulSets, err := clientSet.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, s := range statefulSets.Items {
+		result = append(result, &Workload{Kind: "StatefulSet", Namespace: s.Namespace, Name: s.Name, Replicas: replicasOf(s.Spec.Replicas),
+			Pod: workloads.PodFromTemplate(s.Namespace, s.Name, &s.Spec.Template)})
+	}
+	daemonSets, err := clientSet.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for _, ds := range daemonSets.Items {
+		pod := workloads.PodFromTemplate(ds.Namespace, ds.Name, &ds.Spec.Template)
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, daemonsets.DaemonPodTolerations(pod)...)
+		result = append(result, &Workload{Kind: "DaemonSet", Namespace: ds.Namespace, Name: ds.Name, Replicas: ds.Status.DesiredNumberScheduled, Pod: pod})
+	}
+
+	runtimeClasses := make(map[string]*nodev1.RuntimeClass)
+	for _, w := range result {
+		name := w.Pod.Spec.RuntimeClassName
+		if name == nil || *name == "" {
+			continue
+		}
+		runtimeClass, ok := runtimeClasses[*name]
+		if !ok {
+			runtimeClass, err = clientSet.NodeV1().RuntimeClasses().Get(ctx, *name, metav1.GetOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get runtimeclass %s: %w", *name, err)
+			}
+			if err != nil {
+				runtimeClass = nil
+			}
+			runtimeClasses[*name] = runtimeClass
+		}
+		if runtimeClass == nil {
+			w.Error = fmt.Sprintf("runtimeclass %s not found", *name)
+			continue
+		}
+		if err := workloads.ApplyRuntimeClass(w.Pod, runtimeClass); err != nil {
+			w.Error = err.Error()
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].String() < result[j].String() })
+	return result, nil
+}
+
+func (f *FeasibilityReporter) GetFeasibility() error {
+	workloadList, err := listWorkloads(f.ClientSet, f.Namespace)
+	if err != nil {
+		return err
+	}
+	if len(workloadList) == 0 {
+		return fmt.Errorf("no deployment, statefulset or daemonset found")
+	}
+
+	nodes, err := framework.ListAllNodes(f.ClientSet, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	// the static checks do not look at running pods
+	snapshot := scheduler.NewSnapshot(f.ClientSet, nodes, nil)
+
+	pools := make(map[string]string, len(nodes))
+	poolSize := make(map[string]int)
+	for _, node := range nodes {
+		pool, ok := node.Labels[f.PoolLabel]
+		if !ok {
+			pool = framework.NoGroup
+		}
+		pools[node.Name] = pool
+		poolSize[pool]++
+	}
+	var poolNames []string
+	for pool := range poolSize {
+		poolNames = append(poolNames, pool)
+	}
+	sort.Strings(poolNames)
+
+	feasibilities := Evaluate(f.ClientSet, workloadList, snapshot, pools)
+	printMatrix(feasibilities, poolNames, poolSize)
+	printRisks(feasibilities)
+	return nil
+}
+
+func printMatrix(feasibilities []*WorkloadFeasibility, poolNames []string, poolSize map[string]int) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	header := []string{"workload", "replicas"}
+	for _, pool := range poolNames {
+		header = append(header, fmt.Sprintf("%s (%d)", pool, poolSize[pool]))
+	}
+	header = append(header, "eligible")
+	t.AppendHeader(util.ListToRow(header))
+
+	for _, wf := range feasibilities {
+		row := []string{wf.Workload.String(), fmt.Sprintf("%d", wf.Workload.Replicas)}
+		for _, pool := range poolNames {
+			if count := wf.Eligible[pool]; count > 0 {
+				row = append(row, fmt.Sprintf("%d", count))
+			} else {
+				row = append(row, "-")
+			}
+		}
+		eligible := util.NewGreenText(fmt.Sprintf("%d", wf.Total))
+		if wf.Risk() != "" {
+			eligible = util.NewRedText(eligible.Text)
+		}
+		row = append(row, eligible.String())
+		t.AppendRow(util.ListToRow(row))
+	}
+
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Render()
+}
+
+func printRisks(feasibilities []*WorkloadFeasibility) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"workload", "risk", "nodes ruled out by"})
+	for _, wf := range feasibilities {
+		if risk := wf.Risk(); risk != "" {
+			t.AppendRow(table.Row{wf.Workload.String(), util.NewRedText(risk).String(), wf.FailedSummary()})
+		}
+	}
+	if t.Length() == 0 {
+		fmt.Println("Every workload has at least as many eligible nodes as replicas")
+		return
+	}
+	fmt.Println("Workloads with too few eligible nodes:")
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
@@ -0,0 +1,86 @@
+package feasibility
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ops-tool/pkg/scheduler"
+)
+
+func TestEvaluate(t *testing.T) {
+	newNode := func(name, pool string, taints ...v1.Taint) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+			Spec:       v1.NodeSpec{Taints: taints},
+		}
+	}
+	gpuTaint := v1.Taint{Key: "gpu", Effect: v1.TaintEffectNoSchedule}
+	nodes := []v1.Node{*newNode("a1", "a"), *newNode("a2", "a"), *newNode("gpu1", "gpu", gpuTaint)}
+
+	replicas := int32(3)
+	newDeployment := func(name string, spec v1.PodSpec) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas, Template: v1.PodTemplateSpec{Spec: spec}},
+		}
+	}
+	kata := "kata"
+	clientSet := fake.NewSimpleClientset(
+		newDeployment("web", v1.PodSpec{}),
+		newDeployment("pinned", v1.PodSpec{NodeSelector: map[string]string{"pool": "a"}}),
+		newDeployment("sandboxed", v1.PodSpec{RuntimeClassName: &kata}),
+		newDeployment("stateful", v1.PodSpec{Volumes: []v1.Volume{{Name: "data", VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+		}}}}),
+		&v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"}},
+		&nodev1.RuntimeClass{ObjectMeta: metav1.ObjectMeta{Name: kata}, Scheduling: &nodev1.Scheduling{
+			NodeSelector: map[string]string{"pool": "gpu"},
+			Tolerations:  []v1.Toleration{{Key: "gpu", Operator: v1.TolerationOpExists}},
+		}},
+	)
+
+	workloadList, err := listWorkloads(clientSet, "")
+	if err != nil {
+		t.Fatalf("listWorkloads() error = %v", err)
+	}
+	pools := map[string]string{"a1": "a", "a2": "a", "gpu1": "gpu"}
+	feasibilities := Evaluate(clientSet, workloadList, scheduler.NewSnapshot(clientSet, nodes, nil), pools)
+	byName := map[string]*WorkloadFeasibility{}
+	for _, wf := range feasibilities {
+		byName[wf.Workload.String()] = wf
+	}
+	if len(byName) != 4 {
+		t.Fatalf("Evaluate() returned %d workloads, want 4", len(byName))
+	}
+
+	tests := []struct {
+		name   string
+		a, gpu int
+		risky  bool
+		failed string
+	}{
+		{name: "Deployment/default/web", a: 2, risky: true, failed: "Toleration(1)"},
+		{name: "Deployment/default/pinned", a: 2, risky: true, failed: "Toleration(1), nodeSelector(1)"},
+		{name: "Deployment/default/sandboxed", gpu: 1, risky: true, failed: "nodeSelector(2)"},
+		{name: "Deployment/default/stateful", a: 2, risky: true, failed: "Toleration(1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wf, ok := byName[tt.name]
+			if !ok {
+				t.Fatalf("Evaluate() has no %s", tt.name)
+			}
+			if wf.Eligible["a"] != tt.a || wf.Eligible["gpu"] != tt.gpu || (wf.Risk() != "") != tt.risky {
+				t.Errorf("%s: eligible %v, risk %q, want %+v", wf.Workload, wf.Eligible, wf.Risk(), tt)
+			}
+			if summary := wf.FailedSummary(); summary != tt.failed {
+				t.Errorf("FailedSummary() of %s = %q, want %q", wf.Workload, summary, tt.failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package namespaces
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/ops-tool/pkg/pods"
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+)
+
+type NamespaceResourceReporter struct {
+	ClientSet    kubernetes.Interface
+	MetricClient *metrics.Clientset
+
+	Namespace string
+	// Threshold is the quota usage percentage from which a namespace is highlighted.
+	Threshold float64
+}
+
+type QuotaUsage struct {
+	Source   string
+	Resource v1.ResourceName
+	Used     resource.Quantity
+	Hard     resource.Quantity
+}
+
+func (q *QuotaUsage) Fraction() float64 {
+	if q.Hard.IsZero() {
+		if q.Used.IsZero() {
+			return 0
+		}
+		return 100
+	}
+	return float64(q.Used.MilliValue()) / float64(q.Hard.MilliValue()) * 100
+}
+
+type NamespaceResource struct {
+	Name      string
+	Pods      int
+	Requests  map[v1.ResourceName]resource.Quantity
+	Limits    map[v1.ResourceName]resource.Quantity
+	CPUUsage  *resource.Quantity
+	MemUsage  *resource.Quantity
+	CPUShare  float64
+	MemShare  float64
+	Quotas    []*QuotaUsage
+	NearQuota bool
+}
+
+// quotaTexts renders the quota usages, red from threshold percent on.
+func (nr *NamespaceResource) quotaTexts(threshold float64) util.ColorTextList {
+	var quotas util.ColorTextList
+	for _, q := range nr.Quotas {
+		line := fmt.Sprintf("%s %s: %s/%s(%d%%)", strings.TrimPrefix(q.Source, "ResourceQuota/"), q.Resource, q.Used.String(), q.Hard.String(), int64(q.Fraction()))
+		if q.Fraction() >= threshold {
+			quotas = append(quotas, util.NewRedText(line))
+		} else {
+			quotas = append(quotas, util.ColorText{Text: line})
+		}
+	}
+	return quotas
+}
+
+func (nr *NamespaceResource) ToStringList(threshold float64) []string {
+	cpuRequest, cpuLimit := nr.Requests[v1.ResourceCPU], nr.Limits[v1.ResourceCPU]
+	memRequest, memLimit := nr.Requests[v1.ResourceMemory], nr.Limits[v1.ResourceMemory]
+	cpuUsage, memUsage := "-", "-"
+	if nr.CPUUsage != nil {
+		cpuUsage = fmt.Sprintf("%d", nr.CPUUsage.MilliValue())
+	}
+	if nr.MemUsage != nil {
+		memUsage = fmt.Sprintf("%d", nr.MemUsage.Value()/(1024*1024))
+	}
+
+	quotas := nr.quotaTexts(threshold)
+	quota := "-"
+	if len(quotas) > 0 {
+		quota = strings.TrimSuffix(quotas.String(), "\n")
+	}
+
+	name := nr.Name
+	if nr.NearQuota {
+		name = util.NewRedText(name).String()
+	}
+	return []string{
+		name,
+		fmt.Sprintf("%d", nr.Pods),
+		fmt.Sprintf("%d", cpuRequest.MilliValue()),
+		fmt.Sprintf("%d", cpuLimit.MilliValue()),
+		cpuUsage,
+		fmt.Sprintf("%.1f%%", nr.CPUShare),
+		fmt.Sprintf("%d", memRequest.Value()/(1024*1024)),
+		fmt.Sprintf("%d", memLimit.Value()/(1024*1024)),
+		memUsage,
+		fmt.Sprintf("%.1f%%", nr.MemShare),
+		quota,
+	}
+}
+
+func (n *NamespaceResourceReporter) GetNamespaceResource() error {
+	namespaces, err := n.namespaces()
+	if err != nil {
+		return err
+	}
+
+	podList, err := n.ClientSet.CoreV1().Pods(n.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	podsByNamespace := map[string]*v1.PodList{}
+	for _, pod := range podList.Items {
+		if workloads.IsTerminated(&pod) {
+			continue
+		}
+		if _, ok := podsByNamespace[pod.Namespace]; !ok {
+			podsByNamespace[pod.Namespace] = &v1.PodList{}
+		}
+		podsByNamespace[pod.Namespace].Items = append(podsByNamespace[pod.Namespace].Items, pod)
+	}
+
+	quotaList, err := n.ClientSet.CoreV1().ResourceQuotas(n.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list resourcequotas: %w", err)
+	}
+
+	allocatable, err := n.clusterAllocatable()
+	if err != nil {
+		return err
+	}
+
+	var usage pods.PodMetricMap
+	podMetricsList, err := n.MetricClient.MetricsV1beta1().PodMetricses(n.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("Error getting pod metrics, usage is not shown: %v\n", err)
+	} else {
+		usage = pods.BuildPodMetricMap(podMetricsList)
+	}
+
+	result := BuildNamespaceResources(namespaces, podsByNamespace, quotaList.Items, allocatable, usage, n.Threshold)
+	n.print(result)
+	return nil
+}
+
+// BuildNamespaceResources sums the requests, limits and usage of the pods per namespace and
+// marks the namespaces having a quota used from threshold percent on, biggest cpu requests first.
+func BuildNamespaceResources(namespaces []string, podsByNamespace map[string]*v1.PodList, quotas []v1.ResourceQuota,
+	allocatable v1.ResourceList, usage pods.PodMetricMap, threshold float64) []*NamespaceResource {
+	var result []*NamespaceResource
+	for _, namespace := range namespaces {
+		nr := &NamespaceResource{Name: namespace}
+		nsPods := podsByNamespace[namespace]
+		if nsPods == nil {
+			nsPods = &v1.PodList{}
+		}
+		nr.Pods = len(nsPods.Items)
+		nr.Requests, nr.Limits = framework.GetPodsTotalRequestsAndLimits(nsPods)
+
+		if usage != nil {
+			cpu, mem := resource.NewQuantity(0, resource.DecimalSI), resource.NewQuantity(0, resource.BinarySI)
+			for _, podUsage := range usage[namespace] {
+				cpu.Add(podUsage.CPUUsage)
+				mem.Add(podUsage.MemUsage)
+			}
+			nr.CPUUsage, nr.MemUsage = cpu, mem
+		}
+
+		nr.CPUShare = share(nr.Requests[v1.ResourceCPU], allocatable[v1.ResourceCPU])
+		nr.MemShare = share(nr.Requests[v1.ResourceMemory], allocatable[v1.ResourceMemory])
+
+		for _, quota := range quotas {
+			if quota.Namespace != namespace {
+				continue
+			}
+			for name, hard := range quota.Status.Hard {
+				q := &QuotaUsage{Source: "ResourceQuota/" + quota.Name, Resource: name, Used: quota.Status.Used[name], Hard: hard}
+				nr.Quotas = append(nr.Quotas, q)
+				if q.Fraction() >= threshold {
+					nr.NearQuota = true
+				}
+			}
+		}
+		sort.Slice(nr.Quotas, func(i, j int) bool {
+			if nr.Quotas[i].Source != nr.Quotas[j].Source {
+				return nr.Quotas[i].Source < nr.Quotas[j].Source
+			}
+			return nr.Quotas[i].Resource < nr.Quotas[j].Resource
+		})
+		result = append(result, nr)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i].Requests[v1.ResourceCPU], result[j].Requests[v1.ResourceCPU]
+		return a.Cmp(b) > 0
+	})
+	return result
+}
+
+func (n *NamespaceResourceReporter) namespaces() ([]string, error) {
+	if n.Namespace != "" {
+		return []string{n.Namespace}, nil
+	}
+	list, err := n.ClientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	var result []string
+	for _, ns := range list.Items {
+		result = append(result, ns.Name)
+	}
+	return result, nil
+}
+
+// clusterAllocatable sums the allocatable of all nodes.
+func (n *NamespaceResourceReporter) clusterAllocatable() (v1.ResourceList, error) {
+	nodes, err := n.ClientSet.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	result := v1.ResourceList{}
+	for _, node := range nodes.Items {
+		for name, quantity := range node.Status.Allocatable {
+			total := result[name]
+			total.Add(quantity)
+			result[name] = total
+		}
+	}
+	return result, nil
+}
+
+func share(part, total resource.Quantity) float64 {
+	if total.IsZero() {
+		return 0
+	}
+	return float64(part.MilliValue()) / float64(total.MilliValue()) * 100
+}
+
+func (n *NamespaceResourceReporter) print(result []*NamespaceResource) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Namespace", "Pods", "CPURequest(m)", "CPULimit(m)", "CPUUsage(m)", "CPUShare",
+		"MemRequest(Mi)", "MemLimit(Mi)", "MemUsage(Mi)", "MemShare", "Quota(used/hard)"})
+	for _, nr := range result {
+		t.AppendRow(util.ListToRow(nr.ToStringList(n.Threshold)))
+	}
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
@@ -0,0 +1,124 @@
+package namespaces
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/color"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBuildNamespaceResources(t *testing.T) {
+	reporter := &NamespaceResourceReporter{ClientSet: fake.NewSimpleClientset(newTestNode("node1", "8", "16Gi"), newTestNode("node2", "8", "16Gi"))}
+	allocatable, err := reporter.clusterAllocatable()
+	if err != nil {
+		t.Fatalf("clusterAllocatable() error = %v", err)
+	}
+	podsByNamespace := map[string]*v1.PodList{
+		"web":   {Items: []v1.Pod{newTestPod("web", "web-1", "2", "4Gi"), newTestPod("web", "web-2", "2", "4Gi")}},
+		"batch": {Items: []v1.Pod{newTestPod("batch", "job-1", "1", "2Gi")}},
+	}
+	quotas := []v1.ResourceQuota{
+		newTestQuota("web", "compute", v1.ResourceRequestsCPU, "4", "5"),
+		newTestQuota("web", "objects", v1.ResourcePods, "2", "10"),
+		newTestQuota("batch", "compute", v1.ResourceRequestsCPU, "1", "4"),
+		newTestQuota("idle", "compute", v1.ResourceRequestsCPU, "0", "0"),
+	}
+
+	type quota struct {
+		Text string
+		Red  bool
+	}
+	type want struct {
+		Name      string
+		Pods      int
+		CPUShare  float64
+		NearQuota bool
+		Quotas    []quota
+	}
+	type args struct {
+		threshold float64
+	}
+	tests := []struct {
+		name string
+		args args
+		want []want
+	}{
+		{
+			name: "threshold 80",
+			args: args{threshold: 80},
+			want: []want{
+				{Name: "web", Pods: 2, CPUShare: 25, NearQuota: true, Quotas: []quota{
+					{Text: "compute requests.cpu: 4/5(80%)", Red: true},
+					{Text: "objects pods: 2/10(20%)"},
+				}},
+				{Name: "batch", Pods: 1, CPUShare: 6.25, Quotas: []quota{{Text: "compute requests.cpu: 1/4(25%)"}}},
+				{Name: "idle", Quotas: []quota{{Text: "compute requests.cpu: 0/0(0%)"}}},
+			},
+		},
+		{
+			name: "threshold 20",
+			args: args{threshold: 20},
+			want: []want{
+				{Name: "web", Pods: 2, CPUShare: 25, NearQuota: true, Quotas: []quota{
+					{Text: "compute requests.cpu: 4/5(80%)", Red: true},
+					{Text: "objects pods: 2/10(20%)", Red: true},
+				}},
+				{Name: "batch", Pods: 1, CPUShare: 6.25, NearQuota: true, Quotas: []quota{{Text: "compute requests.cpu: 1/4(25%)", Red: true}}},
+				{Name: "idle", Quotas: []quota{{Text: "compute requests.cpu: 0/0(0%)"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []want
+			for _, nr := range BuildNamespaceResources([]string{"batch", "idle", "web"}, podsByNamespace, quotas, allocatable, nil, tt.args.threshold) {
+				w := want{Name: nr.Name, Pods: nr.Pods, CPUShare: nr.CPUShare, NearQuota: nr.NearQuota}
+				for _, ct := range nr.quotaTexts(tt.args.threshold) {
+					w.Quotas = append(w.Quotas, quota{Text: ct.Text, Red: ct.Color == color.FgRed})
+				}
+				got = append(got, w)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildNamespaceResources() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestPod(namespace, name, cpu, memory string) v1.Pod {
+	requests := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "main", Resources: v1.ResourceRequirements{Requests: requests, Limits: requests}}}},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func newTestNode(name, cpu, memory string) *v1.Node {
+	allocatable := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1.NodeStatus{Capacity: allocatable, Allocatable: allocatable},
+	}
+}
+
+// newTestQuota returns a quota whose status uses used of hard for the resource.
+func newTestQuota(namespace, name string, resourceName v1.ResourceName, used, hard string) v1.ResourceQuota {
+	return v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Status: v1.ResourceQuotaStatus{
+			Hard: v1.ResourceList{resourceName: resource.MustParse(hard)},
+			Used: v1.ResourceList{resourceName: resource.MustParse(used)},
+		},
+	}
+}
@@ -0,0 +1,229 @@
+package nodes
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+)
+
+type ExtendedResourceReporter struct {
+	ClientSet *kubernetes.Clientset
+
+	// Resource is the extended resource to report, every extended resource when empty.
+	Resource string
+	Node     string
+}
+
+// ResourceHolder is a container requesting units of an extended resource.
+type ResourceHolder struct {
+	Pod       *v1.Pod
+	Container string
+	Init      bool
+	Units     int64
+}
+
+// NodeExtendedResource is an extended resource of a node and the containers holding it.
+type NodeExtendedResource struct {
+	NodeName    string
+	Resource    string
+	Capacity    int64
+	Allocatable int64
+	// Allocated is what the scheduler accounts, terminated pods excluded.
+	Allocated int64
+	Holders   []*ResourceHolder
+}
+
+// Problems returns why the allocation of the node is inconsistent, nil when it is not.
+func (ner *NodeExtendedResource) Problems() []string {
+	var problems []string
+	if ner.Allocated > ner.Capacity {
+		problems = append(problems, fmt.Sprintf("capacity %d dropped below allocation %d", ner.Capacity, ner.Allocated))
+	} else if ner.Allocated > ner.Allocatable {
+		problems = append(problems, fmt.Sprintf("allocatable %d dropped below allocation %d", ner.Allocatable, ner.Allocated))
+	}
+	for _, holder := range ner.Holders {
+		if workloads.IsTerminated(holder.Pod) {
+			problems = append(problems, fmt.Sprintf("%s/%s is %s but still bound to the node", holder.Pod.Namespace, holder.Pod.Name, holder.Pod.Status.Phase))
+		}
+	}
+	return problems
+}
+
+// BuildExtendedResourceHolders returns the holders of the resource per node, nodes that neither
+// advertise nor run a pod requesting the resource are left out.
+func BuildExtendedResourceHolders(nodes []v1.Node, pods []v1.Pod, resourceName v1.ResourceName) []*NodeExtendedResource {
+	podsByNode := framework.IndexPodsByNode(pods)
+
+	var result []*NodeExtendedResource
+	for i := range nodes {
+		node := &nodes[i]
+		capacity := node.Status.Capacity[resourceName]
+		allocatable := node.Status.Allocatable[resourceName]
+		ner := &NodeExtendedResource{
+			NodeName:    node.Name,
+			Resource:    resourceName.String(),
+			Capacity:    capacity.Value(),
+			Allocatable: allocatable.Value(),
+		}
+
+		for j := range podsByNode[node.Name] {
+			pod := &podsByNode[node.Name][j]
+			holders := containerHolders(pod, resourceName)
+			if len(holders) == 0 {
+				continue
+			}
+			ner.Holders = append(ner.Holders, holders...)
+			if !workloads.IsTerminated(pod) {
+				requests, _ := resourcehelper.PodRequestsAndLimits(pod)
+				request := requests[resourceName]
+				ner.Allocated += request.Value()
+			}
+		}
+		if ner.Capacity == 0 && len(ner.Holders) == 0 {
+			continue
+		}
+		result = append(result, ner)
+	}
+	return result
+}
+
+func containerHolders(pod *v1.Pod, resourceName v1.ResourceName) []*ResourceHolder {
+	var holders []*ResourceHolder
+	add := func(containers []v1.Container, init bool) {
+		for _, container := range containers {
+			request, ok := container.Resources.Requests[resourceName]
+			if !ok {
+				// extended resources default their request to the limit
+				request = container.Resources.Limits[resourceName]
+			}
+			if request.Value() > 0 {
+				holders = append(holders, &ResourceHolder{Pod: pod, Container: container.Name, Init: init, Units: request.Value()})
+			}
+		}
+	}
+	add(pod.Spec.InitContainers, true)
+	add(pod.Spec.Containers, false)
+	return holders
+}
+
+// extendedResourceNames returns the extended resources advertised by the nodes or requested
+// by the pods.
+func extendedResourceNames(nodes []v1.Node, pods []v1.Pod) []v1.ResourceName {
+	seen := make(map[v1.ResourceName]bool)
+	for _, node := range nodes {
+		for name := range node.Status.Capacity {
+			if framework.IsExtendedResource(name) {
+				seen[name] = true
+			}
+		}
+	}
+	for _, pod := range pods {
+		requests, _ := resourcehelper.PodRequestsAndLimits(&pod)
+		for name := range requests {
+			if framework.IsExtendedResource(name) {
+				seen[name] = true
+			}
+		}
+	}
+	var names []v1.ResourceName
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+func (e *ExtendedResourceReporter) GetExtendedResourceHolders() error {
+	nodeOptions, podOptions := metav1.ListOptions{}, metav1.ListOptions{}
+	if e.Node != "" {
+		nodeOptions.FieldSelector = fmt.Sprintf("metadata.name=%s", e.Node)
+		podOptions.FieldSelector = fmt.Sprintf("spec.nodeName=%s", e.Node)
+	}
+	nodes, err := framework.ListAllNodes(e.ClientSet, nodeOptions)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if e.Node != "" && len(nodes) == 0 {
+		return fmt.Errorf("node %s not found", e.Node)
+	}
+	pods, err := framework.ListAllPods(e.ClientSet, v1.NamespaceAll, podOptions)
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	resourceNames := []v1.ResourceName{v1.ResourceName(e.Resource)}
+	if e.Resource == "" {
+		resourceNames = extendedResourceNames(nodes, pods)
+	}
+	if len(resourceNames) == 0 {
+		fmt.Println("No extended resource found")
+		return nil
+	}
+
+	var nodeResources []*NodeExtendedResource
+	for _, name := range resourceNames {
+		nodeResources = append(nodeResources, BuildExtendedResourceHolders(nodes, pods, name)...)
+	}
+	if len(nodeResources) == 0 {
+		fmt.Printf("No node advertises %s and no pod requests it\n", e.Resource)
+		return nil
+	}
+	printExtendedResourceHolders(nodeResources)
+	return nil
+}
+
+func printExtendedResourceHolders(nodeResources []*NodeExtendedResource) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"nodeName", "resource", "capacity", "allocatable", "allocated", "pod", "container", "units", "owner", "age", "status"})
+
+	for _, ner := range nodeResources {
+		allocated := util.NewGreenText(fmt.Sprintf("%d", ner.Allocated))
+		if ner.Allocated > ner.Allocatable {
+			allocated = util.NewRedText(allocated.Text)
+		}
+		nodeCells := table.Row{ner.NodeName, ner.Resource, ner.Capacity, ner.Allocatable, allocated.String()}
+		if len(ner.Holders) == 0 {
+			t.AppendRow(append(nodeCells, "-", "-", "-", "-", "-", "-"))
+		}
+		for i, holder := range ner.Holders {
+			if i > 0 {
+				nodeCells = table.Row{"", "", "", "", ""}
+			}
+			container := holder.Container
+			if holder.Init {
+				container += " (init)"
+			}
+			status := util.ColorText{Text: string(holder.Pod.Status.Phase)}
+			switch {
+			case workloads.IsTerminated(holder.Pod):
+				status = util.NewRedText(fmt.Sprintf("%s but still bound", holder.Pod.Status.Phase))
+			case holder.Pod.DeletionTimestamp != nil:
+				status = util.NewRedText("Terminating")
+			}
+			t.AppendRow(append(nodeCells, fmt.Sprintf("%s/%s", holder.Pod.Namespace, holder.Pod.Name), container, holder.Units,
+				workloads.OwnerString(holder.Pod), duration.HumanDuration(metav1.Now().Sub(holder.Pod.CreationTimestamp.Time)), status.String()))
+		}
+		if problems := ner.Problems(); len(problems) > 0 {
+			t.AppendRow(table.Row{"", "", "", "", "", util.StringListToColorTextList(problems, "red").String()})
+		}
+	}
+
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
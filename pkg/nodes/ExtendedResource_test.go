@@ -0,0 +1,74 @@
+package nodes
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestBuildExtendedResourceHolders(t *testing.T) {
+	nodes := []v1.Node{
+		newTestGPUNode("node1", "2", "2"),
+		newTestGPUNode("node2", "", ""),
+		newTestGPUNode("node3", "4", "2"),
+		newTestGPUNode("node4", "1", "1"),
+	}
+	pods := []v1.Pod{
+		newTestGPUPod("train-1", "node1", v1.PodRunning, "", "2"),
+		newTestGPUPod("train-2", "node1", v1.PodRunning, "", "0", "1"),
+		newTestGPUPod("done", "node1", v1.PodSucceeded, "", "1"),
+		newTestGPUPod("web", "node2", v1.PodRunning, ""),
+		newTestGPUPod("infer", "node3", v1.PodRunning, "3", "1", "1"),
+	}
+	type holder struct {
+		Pod       string
+		Container string
+		Init      bool
+		Units     int64
+	}
+	type want struct {
+		NodeName    string
+		Capacity    int64
+		Allocatable int64
+		Allocated   int64
+		Holders     []holder
+		Problems    []string
+	}
+	tests := []struct {
+		name string
+		want []want
+	}{
+		{
+			name: "holders per node",
+			want: []want{
+				{
+					NodeName: "node1", Capacity: 2, Allocatable: 2, Allocated: 3,
+					Holders:  []holder{{Pod: "train-1", Container: "a", Units: 2}, {Pod: "train-2", Container: "b", Units: 1}, {Pod: "done", Container: "a", Units: 1}},
+					Problems: []string{"capacity 2 dropped below allocation 3", "default/done is Succeeded but still bound to the node"},
+				},
+				{
+					NodeName: "node3", Capacity: 4, Allocatable: 2, Allocated: 3,
+					Holders:  []holder{{Pod: "infer", Container: "init", Init: true, Units: 3}, {Pod: "infer", Container: "a", Units: 1}, {Pod: "infer", Container: "b", Units: 1}},
+					Problems: []string{"allocatable 2 dropped below allocation 3"},
+				},
+				{NodeName: "node4", Capacity: 1, Allocatable: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []want
+			for _, ner := range BuildExtendedResourceHolders(nodes, pods, testGPU) {
+				w := want{NodeName: ner.NodeName, Capacity: ner.Capacity, Allocatable: ner.Allocatable, Allocated: ner.Allocated, Problems: ner.Problems()}
+				for _, h := range ner.Holders {
+					w.Holders = append(w.Holders, holder{Pod: h.Pod.Name, Container: h.Container, Init: h.Init, Units: h.Units})
+				}
+				got = append(got, w)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildExtendedResourceHolders() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"github.com/ops-tool/pkg/util"
+)
+
+const DefaultPoolLabel = "node.kubernetes.io/instance-type"
+
+// perNodeLabels always differ between nodes, they are only shown when asked for.
+var perNodeLabels = map[string]bool{v1.LabelHostname: true}
+
+type LabelMatrixReporter struct {
+	ClientSet *kubernetes.Clientset
+
+	Selector string
+	// Keys are the label keys shown, every key starting with Prefix when empty.
+	Keys   []string
+	Prefix string
+	// PoolLabel groups the nodes into pools, drift is looked for within a pool.
+	PoolLabel string
+}
+
+// LabelFinding is a label key not consistent within a pool.
+type LabelFinding struct {
+	Pool    string
+	Key     string
+	Message string
+}
+
+type LabelMatrix struct {
+	Keys     []string
+	Nodes    []*v1.Node
+	Pools    map[string]string
+	Findings []*LabelFinding
+	// inconsistent marks the pool/key pairs with a finding.
+	inconsistent map[[2]string]bool
+}
+
+// BuildLabelMatrix selects the label keys and looks for keys present on only some nodes of a
+// pool and for values differing within a pool.
+func BuildLabelMatrix(nodes []v1.Node, keys []string, prefix, poolLabel string) *LabelMatrix {
+	matrix := &LabelMatrix{Keys: keys, Pools: make(map[string]string), inconsistent: make(map[[2]string]bool)}
+	for i := range nodes {
+		matrix.Nodes = append(matrix.Nodes, &nodes[i])
+		pool, ok := nodes[i].Labels[poolLabel]
+		if !ok {
+			pool = framework.NoGroup
+		}
+		matrix.Pools[nodes[i].Name] = pool
+	}
+	sort.Slice(matrix.Nodes, func(i, j int) bool {
+		pi, pj := matrix.Pools[matrix.Nodes[i].Name], matrix.Pools[matrix.Nodes[j].Name]
+		if pi != pj {
+			return pi < pj
+		}
+		return matrix.Nodes[i].Name < matrix.Nodes[j].Name
+	})
+
+	if len(matrix.Keys) == 0 {
+		seen := make(map[string]bool)
+		for _, node := range matrix.Nodes {
+			for key := range node.Labels {
+				if strings.HasPrefix(key, prefix) && !perNodeLabels[key] && key != poolLabel && !seen[key] {
+					seen[key] = true
+					matrix.Keys = append(matrix.Keys, key)
+				}
+			}
+		}
+		sort.Strings(matrix.Keys)
+	}
+
+	poolNodes := make(map[string][]*v1.Node)
+	var pools []string
+	for _, node := range matrix.Nodes {
+		pool := matrix.Pools[node.Name]
+		if _, ok := poolNodes[pool]; !ok {
+			pools = append(pools, pool)
+		}
+		poolNodes[pool] = append(poolNodes[pool], node)
+	}
+	for _, pool := range pools {
+		for _, key := range matrix.Keys {
+			values := make(map[string]int)
+			var missing []string
+			for _, node := range poolNodes[pool] {
+				if value, ok := node.Labels[key]; ok {
+					values[value]++
+				} else {
+					missing = append(missing, node.Name)
+				}
+			}
+			if len(values) == 0 {
+				continue
+			}
+			if len(missing) > 0 {
+				matrix.add(pool, key, fmt.Sprintf("missing on %d of %d nodes: %s", len(missing), len(poolNodes[pool]), strings.Join(missing, ",")))
+			}
+			if len(values) > 1 {
+				var counted []string
+				for value, count := range values {
+					counted = append(counted, fmt.Sprintf("%s(%d)", value, count))
+				}
+				sort.Strings(counted)
+				matrix.add(pool, key, fmt.Sprintf("values differ: %s", strings.Join(counted, ", ")))
+			}
+		}
+	}
+	return matrix
+}
+
+func (m *LabelMatrix) add(pool, key, message string) {
+	m.Findings = append(m.Findings, &LabelFinding{Pool: pool, Key: key, Message: message})
+	m.inconsistent[[2]string{pool, key}] = true
+}
+
+// UntoleratedTaints returns the taints no toleration set tolerates. Catch-all tolerations, an
+// empty key with operator Exists, are ignored: node agents commonly carry one and would
+// otherwise hide every taint.
+func UntoleratedTaints(taints []v1.Taint, tolerationSets [][]v1.Toleration) []v1.Taint {
+	var result []v1.Taint
+	for i := range taints {
+		tolerated := false
+		for _, tolerations := range tolerationSets {
+			for _, toleration := range tolerations {
+				if isCatchAllToleration(&toleration) {
+					continue
+				}
+				if toleration.ToleratesTaint(&taints[i]) {
+					tolerated = true
+					break
+				}
+			}
+			if tolerated {
+				break
+			}
+		}
+		if !tolerated {
+			result = append(result, taints[i])
+		}
+	}
+	return result
+}
+
+func isCatchAllToleration(toleration *v1.Toleration) bool {
+	return toleration.Key == "" && toleration.Operator == v1.TolerationOpExists
+}
+
+// workloadTolerations returns the tolerations of the pods and of the workload templates, so
+// that a workload scaled to zero still counts.
+func (l *LabelMatrixReporter) workloadTolerations() ([][]v1.Toleration, error) {
+	var result [][]v1.Toleration
+	pods, err := framework.ListAllPods(l.ClientSet, v1.NamespaceAll, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, pod := range pods {
+		result = append(result, pod.Spec.Tolerations)
+	}
+
+	ctx := context.TODO()
+	deployments, err := l.ClientSet.AppsV1().Deployments(v1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, deployment := range deployments.Items {
+		result = append(result, deployment.Spec.Template.Spec.Tolerations)
+	}
+	statefulSets, err := l.ClientSet.AppsV1().StatefulSets(v1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, statefulSet := range statefulSets.Items {
+		result = append(result, statefulSet.Spec.Template.Spec.Tolerations)
+	}
+	daemonSets, err := l.ClientSet.AppsV1().DaemonSets(v1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for _, daemonSet := range daemonSets.Items {
+		result = append(result, daemonSet.Spec.Template.Spec.Tolerations)
+	}
+	cronJobs, err := l.ClientSet.BatchV1().CronJobs(v1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for _, cronJob := range cronJobs.Items {
+		result = append(result, cronJob.Spec.JobTemplate.Spec.Template.Spec.Tolerations)
+	}
+	return result, nil
+}
+
+func (l *LabelMatrixReporter) GetLabelMatrix() error {
+	nodes, err := framework.ListAllNodes(l.ClientSet, metav1.ListOptions{LabelSelector: l.Selector})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	tolerationSets, err := l.workloadTolerations()
+	if err != nil {
+		return err
+	}
+
+	matrix := BuildLabelMatrix(nodes, l.Keys, l.Prefix, l.PoolLabel)
+	matrix.Print(l.PoolLabel, tolerationSets)
+	return nil
+}
+
+// Print prints the nodes × keys matrix, missing and differing labels are yellow, taints no
+// workload tolerates are red, followed by the findings per pool.
+func (m *LabelMatrix) Print(poolLabel string, tolerationSets [][]v1.Toleration) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	header := []string{"nodeName", poolLabel}
+	header = append(header, m.Keys...)
+	header = append(header, "taints")
+	t.AppendHeader(util.ListToRow(header))
+
+	for _, node := range m.Nodes {
+		pool := m.Pools[node.Name]
+		row := []string{node.Name, pool}
+		for _, key := range m.Keys {
+			cell := util.ColorText{Text: "-"}
+			if value, ok := node.Labels[key]; ok {
+				cell.Text = value
+			}
+			if m.inconsistent[[2]string{pool, key}] {
+				cell = util.NewYellowText(cell.Text)
+			}
+			row = append(row, cell.String())
+		}
+
+		untolerated := make(map[string]bool)
+		for _, taint := range UntoleratedTaints(node.Spec.Taints, tolerationSets) {
+			untolerated[taint.ToString()] = true
+		}
+		var taints util.ColorTextList
+		for _, taint := range node.Spec.Taints {
+			if untolerated[taint.ToString()] {
+				taints = append(taints, util.NewRedText(taint.ToString()+" (no workload tolerates)"))
+			} else {
+				taints = append(taints, util.ColorText{Text: taint.ToString()})
+			}
+		}
+		if len(taints) == 0 {
+			taints = util.ColorTextList{{Text: "-"}}
+		}
+		row = append(row, taints.String())
+		t.AppendRow(util.ListToRow(row))
+	}
+
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+
+	if len(m.Findings) == 0 {
+		fmt.Println("Labels are consistent within every pool")
+		return
+	}
+	findings := table.NewWriter()
+	findings.SetOutputMirror(os.Stdout)
+	findings.AppendHeader(table.Row{poolLabel, "key", "finding"})
+	for _, finding := range m.Findings {
+		findings.AppendRow(table.Row{finding.Pool, finding.Key, util.NewYellowText(finding.Message).String()})
+	}
+	findings.SetStyle(style)
+	findings.Style().Options.SeparateRows = true
+	findings.Render()
+}
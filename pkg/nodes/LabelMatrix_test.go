@@ -0,0 +1,136 @@
+package nodes
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestBuildLabelMatrix(t *testing.T) {
+	const poolLabel = DefaultPoolLabel
+	nodes := []v1.Node{
+		newTestNode("node2", map[string]string{poolLabel: "m5", "gpu": "false", "disk": "ssd"}),
+		newTestNode("node1", map[string]string{poolLabel: "m5", "gpu": "false", "disk": "hdd", v1.LabelHostname: "node1"}),
+		newTestNode("node3", map[string]string{poolLabel: "m5", "disk": "ssd"}),
+		newTestNode("node4", map[string]string{poolLabel: "c5", "gpu": "true"}),
+		newTestNode("node5", map[string]string{"gpu": "true"}),
+	}
+	type args struct {
+		keys   []string
+		prefix string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantNodes []string
+		wantKeys  []string
+		want      []LabelFinding
+	}{
+		{
+			name:      "every key",
+			args:      args{},
+			wantNodes: []string{"node5", "node4", "node1", "node2", "node3"},
+			wantKeys:  []string{"disk", "gpu"},
+			want: []LabelFinding{
+				{Pool: "m5", Key: "disk", Message: "values differ: hdd(1), ssd(2)"},
+				{Pool: "m5", Key: "gpu", Message: "missing on 1 of 3 nodes: node3"},
+			},
+		},
+		{
+			name:      "given keys",
+			args:      args{keys: []string{"gpu"}},
+			wantNodes: []string{"node5", "node4", "node1", "node2", "node3"},
+			wantKeys:  []string{"gpu"},
+			want: []LabelFinding{
+				{Pool: "m5", Key: "gpu", Message: "missing on 1 of 3 nodes: node3"},
+			},
+		},
+		{
+			name:      "prefix",
+			args:      args{prefix: "d"},
+			wantNodes: []string{"node5", "node4", "node1", "node2", "node3"},
+			wantKeys:  []string{"disk"},
+			want: []LabelFinding{
+				{Pool: "m5", Key: "disk", Message: "values differ: hdd(1), ssd(2)"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildLabelMatrix(nodes, tt.args.keys, tt.args.prefix, poolLabel)
+			var gotNodes []string
+			for _, node := range got.Nodes {
+				gotNodes = append(gotNodes, node.Name)
+			}
+			if !reflect.DeepEqual(gotNodes, tt.wantNodes) {
+				t.Errorf("BuildLabelMatrix() nodes = %v, want %v", gotNodes, tt.wantNodes)
+			}
+			if !reflect.DeepEqual(got.Keys, tt.wantKeys) {
+				t.Errorf("BuildLabelMatrix() keys = %v, want %v", got.Keys, tt.wantKeys)
+			}
+			var findings []LabelFinding
+			for _, finding := range got.Findings {
+				findings = append(findings, *finding)
+			}
+			if !reflect.DeepEqual(findings, tt.want) {
+				t.Errorf("BuildLabelMatrix() findings = %v, want %v", findings, tt.want)
+			}
+		})
+	}
+}
+
+func TestUntoleratedTaints(t *testing.T) {
+	taints := []v1.Taint{
+		{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+		{Key: "spot", Effect: v1.TaintEffectNoExecute},
+	}
+	type args struct {
+		tolerationSets [][]v1.Toleration
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "no toleration",
+			args: args{},
+			want: []string{"dedicated=gpu:NoSchedule", "spot:NoExecute"},
+		},
+		{
+			name: "tolerated by key",
+			args: args{tolerationSets: [][]v1.Toleration{
+				{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "gpu", Effect: v1.TaintEffectNoSchedule}},
+			}},
+			want: []string{"spot:NoExecute"},
+		},
+		{
+			name: "other value",
+			args: args{tolerationSets: [][]v1.Toleration{
+				{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "db"}},
+				{{Key: "spot", Operator: v1.TolerationOpExists}},
+			}},
+			want: []string{"dedicated=gpu:NoSchedule"},
+		},
+		{
+			name: "catch-all toleration ignored",
+			args: args{tolerationSets: [][]v1.Toleration{
+				{{Operator: v1.TolerationOpExists}},
+				{{Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}},
+			}},
+			want: []string{"dedicated=gpu:NoSchedule", "spot:NoExecute"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, taint := range UntoleratedTaints(taints, tt.args.tolerationSets) {
+				got = append(got, taint.ToString())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UntoleratedTaints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
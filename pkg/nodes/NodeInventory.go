@@ -0,0 +1,205 @@
+package nodes
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"github.com/ops-tool/pkg/util"
+)
+
+const (
+	OutputTable    = "table"
+	OutputCSV      = "csv"
+	OutputMarkdown = "markdown"
+	OutputJSON     = "json"
+)
+
+var Outputs = []string{OutputTable, OutputCSV, OutputMarkdown, OutputJSON}
+
+var DefaultInventoryLabels = []string{"node.kubernetes.io/instance-type", "topology.kubernetes.io/zone"}
+
+type NodeInventoryReporter struct {
+	ClientSet *kubernetes.Clientset
+
+	// Selector is a label selector restricting the nodes.
+	Selector string
+	// Labels are the node labels added as columns.
+	Labels []string
+	Output string
+}
+
+type NodeInventory struct {
+	Name             string            `json:"name"`
+	KubeletVersion   string            `json:"kubeletVersion"`
+	KubeProxyVersion string            `json:"kubeProxyVersion,omitempty"`
+	ContainerRuntime string            `json:"containerRuntime"`
+	OSImage          string            `json:"osImage"`
+	KernelVersion    string            `json:"kernelVersion"`
+	Architecture     string            `json:"architecture"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	// Skew lists the components outside the version skew policy.
+	Skew []string `json:"skew,omitempty"`
+}
+
+// VersionCount is the number of nodes running a version of a component.
+type VersionCount struct {
+	Component string `json:"component"`
+	Version   string `json:"version"`
+	Nodes     int    `json:"nodes"`
+}
+
+type Inventory struct {
+	APIServerVersion string           `json:"apiServerVersion"`
+	Labels           []string         `json:"-"`
+	Nodes            []*NodeInventory `json:"nodes"`
+	Versions         []*VersionCount  `json:"versions"`
+}
+
+// BuildInventory reads the NodeInfo of the nodes and checks the kubelet and kube-proxy
+// versions against the apiserver.
+func BuildInventory(nodes []v1.Node, server string, labels []string) *Inventory {
+	inventory := &Inventory{APIServerVersion: server, Labels: labels}
+
+	counts := make(map[[2]string]int)
+	for _, node := range nodes {
+		info := node.Status.NodeInfo
+		ni := &NodeInventory{
+			Name:             node.Name,
+			KubeletVersion:   info.KubeletVersion,
+			KubeProxyVersion: info.KubeProxyVersion,
+			ContainerRuntime: info.ContainerRuntimeVersion,
+			OSImage:          info.OSImage,
+			KernelVersion:    info.KernelVersion,
+			Architecture:     info.Architecture,
+			Labels:           make(map[string]string),
+		}
+		for _, label := range labels {
+			if value, ok := node.Labels[label]; ok {
+				ni.Labels[label] = value
+			}
+		}
+		if skew := util.KubeletSkew(ni.KubeletVersion, server); skew != "" {
+			ni.Skew = append(ni.Skew, skew)
+		}
+		// kube-proxy no longer reports its version since 1.31
+		if ni.KubeProxyVersion != "" {
+			if skew := util.ComponentSkew("kube-proxy", ni.KubeProxyVersion, server); skew != "" {
+				ni.Skew = append(ni.Skew, skew)
+			}
+		}
+		inventory.Nodes = append(inventory.Nodes, ni)
+
+		for component, version := range map[string]string{
+			"kubelet": ni.KubeletVersion, "kube-proxy": ni.KubeProxyVersion, "container runtime": ni.ContainerRuntime,
+			"os image": ni.OSImage, "kernel": ni.KernelVersion,
+		} {
+			if version != "" {
+				counts[[2]string{component, version}]++
+			}
+		}
+	}
+	sort.Slice(inventory.Nodes, func(i, j int) bool { return inventory.Nodes[i].Name < inventory.Nodes[j].Name })
+
+	order := map[string]int{"kubelet": 0, "kube-proxy": 1, "container runtime": 2, "os image": 3, "kernel": 4}
+	for key, count := range counts {
+		inventory.Versions = append(inventory.Versions, &VersionCount{Component: key[0], Version: key[1], Nodes: count})
+	}
+	sort.Slice(inventory.Versions, func(i, j int) bool {
+		vi, vj := inventory.Versions[i], inventory.Versions[j]
+		if vi.Component != vj.Component {
+			return order[vi.Component] < order[vj.Component]
+		}
+		return vi.Version < vj.Version
+	})
+	return inventory
+}
+
+func (n *NodeInventoryReporter) GetNodeInventory() error {
+	nodes, err := framework.ListAllNodes(n.ClientSet, metav1.ListOptions{LabelSelector: n.Selector})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	server, err := n.ClientSet.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("failed to get apiserver version: %w", err)
+	}
+
+	inventory := BuildInventory(nodes, server.GitVersion, n.Labels)
+	if n.Output == OutputJSON {
+		fmt.Println(util.ToJSONIndent(inventory))
+		return nil
+	}
+	inventory.Print(n.Output)
+	return nil
+}
+
+// Print renders the node and version tables as table, csv or markdown. Colors are only used
+// by the table output, the others are meant to be pasted into change tickets.
+func (inv *Inventory) Print(output string) {
+	colored := output == OutputTable
+
+	header := []string{"nodeName", "kubelet", "kube-proxy", "container runtime", "os image", "kernel", "arch"}
+	header = append(header, inv.Labels...)
+	header = append(header, fmt.Sprintf("skew (apiserver %s)", inv.APIServerVersion))
+	nodeTable := newInventoryTable(header)
+	for _, ni := range inv.Nodes {
+		row := []string{ni.Name, ni.KubeletVersion, orDash(ni.KubeProxyVersion), ni.ContainerRuntime, ni.OSImage, ni.KernelVersion, ni.Architecture}
+		for _, label := range inv.Labels {
+			row = append(row, orDash(ni.Labels[label]))
+		}
+		skew := util.NewGreenText("OK")
+		if len(ni.Skew) > 0 {
+			skew = util.NewRedText(strings.Join(ni.Skew, "; "))
+		}
+		if colored {
+			row = append(row, skew.String())
+		} else {
+			row = append(row, skew.Text)
+		}
+		nodeTable.AppendRow(util.ListToRow(row))
+	}
+
+	versionTable := newInventoryTable([]string{"component", "version", "nodes"})
+	for _, vc := range inv.Versions {
+		versionTable.AppendRow(table.Row{vc.Component, vc.Version, vc.Nodes})
+	}
+
+	for _, t := range []table.Writer{nodeTable, versionTable} {
+		switch output {
+		case OutputCSV:
+			t.RenderCSV()
+		case OutputMarkdown:
+			t.RenderMarkdown()
+		default:
+			t.Render()
+		}
+		fmt.Println()
+	}
+}
+
+func newInventoryTable(header []string) table.Writer {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(util.ListToRow(header))
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	return t
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package nodes
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestBuildInventory(t *testing.T) {
+	const zoneLabel = "topology.kubernetes.io/zone"
+	nodes := []v1.Node{
+		newTestVersionNode("node3", "v1.27.9", "", "containerd://1.7.13", nil),
+		newTestVersionNode("node1", "v1.31.1", "v1.31.1", "containerd://1.7.13", map[string]string{zoneLabel: "zone-a"}),
+		newTestVersionNode("node2", "v1.30.4", "v1.32.0", "containerd://1.6.28", map[string]string{zoneLabel: "zone-b"}),
+	}
+	type node struct {
+		Name   string
+		Labels map[string]string
+		Skew   []string
+	}
+	tests := []struct {
+		name         string
+		server       string
+		wantNodes    []node
+		wantVersions []VersionCount
+	}{
+		{
+			name:   "skew and version counts",
+			server: "v1.31.2",
+			wantNodes: []node{
+				{Name: "node1", Labels: map[string]string{zoneLabel: "zone-a"}},
+				{Name: "node2", Labels: map[string]string{zoneLabel: "zone-b"}, Skew: []string{"kube-proxy v1.32.0 is newer than apiserver v1.31.2"}},
+				{Name: "node3", Labels: map[string]string{}, Skew: []string{"kubelet v1.27.9 is 4 minor versions older than apiserver v1.31.2, at most 3 is supported"}},
+			},
+			wantVersions: []VersionCount{
+				{Component: "kubelet", Version: "v1.27.9", Nodes: 1},
+				{Component: "kubelet", Version: "v1.30.4", Nodes: 1},
+				{Component: "kubelet", Version: "v1.31.1", Nodes: 1},
+				{Component: "kube-proxy", Version: "v1.31.1", Nodes: 1},
+				{Component: "kube-proxy", Version: "v1.32.0", Nodes: 1},
+				{Component: "container runtime", Version: "containerd://1.6.28", Nodes: 1},
+				{Component: "container runtime", Version: "containerd://1.7.13", Nodes: 2},
+				{Component: "os image", Version: "Ubuntu 22.04.4 LTS", Nodes: 3},
+				{Component: "kernel", Version: "5.15.0-105-generic", Nodes: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildInventory(nodes, tt.server, []string{zoneLabel})
+			var gotNodes []node
+			for _, ni := range got.Nodes {
+				gotNodes = append(gotNodes, node{Name: ni.Name, Labels: ni.Labels, Skew: ni.Skew})
+			}
+			if !reflect.DeepEqual(gotNodes, tt.wantNodes) {
+				t.Errorf("BuildInventory() nodes = %+v, want %+v", gotNodes, tt.wantNodes)
+			}
+			var gotVersions []VersionCount
+			for _, vc := range got.Versions {
+				gotVersions = append(gotVersions, *vc)
+			}
+			if !reflect.DeepEqual(gotVersions, tt.wantVersions) {
+				t.Errorf("BuildInventory() versions = %+v, want %+v", gotVersions, tt.wantVersions)
+			}
+		})
+	}
+}
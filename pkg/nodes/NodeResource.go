@@ -1,14 +1,46 @@
 package nodes
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/ops-tool/pkg/scheduler/framework"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 type NodeResourceReporter struct {
-	ClientSet *kubernetes.Clientset
+	ClientSet     *kubernetes.Clientset
+	MetricClient  *metrics.Clientset
+	DynamicClient dynamic.Interface
+
+	// GroupBy is a node label, when set nodes are aggregated per label value.
+	GroupBy string
+	// View is the report to print, one of the View constants.
+	View string
+	// Shapes are the pod shapes counted by the fragmentation view.
+	Shapes []*framework.Shape
+	// Warn and Critical are the limits/allocatable percentages per resource of the overcommit view.
+	Warn     map[string]int
+	Critical map[string]int
+	// Top is the number of burstable pods listed per overcommitted node.
+	Top int
+	// Configz reads the kubelet CPU manager settings of every node for the cpumanager view.
+	Configz bool
 }
 
+const (
+	ViewResources     = "resources"
+	ViewFragmentation = "fragmentation"
+	ViewOvercommit    = "overcommit"
+	ViewCPUManager    = "cpumanager"
+	ViewNUMA          = "numa"
+)
+
+var Views = []string{ViewResources, ViewFragmentation, ViewOvercommit, ViewCPUManager, ViewNUMA}
+
 func (n *NodeResourceReporter) GetNodeResource() error {
 
 	nodeResourceList, err := framework.BuildNodeList(n.ClientSet)
@@ -17,6 +49,70 @@ func (n *NodeResourceReporter) GetNodeResource() error {
 		return err
 	}
 
+	if n.View == ViewFragmentation {
+		framework.PrintFragmentation(nodeResourceList, n.Shapes)
+		return nil
+	}
+	if n.View == ViewOvercommit {
+		framework.PrintOvercommit(nodeResourceList, n.Warn, n.Critical, n.Top)
+		return nil
+	}
+	if n.View == ViewCPUManager {
+		framework.PrintCPUPools(nodeResourceList, n.cpuManagerConfigs(nodeResourceList))
+		return nil
+	}
+	if n.View == ViewNUMA {
+		topologies, err := framework.ListNodeTopologies(n.DynamicClient)
+		if err != nil {
+			return err
+		}
+		framework.PrintNodeTopologies(nodeResourceList, topologies)
+		return nil
+	}
+
+	n.setUsage(nodeResourceList)
+	if n.GroupBy != "" {
+		framework.PrintNodeGroups(nodeResourceList, n.GroupBy)
+		return nil
+	}
 	framework.PrintNodeList(nodeResourceList)
 	return nil
 }
+
+// setUsage fills the metrics-server usage of the nodes, the usage columns are left out when
+// metrics-server is not available.
+func (n *NodeResourceReporter) setUsage(nodeList []*framework.Node) {
+	if n.MetricClient == nil {
+		return
+	}
+	nodeMetricsList, err := n.MetricClient.MetricsV1beta1().NodeMetricses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("Error getting node metrics, usage is not shown: %v\n", err)
+		return
+	}
+	for _, node := range nodeList {
+		for _, nodeMetrics := range nodeMetricsList.Items {
+			if nodeMetrics.Name == node.Name {
+				node.Usage = nodeMetrics.Usage
+				break
+			}
+		}
+	}
+}
+
+// cpuManagerConfigs reads configz of the nodes when Configz is set, nodes failing are left out.
+func (n *NodeResourceReporter) cpuManagerConfigs(nodeList []*framework.Node) map[string]*framework.CPUManagerConfig {
+	configs := make(map[string]*framework.CPUManagerConfig)
+	if !n.Configz {
+		return configs
+	}
+	for _, node := range nodeList {
+		config, err := framework.GetCPUManagerConfig(n.ClientSet, node.Name)
+		if err != nil {
+			fmt.Printf("%v, reserved cpus are estimated\n", err)
+			continue
+		}
+		configs[node.Name] = config
+	}
+	return configs
+}
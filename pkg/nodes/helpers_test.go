@@ -0,0 +1,55 @@
+package nodes
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testGPU v1.ResourceName = "nvidia.com/gpu"
+
+func newTestNode(name string, labels map[string]string) v1.Node {
+	return v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+// newTestGPUNode returns a node advertising gpus, nothing when capacity is empty.
+func newTestGPUNode(name, capacity, allocatable string) v1.Node {
+	node := newTestNode(name, nil)
+	if capacity != "" {
+		node.Status.Capacity = v1.ResourceList{testGPU: resource.MustParse(capacity)}
+		node.Status.Allocatable = v1.ResourceList{testGPU: resource.MustParse(allocatable)}
+	}
+	return node
+}
+
+// newTestGPUPod returns a pod in the default namespace with one container per gpu count, an
+// init container too when initGPU is not empty.
+func newTestGPUPod(name, nodeName string, phase v1.PodPhase, initGPU string, gpus ...string) v1.Pod {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: name},
+		Spec:       v1.PodSpec{NodeName: nodeName},
+		Status:     v1.PodStatus{Phase: phase},
+	}
+	if initGPU != "" {
+		limits := v1.ResourceList{testGPU: resource.MustParse(initGPU)}
+		pod.Spec.InitContainers = []v1.Container{{Name: "init", Resources: v1.ResourceRequirements{Requests: limits, Limits: limits}}}
+	}
+	for i, gpu := range gpus {
+		limits := v1.ResourceList{testGPU: resource.MustParse(gpu)}
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: string(rune('a' + i)), Resources: v1.ResourceRequirements{Requests: limits, Limits: limits}})
+	}
+	return pod
+}
+
+func newTestVersionNode(name, kubelet, kubeProxy, runtime string, labels map[string]string) v1.Node {
+	node := newTestNode(name, labels)
+	node.Status.NodeInfo = v1.NodeSystemInfo{
+		KubeletVersion:          kubelet,
+		KubeProxyVersion:        kubeProxy,
+		ContainerRuntimeVersion: runtime,
+		OSImage:                 "Ubuntu 22.04.4 LTS",
+		KernelVersion:           "5.15.0-105-generic",
+		Architecture:            "amd64",
+	}
+	return node
+}
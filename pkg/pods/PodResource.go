@@ -10,6 +10,7 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,8 +25,10 @@ type PodResourceReporter struct {
 
 	Namespace string
 	Node      string
-	Workload  string
-	Sort      string
+	// Workload is a comma separated list of workload kinds pods are rolled up to, pods are
+	// listed one by one when empty.
+	Workload string
+	Sort     string
 }
 
 type PodResource struct {
@@ -86,28 +89,7 @@ func (pl PodResourceList) Sort(key, mode string) {
 	}
 
 	sort.Slice(pl, func(i, j int) bool {
-		// 获取比较值
-		var less bool
-		switch key {
-		case "Name":
-			less = strings.Compare(pl[i].Name, pl[j].Name) < 0
-		case "Namespace":
-			less = strings.Compare(pl[i].Namespace, pl[j].Namespace) < 0
-		case "NodeName":
-			less = strings.Compare(pl[i].NodeName, pl[j].NodeName) < 0
-		case "CPURequest":
-			less = pl[i].CPURequest.MilliValue() < pl[j].CPURequest.MilliValue()
-		case "CPULimit":
-			less = pl[i].CPULimit.MilliValue() < pl[j].CPULimit.MilliValue()
-		case "CPUUsage":
-			less = pl[i].CPUUsage.MilliValue() < pl[j].CPUUsage.MilliValue()
-		case "MemRequest":
-			less = pl[i].MemRequest.Value() < pl[j].MemRequest.Value()
-		case "MemLimit":
-			less = pl[i].MemLimit.Value() < pl[j].MemLimit.Value()
-		case "MemUsage":
-			less = pl[i].MemUsage.Value() < pl[j].MemUsage.Value()
-		}
+		less := lessPodResource(pl[i], pl[j], key)
 
 		// 处理排序模式
 		if mode == SortDesc {
@@ -117,6 +99,30 @@ func (pl PodResourceList) Sort(key, mode string) {
 	})
 }
 
+func lessPodResource(a, b *PodResource, key string) bool {
+	switch key {
+	case "Name":
+		return strings.Compare(a.Name, b.Name) < 0
+	case "Namespace":
+		return strings.Compare(a.Namespace, b.Namespace) < 0
+	case "NodeName":
+		return strings.Compare(a.NodeName, b.NodeName) < 0
+	case "CPURequest":
+		return a.CPURequest.MilliValue() < b.CPURequest.MilliValue()
+	case "CPULimit":
+		return a.CPULimit.MilliValue() < b.CPULimit.MilliValue()
+	case "CPUUsage":
+		return a.CPUUsage.MilliValue() < b.CPUUsage.MilliValue()
+	case "MemRequest":
+		return a.MemRequest.Value() < b.MemRequest.Value()
+	case "MemLimit":
+		return a.MemLimit.Value() < b.MemLimit.Value()
+	case "MemUsage":
+		return a.MemUsage.Value() < b.MemUsage.Value()
+	}
+	return false
+}
+
 func (pl PodResourceList) Print() {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
@@ -200,18 +206,31 @@ func (p *PodResourceReporter) GetPodResource() error {
 		podResourceList = append(podResourceList, podResource)
 	}
 
-	// 需要对资源进行排序
-	if p.Sort != "" {
-		key, mode := "CPURequest", "desc"
-		sortParam := strings.Split(p.Sort, ",")
-		if len(sortParam) == 1 {
-			key = sortParam[0]
-		} else if len(sortParam) == 2 {
-			key, mode = sortParam[0], sortParam[1]
-		} else {
-			return fmt.Errorf("invalid sort paramter %s, using format key(,mode)", p.Sort)
+	key, mode, err := p.sortParam()
+	if err != nil {
+		return err
+	}
+
+	// 按workload汇总
+	if p.Workload != "" {
+		kinds, err := ParseTargets(p.Workload)
+		if err != nil {
+			return err
+		}
+		resolver, err := workloads.NewOwnerResolver(p.ClientSet, p.Namespace)
+		if err != nil {
+			return err
+		}
+		workloadResourceList := RollupByOwner(pods.Items, podResourceList, resolver, kinds)
+		if key != "" {
+			workloadResourceList.Sort(key, mode)
 		}
+		workloadResourceList.Print()
+		return nil
+	}
 
+	// 需要对资源进行排序
+	if key != "" {
 		podResourceList.Sort(key, mode)
 	}
 	podResourceList.Print()
@@ -220,6 +239,23 @@ func (p *PodResourceReporter) GetPodResource() error {
 
 }
 
+// sortParam parses the sort flag, key is empty when no sort is asked for.
+func (p *PodResourceReporter) sortParam() (key, mode string, err error) {
+	if p.Sort == "" {
+		return "", "", nil
+	}
+	key, mode = "CPURequest", "desc"
+	sortParam := strings.Split(p.Sort, ",")
+	if len(sortParam) == 1 {
+		key = sortParam[0]
+	} else if len(sortParam) == 2 {
+		key, mode = sortParam[0], sortParam[1]
+	} else {
+		return "", "", fmt.Errorf("invalid sort paramter %s, using format key(,mode)", p.Sort)
+	}
+	return key, mode, nil
+}
+
 func BuildPodResource(pod v1.Pod) *PodResource {
 	totalCPURequest := resource.NewQuantity(0, resource.DecimalSI)
 	totalCPULimit := resource.NewQuantity(0, resource.DecimalSI)
@@ -250,7 +286,7 @@ func BuildPodResource(pod v1.Pod) *PodResource {
 		CPURequest: *totalCPURequest,
 		CPULimit:   *totalCPULimit,
 		MemRequest: *totalMemRequest,
-		MemLimit:   *totalCPULimit,
+		MemLimit:   *totalMemLimit,
 	}
 }
 func BuildPodMetricMap(podMetricsList *metricsv1beta1.PodMetricsList) PodMetricMap {
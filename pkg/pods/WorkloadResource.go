@@ -0,0 +1,152 @@
+package pods
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TargetAll selects every kind of workload, bare pods included.
+const TargetAll = "all"
+
+// ParseTargets turns a comma separated list of kinds into the set of workload kinds to roll
+// pods up to, nil meaning every kind.
+func ParseTargets(target string) (map[string]bool, error) {
+	kinds := make(map[string]bool)
+	for _, t := range strings.Split(target, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == TargetAll {
+			return nil, nil
+		}
+		kind, ok := workloads.KindOf(t)
+		if !ok {
+			return nil, fmt.Errorf("unsupported target %q, supported targets: deploy, sts, ds, rs, job, cronjob, pod, all", t)
+		}
+		kinds[kind] = true
+	}
+	return kinds, nil
+}
+
+// WorkloadResource is the resources of the pods of a workload.
+type WorkloadResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Replicas  int64
+	Sum       PodResource
+	Max       PodResource
+}
+
+func (wr *WorkloadResource) add(pr *PodResource) {
+	wr.Replicas++
+	for _, field := range []struct{ sum, max, value *resource.Quantity }{
+		{&wr.Sum.CPURequest, &wr.Max.CPURequest, &pr.CPURequest},
+		{&wr.Sum.CPULimit, &wr.Max.CPULimit, &pr.CPULimit},
+		{&wr.Sum.CPUUsage, &wr.Max.CPUUsage, &pr.CPUUsage},
+		{&wr.Sum.MemRequest, &wr.Max.MemRequest, &pr.MemRequest},
+		{&wr.Sum.MemLimit, &wr.Max.MemLimit, &pr.MemLimit},
+		{&wr.Sum.MemUsage, &wr.Max.MemUsage, &pr.MemUsage},
+	} {
+		field.sum.Add(*field.value)
+		if field.value.Cmp(*field.max) > 0 {
+			*field.max = field.value.DeepCopy()
+		}
+	}
+}
+
+// cpu formats sum/avg/max in milli cores.
+func (wr *WorkloadResource) cpu(sum, max resource.Quantity) string {
+	return fmt.Sprintf("%d/%d/%d", sum.MilliValue(), sum.MilliValue()/wr.Replicas, max.MilliValue())
+}
+
+// mem formats sum/avg/max in Mi.
+func (wr *WorkloadResource) mem(sum, max resource.Quantity) string {
+	mi := int64(1024 * 1024)
+	return fmt.Sprintf("%d/%d/%d", sum.Value()/mi, sum.Value()/wr.Replicas/mi, max.Value()/mi)
+}
+
+func (wr *WorkloadResource) ToStringList() []string {
+	return []string{
+		fmt.Sprintf("%s/%s", wr.Namespace, wr.Name),
+		wr.Kind,
+		fmt.Sprintf("%d", wr.Replicas),
+		wr.cpu(wr.Sum.CPURequest, wr.Max.CPURequest),
+		wr.cpu(wr.Sum.CPULimit, wr.Max.CPULimit),
+		wr.cpu(wr.Sum.CPUUsage, wr.Max.CPUUsage),
+		wr.mem(wr.Sum.MemRequest, wr.Max.MemRequest),
+		wr.mem(wr.Sum.MemLimit, wr.Max.MemLimit),
+		wr.mem(wr.Sum.MemUsage, wr.Max.MemUsage),
+	}
+}
+
+type WorkloadResourceList []*WorkloadResource
+
+// RollupByOwner groups the pod resources by the top-level workload of their pod, keeping the
+// kinds selected. Terminated pods are left out, podResources is in the order of podList.
+func RollupByOwner(podList []v1.Pod, podResources PodResourceList, resolver *workloads.OwnerResolver, kinds map[string]bool) WorkloadResourceList {
+	index := make(map[string]*WorkloadResource)
+	var result WorkloadResourceList
+	for i := range podList {
+		pod := &podList[i]
+		if workloads.IsTerminated(pod) {
+			continue
+		}
+		kind, name := resolver.Resolve(pod)
+		if kinds != nil && !kinds[kind] {
+			continue
+		}
+		key := strings.Join([]string{kind, pod.Namespace, name}, "/")
+		wr, ok := index[key]
+		if !ok {
+			wr = &WorkloadResource{Kind: kind, Namespace: pod.Namespace, Name: name}
+			wr.Sum.Name, wr.Sum.Namespace = name, pod.Namespace
+			index[key] = wr
+			result = append(result, wr)
+		}
+		wr.add(podResources[i])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Sort sorts the workloads by the sum of the pod resource key, equal workloads keep their order.
+func (wl WorkloadResourceList) Sort(key, mode string) {
+	if _, valid := validSortKeys[key]; !valid {
+		return
+	}
+	sort.SliceStable(wl, func(i, j int) bool {
+		if mode == SortDesc {
+			return lessPodResource(&wl[j].Sum, &wl[i].Sum, key)
+		}
+		return lessPodResource(&wl[i].Sum, &wl[j].Sum, key)
+	})
+}
+
+func (wl WorkloadResourceList) Print() {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Namespace/Workload", "Kind", "Replicas", "CPURequest(m)\nsum/avg/max", "CPULimit(m)\nsum/avg/max",
+		"CPUUsage(m)\nsum/avg/max", "MemRequest(Mi)\nsum/avg/max", "MemLimit(Mi)\nsum/avg/max", "MemUsage(Mi)\nsum/avg/max"})
+
+	for _, wr := range wl {
+		t.AppendRow(util.ListToRow(wr.ToStringList()))
+	}
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
@@ -0,0 +1,112 @@
+package pods
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ops-tool/pkg/workloads"
+)
+
+func TestRollupByOwner(t *testing.T) {
+	controller := func(kind, name string) []metav1.OwnerReference {
+		isController := true
+		return []metav1.OwnerReference{{Kind: kind, Name: name, UID: types.UID(kind + "/" + name), Controller: &isController}}
+	}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-5d8f", Namespace: "default", UID: "ReplicaSet/web-5d8f",
+		OwnerReferences: controller("Deployment", "web")}}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "backup-2891", Namespace: "default", UID: "Job/backup-2891",
+		OwnerReferences: controller("CronJob", "backup")}}
+	resolver, err := workloads.NewOwnerResolver(fake.NewSimpleClientset(replicaSet, job), "")
+	if err != nil {
+		t.Fatalf("NewOwnerResolver() error = %v", err)
+	}
+
+	newPod := func(name, cpu, memory string, owner []metav1.OwnerReference) v1.Pod {
+		list := v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(memory)}
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", OwnerReferences: owner},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Resources: v1.ResourceRequirements{Requests: list, Limits: list}}}},
+		}
+	}
+	podList := []v1.Pod{
+		newPod("web-5d8f-a", "500m", "1Gi", controller("ReplicaSet", "web-5d8f")),
+		newPod("web-5d8f-b", "1500m", "3Gi", controller("ReplicaSet", "web-5d8f")),
+		newPod("backup-2891-x", "1", "2Gi", controller("Job", "backup-2891")),
+		newPod("debug", "100m", "128Mi", nil),
+	}
+	var podResources PodResourceList
+	for _, pod := range podList {
+		podResources = append(podResources, BuildPodResource(pod))
+	}
+
+	kinds, err := ParseTargets("deploy,cronjob")
+	if err != nil {
+		t.Fatalf("ParseTargets() error = %v", err)
+	}
+	result := RollupByOwner(podList, podResources, resolver, kinds)
+	if len(result) != 2 || result[0].Kind != "CronJob" || result[1].Kind != "Deployment" {
+		t.Fatalf("RollupByOwner() = %v", result)
+	}
+	web := result[1]
+	if web.Name != "web" || web.Replicas != 2 {
+		t.Errorf("web = %s with %d replicas", web.Name, web.Replicas)
+	}
+	if got := web.cpu(web.Sum.CPURequest, web.Max.CPURequest); got != "2000/1000/1500" {
+		t.Errorf("web cpu requests = %s, want 2000/1000/1500", got)
+	}
+	if got := web.mem(web.Sum.MemRequest, web.Max.MemRequest); got != "4096/2048/3072" {
+		t.Errorf("web memory requests = %s, want 4096/2048/3072", got)
+	}
+	if got := web.mem(web.Sum.MemLimit, web.Max.MemLimit); got != "4096/2048/3072" {
+		t.Errorf("web memory limits = %s, want 4096/2048/3072", got)
+	}
+
+	if result := RollupByOwner(podList, podResources, resolver, nil); len(result) != 3 {
+		t.Errorf("RollupByOwner() of all kinds = %d workloads, want 3", len(result))
+	}
+	if _, err := ParseTargets("sts,foo"); err == nil {
+		t.Errorf("ParseTargets() of an unknown kind should fail")
+	}
+}
+
+func TestWorkloadResourceList_Sort(t *testing.T) {
+	newWorkload := func(name, cpu string) *WorkloadResource {
+		wr := &WorkloadResource{Kind: "Deployment", Namespace: "default", Name: name}
+		wr.Sum.Name, wr.Sum.CPURequest = name, resource.MustParse(cpu)
+		return wr
+	}
+	type args struct {
+		key  string
+		mode string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{name: "asc", args: args{key: "CPURequest", mode: SortAsc}, want: []string{"a", "c", "d", "b"}},
+		{name: "desc keeps equal workloads in order", args: args{key: "CPURequest", mode: SortDesc}, want: []string{"b", "d", "a", "c"}},
+		{name: "invalid key", args: args{key: "Foo", mode: SortDesc}, want: []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wl := WorkloadResourceList{newWorkload("a", "1"), newWorkload("b", "3"), newWorkload("c", "1"), newWorkload("d", "2")}
+			wl.Sort(tt.args.key, tt.args.mode)
+			var got []string
+			for _, wr := range wl {
+				got = append(got, wr.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Sort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package resilience
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/schollz/progressbar/v3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+)
+
+const (
+	DomainNode = "node"
+	DomainZone = "zone"
+	DomainAll  = "all"
+)
+
+type ResilienceChecker struct {
+	ClientSet *kubernetes.Clientset
+
+	// Domain is the kind of failure domain to iterate: node, zone or all.
+	Domain    string
+	ZoneLabel string
+
+	// Pods are selected when they match every non-empty filter below, all pods when none is set.
+	Namespaces      []string
+	Workloads       []string
+	PriorityClasses []string
+}
+
+type DomainResult struct {
+	Kind          string
+	Name          string
+	Nodes         int
+	Displaced     int
+	Rescheduled   int
+	Unschedulable []*scheduler.Placement
+	// Shortfall sums the requests of the pods left unschedulable, per resource.
+	Shortfall framework.ResourceList
+}
+
+func (dr *DomainResult) shortfallString() string {
+	var names []string
+	for name := range dr.Shortfall {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var result []string
+	for _, name := range names {
+		result = append(result, fmt.Sprintf("%s: %s", name, dr.Shortfall[name]))
+	}
+	return strings.Join(result, "\n")
+}
+
+func (dr *DomainResult) ToStringList() []string {
+	unschedulable := util.NewGreenText("0")
+	if len(dr.Unschedulable) > 0 {
+		unschedulable = util.NewRedText(fmt.Sprintf("%d", len(dr.Unschedulable)))
+	}
+	var pods []string
+	for _, placement := range dr.Unschedulable {
+		pods = append(pods, fmt.Sprintf("%s/%s", placement.Pod.Namespace, placement.Pod.Name))
+	}
+	return []string{
+		dr.Kind,
+		dr.Name,
+		fmt.Sprintf("%d", dr.Nodes),
+		fmt.Sprintf("%d", dr.Displaced),
+		fmt.Sprintf("%d", dr.Rescheduled),
+		unschedulable.String(),
+		dr.shortfallString(),
+		strings.Join(pods, "\n"),
+	}
+}
+
+// worse orders results by unschedulable pods, then by the cpu they request.
+func (dr *DomainResult) worse(other *DomainResult) bool {
+	if len(dr.Unschedulable) != len(other.Unschedulable) {
+		return len(dr.Unschedulable) > len(other.Unschedulable)
+	}
+	var cpu, otherCPU int64
+	if r, ok := dr.Shortfall[string(v1.ResourceCPU)]; ok {
+		cpu = r.Requests
+	}
+	if r, ok := other.Shortfall[string(v1.ResourceCPU)]; ok {
+		otherCPU = r.Requests
+	}
+	return cpu > otherCPU
+}
+
+type failureDomain struct {
+	kind  string
+	name  string
+	nodes sets.Set[string]
+}
+
+func (r *ResilienceChecker) failureDomains(nodes []v1.Node) []failureDomain {
+	var domains []failureDomain
+	if r.Domain == DomainNode || r.Domain == DomainAll {
+		for _, node := range nodes {
+			domains = append(domains, failureDomain{kind: DomainNode, name: node.Name, nodes: sets.New(node.Name)})
+		}
+	}
+	if r.Domain == DomainZone || r.Domain == DomainAll {
+		zones := map[string]sets.Set[string]{}
+		for _, node := range nodes {
+			zone, ok := node.Labels[r.ZoneLabel]
+			if !ok {
+				continue
+			}
+			if _, ok := zones[zone]; !ok {
+				zones[zone] = sets.New[string]()
+			}
+			zones[zone].Insert(node.Name)
+		}
+		for _, zone := range sets.List(sets.KeySet(zones)) {
+			domains = append(domains, failureDomain{kind: DomainZone, name: zone, nodes: zones[zone]})
+		}
+	}
+	return domains
+}
+
+func (r *ResilienceChecker) selected(pod *v1.Pod) bool {
+	if workloads.IsMirrorPod(pod) || workloads.IsDaemonSetPod(pod) || workloads.IsTerminated(pod) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !contains(r.Namespaces, pod.Namespace) {
+		return false
+	}
+	if len(r.PriorityClasses) > 0 && !contains(r.PriorityClasses, pod.Spec.PriorityClassName) {
+		return false
+	}
+	if len(r.Workloads) > 0 && !r.selectedWorkload(pod) {
+		return false
+	}
+	return true
+}
+
+// selectedWorkload matches the owner of pod against the workload references, kind/name with
+// the kind in any form kubectl accepts, or a bare name of any kind.
+func (r *ResilienceChecker) selectedWorkload(pod *v1.Pod) bool {
+	ownerKind, ownerName := workloads.OwnerOf(pod)
+	for _, ref := range r.Workloads {
+		kind, name, err := workloads.ParseRef(ref)
+		if err != nil || name != ownerName {
+			continue
+		}
+		if !strings.Contains(ref, "/") {
+			return true
+		}
+		if kind, ok := workloads.KindOf(kind); ok && kind == ownerKind {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ResilienceChecker) Check() error {
+
+	snapshot, err := scheduler.TakeSnapshot(r.ClientSet)
+	if err != nil {
+		return err
+	}
+
+	domains := r.failureDomains(snapshot.Nodes)
+	if len(domains) == 0 {
+		return fmt.Errorf("no failure domain found, check the zone label %s", r.ZoneLabel)
+	}
+
+	bar := progressbar.NewOptions(len(domains),
+		progressbar.OptionSetDescription("Simulating failure domains"),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetWidth(40),
+	)
+
+	var results []*DomainResult
+	for _, domain := range domains {
+		results = append(results, r.simulate(snapshot, domain))
+		bar.Add(1)
+	}
+	fmt.Println()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].worse(results[j])
+	})
+	printResults(results)
+
+	worst := results[0]
+	if len(worst.Unschedulable) == 0 {
+		fmt.Println(util.NewGreenText(fmt.Sprintf("losing any single %s leaves no selected pod unschedulable", r.Domain)))
+	} else {
+		fmt.Println(util.NewRedText(fmt.Sprintf("worst case: losing %s %s leaves %d pods unschedulable", worst.Kind, worst.Name, len(worst.Unschedulable))))
+	}
+	return nil
+}
+
+func (r *ResilienceChecker) simulate(snapshot *scheduler.Snapshot, domain failureDomain) *DomainResult {
+	result := &DomainResult{Kind: domain.kind, Name: domain.name, Nodes: domain.nodes.Len(), Shortfall: framework.ResourceList{}}
+
+	var displaced []*v1.Pod
+	for i := range snapshot.Pods {
+		pod := &snapshot.Pods[i]
+		if domain.nodes.Has(pod.Spec.NodeName) && r.selected(pod) {
+			displaced = append(displaced, pod)
+		}
+	}
+	result.Displaced = len(displaced)
+	if len(displaced) == 0 {
+		return result
+	}
+
+	sort.SliceStable(displaced, func(i, j int) bool {
+		return scheduler.PlaceBefore(displaced[i], displaced[j])
+	})
+
+	remaining := snapshot.WithoutNodes(domain.nodes)
+	for _, pod := range displaced {
+		pending := pod.DeepCopy()
+		pending.Spec.NodeName = ""
+		placement := remaining.Place(pending)
+		if placement.Scheduled() {
+			result.Rescheduled++
+			continue
+		}
+		result.Unschedulable = append(result.Unschedulable, placement)
+		for name, request := range framework.BuildPodResourceList(pod) {
+			if _, ok := result.Shortfall[name]; !ok {
+				result.Shortfall[name] = &framework.Resource{Name: name}
+			}
+			result.Shortfall[name].Requests += request.Requests
+		}
+	}
+	return result
+}
+
+func printResults(results []*DomainResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Domain", "Name", "Nodes", "Displaced", "Rescheduled", "Unschedulable", "Shortfall", "UnschedulablePods"})
+	for _, result := range results {
+		t.AppendRow(util.ListToRow(result.ToStringList()))
+	}
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
@@ -0,0 +1,119 @@
+package resilience
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/ops-tool/pkg/scheduler"
+	"github.com/ops-tool/pkg/scheduler/schedulertest"
+)
+
+func TestResilienceChecker_selected(t *testing.T) {
+	web := schedulertest.OwnedBy(schedulertest.Pod("web-1", "node1", nil, "1", "256Mi"), "Deployment", "web")
+	db := schedulertest.OwnedBy(schedulertest.Pod("db-0", "node1", nil, "1", "256Mi"), "StatefulSet", "db")
+	type args struct {
+		pod v1.Pod
+	}
+	tests := []struct {
+		name      string
+		workloads []string
+		args      args
+		want      bool
+	}{
+		{name: "no filter", args: args{pod: web}, want: true},
+		{name: "short kind", workloads: []string{"deploy/web"}, args: args{pod: web}, want: true},
+		{name: "kind", workloads: []string{"Deployment/web"}, args: args{pod: web}, want: true},
+		{name: "lower case kind", workloads: []string{"deployment/web"}, args: args{pod: web}, want: true},
+		{name: "bare name", workloads: []string{"web"}, args: args{pod: web}, want: true},
+		{name: "statefulset short kind", workloads: []string{"sts/db"}, args: args{pod: db}, want: true},
+		{name: "other kind", workloads: []string{"sts/web"}, args: args{pod: web}, want: false},
+		{name: "other name", workloads: []string{"deploy/db"}, args: args{pod: web}, want: false},
+		{name: "unknown kind", workloads: []string{"foo/web"}, args: args{pod: web}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ResilienceChecker{Workloads: tt.workloads}
+			if got := r.selected(&tt.args.pod); got != tt.want {
+				t.Errorf("selected() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResilienceChecker_simulate(t *testing.T) {
+	nodes := []v1.Node{
+		schedulertest.Node("node1", map[string]string{v1.LabelTopologyZone: "zone-a"}, "4", "8Gi"),
+		schedulertest.Node("node2", map[string]string{v1.LabelTopologyZone: "zone-a"}, "4", "8Gi"),
+		schedulertest.Node("node3", map[string]string{v1.LabelTopologyZone: "zone-b"}, "5", "8Gi"),
+	}
+	pods := []v1.Pod{
+		schedulertest.OwnedBy(schedulertest.Pod("web-1", "node1", nil, "2", "256Mi"), "Deployment", "web"),
+		schedulertest.OwnedBy(schedulertest.Pod("web-2", "node1", nil, "1", "256Mi"), "Deployment", "web"),
+		schedulertest.OwnedBy(schedulertest.Pod("db-0", "node2", nil, "4", "256Mi"), "StatefulSet", "db"),
+		schedulertest.OwnedBy(schedulertest.Pod("db-1", "node3", nil, "2", "256Mi"), "StatefulSet", "db"),
+	}
+	type want struct {
+		displaced     int
+		rescheduled   int
+		unschedulable []string
+		shortfallCPU  int64
+	}
+	tests := []struct {
+		name      string
+		workloads []string
+		domain    failureDomain
+		want      want
+	}{
+		{
+			name:   "node replicas rescheduled",
+			domain: failureDomain{kind: DomainNode, name: "node1", nodes: sets.New("node1")},
+			want:   want{displaced: 2, rescheduled: 2},
+		},
+		{
+			name:   "zone leaves the biggest replica unschedulable",
+			domain: failureDomain{kind: DomainZone, name: "zone-a", nodes: sets.New("node1", "node2")},
+			want:   want{displaced: 3, rescheduled: 2, unschedulable: []string{"db-0"}, shortfallCPU: 4000},
+		},
+		{
+			name:      "only selected workloads displaced",
+			workloads: []string{"sts/db"},
+			domain:    failureDomain{kind: DomainZone, name: "zone-a", nodes: sets.New("node1", "node2")},
+			want:      want{displaced: 1, rescheduled: 0, unschedulable: []string{"db-0"}, shortfallCPU: 4000},
+		},
+		{
+			name:   "empty domain",
+			domain: failureDomain{kind: DomainZone, name: "zone-c", nodes: sets.New[string]()},
+			want:   want{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ResilienceChecker{Workloads: tt.workloads}
+			got := r.simulate(scheduler.NewSnapshot(schedulertest.ClientSet(), nodes, pods), tt.domain)
+			if got.Displaced != tt.want.displaced || got.Rescheduled != tt.want.rescheduled {
+				t.Errorf("simulate() displaced %d rescheduled %d, want %d and %d", got.Displaced, got.Rescheduled, tt.want.displaced, tt.want.rescheduled)
+			}
+			var unschedulable []string
+			for _, placement := range got.Unschedulable {
+				unschedulable = append(unschedulable, placement.Pod.Name)
+			}
+			if len(unschedulable) != len(tt.want.unschedulable) {
+				t.Fatalf("simulate() unschedulable = %v, want %v", unschedulable, tt.want.unschedulable)
+			}
+			for i := range unschedulable {
+				if unschedulable[i] != tt.want.unschedulable[i] {
+					t.Errorf("simulate() unschedulable = %v, want %v", unschedulable, tt.want.unschedulable)
+				}
+			}
+			var cpu int64
+			if r, ok := got.Shortfall[string(v1.ResourceCPU)]; ok {
+				cpu = r.Requests
+			}
+			if cpu != tt.want.shortfallCPU {
+				t.Errorf("simulate() cpu shortfall = %d, want %d", cpu, tt.want.shortfallCPU)
+			}
+		})
+	}
+}
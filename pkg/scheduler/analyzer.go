@@ -12,22 +12,51 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/ops-tool/pkg/scheduler/framework"
 	"github.com/ops-tool/pkg/scheduler/framework/interpodaffinity"
 )
 
-var ReportHeader = []string{"nodeName", "Unschedulable", "nodeSelector", "nodeAffinity", "podAffinity", "Toleration", "resource", "PV"}
+const (
+	CheckUnschedulable   = "Unschedulable"
+	CheckNodeSelector    = "nodeSelector"
+	CheckNodeAffinity    = "nodeAffinity"
+	CheckPodAffinity     = "podAffinity"
+	CheckToleration      = "Toleration"
+	CheckResource        = "resource"
+	CheckPV              = "PV"
+	CheckTopologySpread  = "topologySpread"
+	CheckTopologyManager = "topologyManager"
+)
+
+var ReportHeader = []string{"nodeName", CheckUnschedulable, CheckNodeSelector, CheckNodeAffinity, CheckPodAffinity, CheckToleration, CheckResource, CheckPV, CheckTopologySpread, CheckTopologyManager}
+
+// StaticChecks only look at the pod spec and node labels/taints, not at what is running on the node.
+var StaticChecks = []string{CheckUnschedulable, CheckNodeSelector, CheckNodeAffinity, CheckToleration}
 
 type Analyzer struct {
-	ClientSet              *kubernetes.Clientset
+	ClientSet              kubernetes.Interface
 	targetPod              *v1.Pod
 	Namespace              string
 	PodName                string
 	TargetConditions       *Conditions
 	allNodes               []v1.Node
+	allPods                []v1.Pod
+	nodeResources          map[string]framework.ResourceList
 	interPodAffinityPlugin *interpodaffinity.InterPodAffinity
+
+	// topologySpread are the spread constraints of the pod, computed on the first check.
+	topologySpreadOnce sync.Once
+	topologySpread     []*spreadConstraint
+
+	// CPUManagerConfigs are the kubelet CPU manager settings per node, exclusive cores are
+	// only checked on the nodes found here.
+	CPUManagerConfigs map[string]*framework.CPUManagerConfig
+	// NodeTopologies are the NodeResourceTopology objects per node, NUMA alignment is only
+	// checked on the nodes found here.
+	NodeTopologies map[string]*framework.NodeTopology
 }
 
 func filterOutNode(nodeList *v1.NodeList) *v1.NodeList {
@@ -50,13 +79,28 @@ func filterOutNode(nodeList *v1.NodeList) *v1.NodeList {
 	}
 	return result
 }
-func NewAnalyzer(clientSet *kubernetes.Clientset, podNamespace, podName string) (*Analyzer, error) {
+func NewAnalyzer(clientSet kubernetes.Interface, podNamespace, podName string) (*Analyzer, error) {
 
 	pod, err := clientSet.CoreV1().Pods(podNamespace).Get(context.TODO(), podName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pod %s/%s: %w", podNamespace, podName, err)
 	}
 
+	snapshot, err := TakeSnapshot(clientSet)
+	if err != nil {
+		return nil, err
+	}
+
+	//allNodes = filterOutNode(allNodes)
+
+	return NewAnalyzerForPod(clientSet, pod, snapshot), nil
+
+}
+
+// NewAnalyzerForPod builds an analyzer for a pod that does not have to exist in the
+// cluster, e.g. a pod built from a workload template, against an already taken snapshot.
+func NewAnalyzerForPod(clientSet kubernetes.Interface, pod *v1.Pod, snapshot *Snapshot) *Analyzer {
+
 	cond := &Conditions{
 		NodeSelector:             pod.Spec.NodeSelector,
 		Affinity:                 pod.Spec.Affinity,
@@ -65,30 +109,42 @@ func NewAnalyzer(clientSet *kubernetes.Clientset, podNamespace, podName string)
 		PersistentVolumeAffinity: framework.BuildPVAffinity(clientSet, pod),
 	}
 
-	allPods, err := clientSet.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %w", err)
-	}
-
-	allNodes, err := clientSet.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list nodes: %w", err)
-	}
-
-	//allNodes = filterOutNode(allNodes)
-
-	interPodAffinityPlugin := interpodaffinity.NewInterPodAffinityFilter(clientSet, allPods.Items, allNodes.Items)
-
 	return &Analyzer{
 		ClientSet:              clientSet,
 		targetPod:              pod,
-		Namespace:              podNamespace,
-		PodName:                podName,
+		Namespace:              pod.Namespace,
+		PodName:                pod.Name,
 		TargetConditions:       cond,
-		allNodes:               allNodes.Items,
-		interPodAffinityPlugin: interPodAffinityPlugin,
-	}, nil
+		allNodes:               snapshot.Nodes,
+		allPods:                snapshot.Pods,
+		nodeResources:          snapshot.NodeResources,
+		interPodAffinityPlugin: snapshot.interPodAffinityPlugin,
+	}
+}
+
+// LoadCPUManagerConfigs reads the CPU manager settings of every node through configz,
+// nodes whose kubelet cannot be reached are reported and left out.
+func (a *Analyzer) LoadCPUManagerConfigs() {
+	a.CPUManagerConfigs = make(map[string]*framework.CPUManagerConfig, len(a.allNodes))
+	for _, node := range a.allNodes {
+		config, err := framework.GetCPUManagerConfig(a.ClientSet, node.Name)
+		if err != nil {
+			fmt.Printf("%v, exclusive cpus are not checked\n", err)
+			continue
+		}
+		a.CPUManagerConfigs[node.Name] = config
+	}
+}
 
+// LoadNodeTopologies reads the NodeResourceTopology objects, nothing is loaded when the CRD
+// is not installed.
+func (a *Analyzer) LoadNodeTopologies(dynamicClient dynamic.Interface) error {
+	topologies, err := framework.ListNodeTopologies(dynamicClient)
+	if err != nil {
+		return err
+	}
+	a.NodeTopologies = topologies
+	return nil
 }
 
 func (a *Analyzer) Why() error {
@@ -138,27 +194,51 @@ func (a *Analyzer) DiagnoseNode(node *v1.Node) *Report {
 		ResourceReason:         a.checkResource(node),
 		PodAffinityReason:      a.checkPodAffinity(node),
 		NodeAffinityReason:     a.checkNodeAffinity(node),
+		TopologySpreadReason:   a.checkTopologySpread(node),
+		TopologyManagerReason:  a.checkTopologyManager(node),
 	}
 }
 
 type CheckTask struct {
+	name      string
 	checkFunc func() util.ColorTextList // 检查函数
 	result    *util.ColorTextList       // 结果存储指针
 }
 
+func (a *Analyzer) checkTasks(node *v1.Node, report *Report) []CheckTask {
+	return []CheckTask{
+		{name: CheckUnschedulable, checkFunc: func() util.ColorTextList { return a.checkUnSchedulableNode(node) }, result: &report.NodeUnschedulable},
+		{name: CheckNodeSelector, checkFunc: func() util.ColorTextList { return a.checkNodeSelector(node.Labels) }, result: &report.NodeSelectorReason},
+		{name: CheckToleration, checkFunc: func() util.ColorTextList { return a.checkTaints(node.Spec.Taints) }, result: &report.TolerationReason},
+		{name: CheckPV, checkFunc: func() util.ColorTextList { return a.checkVolumeNodeAffinity(node.Labels) }, result: &report.PersistentVolumeReason},
+		{name: CheckResource, checkFunc: func() util.ColorTextList { return a.checkResource(node) }, result: &report.ResourceReason},
+		{name: CheckPodAffinity, checkFunc: func() util.ColorTextList { return a.checkPodAffinity(node) }, result: &report.PodAffinityReason},
+		{name: CheckNodeAffinity, checkFunc: func() util.ColorTextList { return a.checkNodeAffinity(node) }, result: &report.NodeAffinityReason},
+		{name: CheckTopologySpread, checkFunc: func() util.ColorTextList { return a.checkTopologySpread(node) }, result: &report.TopologySpreadReason},
+		{name: CheckTopologyManager, checkFunc: func() util.ColorTextList { return a.checkTopologyManager(node) }, result: &report.TopologyManagerReason},
+	}
+}
+
+// DiagnoseNodeChecks runs only the named checks one after another, the columns of the
+// other checks are left empty. It is meant for callers looping over many pods and nodes.
+func (a *Analyzer) DiagnoseNodeChecks(node *v1.Node, checks ...string) *Report {
+	report := &Report{NodeName: strings.Split(node.Name, "-")[0]}
+	for _, task := range a.checkTasks(node, report) {
+		for _, check := range checks {
+			if task.name == check {
+				*task.result = task.checkFunc()
+				break
+			}
+		}
+	}
+	return report
+}
+
 func (a *Analyzer) DiagnoseNodeMulti(node *v1.Node) *Report {
 	report := &Report{NodeName: strings.Split(node.Name, "-")[0]}
 
 	// 定义所有检查任务
-	tasks := []CheckTask{
-		{checkFunc: func() util.ColorTextList { return a.checkUnSchedulableNode(node) }, result: &report.NodeUnschedulable},
-		{checkFunc: func() util.ColorTextList { return a.checkNodeSelector(node.Labels) }, result: &report.NodeSelectorReason},
-		{checkFunc: func() util.ColorTextList { return a.checkTaints(node.Spec.Taints) }, result: &report.TolerationReason},
-		{checkFunc: func() util.ColorTextList { return a.checkVolumeNodeAffinity(node.Labels) }, result: &report.PersistentVolumeReason},
-		{checkFunc: func() util.ColorTextList { return a.checkResource(node) }, result: &report.ResourceReason},
-		{checkFunc: func() util.ColorTextList { return a.checkPodAffinity(node) }, result: &report.PodAffinityReason},
-		{checkFunc: func() util.ColorTextList { return a.checkNodeAffinity(node) }, result: &report.NodeAffinityReason},
-	}
+	tasks := a.checkTasks(node, report)
 	// 创建带缓冲的任务通道（避免阻塞）
 	taskChan := make(chan CheckTask, len(tasks))
 	var wg sync.WaitGroup
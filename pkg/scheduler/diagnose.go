@@ -6,6 +6,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	componenthelpers "k8s.io/component-helpers/scheduling/corev1"
 
 	"github.com/ops-tool/pkg/scheduler/framework"
@@ -21,7 +23,8 @@ func (a *Analyzer) checkNodeSelector(nodeLabels map[string]string) util.ColorTex
 		return nil
 	}
 
-	for k, v := range selector {
+	for _, k := range sets.List(sets.KeySet(selector)) {
+		v := selector[k]
 		nodeV, ok := nodeLabels[k]
 		if !ok || nodeV != v {
 			notMeetSelector = append(notMeetSelector, strings.Join([]string{k, v}, ":"))
@@ -118,7 +121,7 @@ func (a *Analyzer) checkNodeAffinity(node *corev1.Node) util.ColorTextList {
 		return nil
 	}
 
-	tmpNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: node.Labels}}
+	tmpNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: node.Name, Labels: node.Labels}}
 
 	matches, err := componenthelpers.MatchNodeSelectorTerms(tmpNode, nodeAffinityRequired)
 	if err != nil {
@@ -126,8 +129,11 @@ func (a *Analyzer) checkNodeAffinity(node *corev1.Node) util.ColorTextList {
 			util.NewRedText(err.Error()),
 		}
 	}
-	target := nodeAffinityRequired.NodeSelectorTerms[0].MatchExpressions[0]
-	toSave := fmt.Sprintf("%s %s %s", target.Key, target.Operator, target.Values[0])
+	toSave := "required node affinity"
+	if expressions := nodeAffinityRequired.NodeSelectorTerms[0].MatchExpressions; len(expressions) > 0 {
+		target := expressions[0]
+		toSave = fmt.Sprintf("%s %s %s", target.Key, target.Operator, strings.Join(target.Values, ","))
+	}
 	if matches {
 		return util.ColorTextList{
 			util.NewGreenText(toSave),
@@ -197,7 +203,8 @@ func (a *Analyzer) doCheckResource(want, have framework.ResourceList) util.Color
 	//fmt.Printf("checking resource...\n")
 	var notMeetResource, meetResource []string
 
-	for k, v := range want {
+	for _, k := range sets.List(sets.KeySet(want)) {
+		v := want[k]
 		reason := ""
 		if h, ok := have[k]; !ok {
 			reason = fmt.Sprintf("%s: want %s, have 0", k, v)
@@ -229,18 +236,176 @@ func (a *Analyzer) doCheckResource(want, have framework.ResourceList) util.Color
 
 func (a *Analyzer) checkResource(node *corev1.Node) util.ColorTextList {
 	want := a.TargetConditions.ResourceRequirement
-	have, err := framework.BuildAllocatedResourceMap(a.ClientSet, node)
-
-	if err != nil {
-		return util.ColorTextList{
-			util.NewRedText(fmt.Sprintf("cannot build node allocated resource")),
+	have, ok := a.nodeResources[node.Name]
+	if !ok {
+		var err error
+		have, err = framework.BuildAllocatedResourceMap(a.ClientSet, node)
+		if err != nil {
+			return util.ColorTextList{
+				util.NewRedText(fmt.Sprintf("cannot build node allocated resource")),
+			}
 		}
 	}
 
-	return a.doCheckResource(want, have)
+	result := a.doCheckResource(want, have)
+	result.MergeList(a.checkExclusiveCPUs(node))
+	return result
 
 }
 
+// checkExclusiveCPUs tells whether the static CPU manager of the node can pin the integer
+// cpus of a Guaranteed pod, the milli cpu sum may fit while too few whole cores are free.
+func (a *Analyzer) checkExclusiveCPUs(node *corev1.Node) util.ColorTextList {
+	config, ok := a.CPUManagerConfigs[node.Name]
+	if !ok || config.Policy != framework.CPUManagerPolicyStatic || a.targetPod == nil {
+		return nil
+	}
+	want := framework.ExclusiveCPUs(a.targetPod)
+	if want == 0 {
+		return nil
+	}
+	pools := framework.NewCPUPools(node, a.allPods, config)
+	toSave := fmt.Sprintf("exclusive cpus: want %d, have %d free", want, pools.FreeExclusive())
+	if want > pools.FreeExclusive() {
+		return util.ColorTextList{util.NewRedText(toSave)}
+	}
+	return util.ColorTextList{util.NewGreenText(toSave)}
+}
+
 func (a *Analyzer) checkPodAffinity(node *corev1.Node) util.ColorTextList {
 	return a.interPodAffinityPlugin.Filter(a.targetPod, node)
 }
+
+// checkTopologySpread evaluates the DoNotSchedule topology spread constraints of the pod the
+// way the PodTopologySpread plugin does with its default policies: only nodes matching the
+// pod's nodeSelector/nodeAffinity form domains, taints are ignored.
+func (a *Analyzer) checkTopologySpread(node *corev1.Node) util.ColorTextList {
+	if a.targetPod == nil {
+		return nil
+	}
+
+	var notMeetConstraints, meetConstraints []string
+	for _, spread := range a.spreadConstraints() {
+		constraint := spread.constraint
+		if spread.err != nil {
+			notMeetConstraints = append(notMeetConstraints, fmt.Sprintf("%s: invalid selector: %s", constraint.TopologyKey, spread.err))
+			continue
+		}
+
+		nodeDomain, ok := node.Labels[constraint.TopologyKey]
+		if !ok {
+			notMeetConstraints = append(notMeetConstraints, fmt.Sprintf("node has no label %s", constraint.TopologyKey))
+			continue
+		}
+
+		skew := spread.counts[nodeDomain] + spread.selfMatch - spread.minCount
+		toSave := fmt.Sprintf("%s=%s: skew %d, maxSkew %d", constraint.TopologyKey, nodeDomain, skew, constraint.MaxSkew)
+		if skew > constraint.MaxSkew {
+			notMeetConstraints = append(notMeetConstraints, toSave)
+		} else {
+			meetConstraints = append(meetConstraints, toSave)
+		}
+	}
+
+	result := util.ColorTextList{}
+	result.MergeList(util.StringListToColorTextList(meetConstraints, "green"))
+	result.MergeList(util.StringListToColorTextList(notMeetConstraints, "red"))
+	return result
+}
+
+// spreadConstraint is a DoNotSchedule constraint of the target pod with the matching pods
+// counted per domain, it does not depend on the node being checked.
+type spreadConstraint struct {
+	constraint corev1.TopologySpreadConstraint
+	err        error
+	counts     map[string]int32
+	minCount   int32
+	selfMatch  int32
+}
+
+// spreadConstraints computes the domains of every constraint once per analyzer, instead of
+// once per checked node.
+func (a *Analyzer) spreadConstraints() []*spreadConstraint {
+	a.topologySpreadOnce.Do(func() {
+		for _, constraint := range a.targetPod.Spec.TopologySpreadConstraints {
+			if constraint.WhenUnsatisfiable != corev1.DoNotSchedule {
+				continue
+			}
+			a.topologySpread = append(a.topologySpread, a.buildSpreadConstraint(constraint))
+		}
+	})
+	return a.topologySpread
+}
+
+func (a *Analyzer) buildSpreadConstraint(constraint corev1.TopologySpreadConstraint) *spreadConstraint {
+	spread := &spreadConstraint{constraint: constraint}
+	selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+	if err != nil {
+		spread.err = err
+		return spread
+	}
+	if len(constraint.MatchLabelKeys) > 0 {
+		matchLabels := labels.Set{}
+		for _, key := range constraint.MatchLabelKeys {
+			if value, ok := a.targetPod.Labels[key]; ok {
+				matchLabels[key] = value
+			}
+		}
+		requirements, _ := labels.SelectorFromSet(matchLabels).Requirements()
+		selector = selector.Add(requirements...)
+	}
+
+	domainOfNode := make(map[string]string)
+	spread.counts = make(map[string]int32)
+	for i := range a.allNodes {
+		n := &a.allNodes[i]
+		domain, ok := n.Labels[constraint.TopologyKey]
+		if !ok || a.checkNodeSelector(n.Labels).HasRed() || a.checkNodeAffinity(n).HasRed() {
+			continue
+		}
+		domainOfNode[n.Name] = domain
+		spread.counts[domain] += 0
+	}
+	for _, pod := range a.allPods {
+		domain, ok := domainOfNode[pod.Spec.NodeName]
+		if !ok || pod.Namespace != a.targetPod.Namespace || pod.DeletionTimestamp != nil ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			spread.counts[domain]++
+		}
+	}
+
+	spread.minCount = -1
+	for _, count := range spread.counts {
+		if spread.minCount < 0 || count < spread.minCount {
+			spread.minCount = count
+		}
+	}
+	if spread.minCount < 0 || (constraint.MinDomains != nil && int32(len(spread.counts)) < *constraint.MinDomains) {
+		spread.minCount = 0
+	}
+
+	if selector.Matches(labels.Set(a.targetPod.Labels)) {
+		spread.selfMatch = 1
+	}
+	return spread
+}
+
+// checkTopologyManager tells whether the kubelet topology manager of the node admits the pod
+// under the single-numa-node policy, from the available resources of every NUMA zone.
+func (a *Analyzer) checkTopologyManager(node *corev1.Node) util.ColorTextList {
+	topology, ok := a.NodeTopologies[node.Name]
+	if !ok || topology.Policy != framework.TopologyPolicySingleNUMANode || a.targetPod == nil {
+		return nil
+	}
+	zones, err := topology.FitSingleNUMA(a.targetPod)
+	if err != nil {
+		return util.ColorTextList{util.NewRedText(fmt.Sprintf("%s scope: %s", topology.Scope, err))}
+	}
+	if len(zones) == 0 {
+		return nil
+	}
+	return util.ColorTextList{util.NewGreenText(fmt.Sprintf("%s scope: fits %s", topology.Scope, strings.Join(zones, ",")))}
+}
@@ -3,6 +3,7 @@ package scheduler
 import (
 	"github.com/ops-tool/pkg/scheduler/framework"
 	"github.com/ops-tool/pkg/scheduler/framework/interpodaffinity"
+	"github.com/ops-tool/pkg/scheduler/schedulertest"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -16,7 +17,6 @@ func TestAnalyzer_checkUnSchedulableNode(t *testing.T) {
 		Namespace              string
 		PodName                string
 		TargetConditions       *Conditions
-		NodeReport             NodeReport
 		allNodes               []corev1.Node
 		interPodAffinityPlugin *interpodaffinity.InterPodAffinity
 	}
@@ -43,7 +43,7 @@ func TestAnalyzer_checkUnSchedulableNode(t *testing.T) {
 					},
 				},
 			},
-			want: "node unSchedulable",
+			want: "pod not tolerate unschedulable",
 		},
 		{
 			name: "pod tolerate Unschedulable Node",
@@ -124,7 +124,7 @@ func TestAnalyzer_checkUnSchedulableNode(t *testing.T) {
 					},
 				},
 			},
-			want: "node unSchedulable",
+			want: "pod not tolerate unschedulable",
 		},
 		{
 			name: "pod don't have unschedulable Key",
@@ -144,7 +144,7 @@ func TestAnalyzer_checkUnSchedulableNode(t *testing.T) {
 					},
 				},
 			},
-			want: "node unSchedulable",
+			want: "pod not tolerate unschedulable",
 		},
 	}
 	for _, tt := range tests {
@@ -158,7 +158,7 @@ func TestAnalyzer_checkUnSchedulableNode(t *testing.T) {
 				allNodes:               tt.fields.allNodes,
 				interPodAffinityPlugin: tt.fields.interPodAffinityPlugin,
 			}
-			if got := a.checkUnSchedulableNode(tt.args.node); got != tt.want {
+			if got := failedReasons(a.checkUnSchedulableNode(tt.args.node)); got != tt.want {
 				t.Errorf("checkUnSchedulableNode() = %v, want %v", got, tt.want)
 			}
 		})
@@ -172,7 +172,6 @@ func TestAnalyzer_checkNodeSelector(t *testing.T) {
 		Namespace              string
 		PodName                string
 		TargetConditions       *Conditions
-		NodeReport             NodeReport
 		allNodes               []corev1.Node
 		interPodAffinityPlugin *interpodaffinity.InterPodAffinity
 	}
@@ -256,7 +255,7 @@ func TestAnalyzer_checkNodeSelector(t *testing.T) {
 				allNodes:               tt.fields.allNodes,
 				interPodAffinityPlugin: tt.fields.interPodAffinityPlugin,
 			}
-			if got := a.checkNodeSelector(tt.args.nodeLabels); got != tt.want {
+			if got := failedReasons(a.checkNodeSelector(tt.args.nodeLabels)); got != tt.want {
 				t.Errorf("checkNodeSelector() = %v, want %v", got, tt.want)
 			}
 		})
@@ -283,7 +282,6 @@ func TestAnalyzer_checkTaints(t *testing.T) {
 		Namespace              string
 		PodName                string
 		TargetConditions       *Conditions
-		NodeReport             NodeReport
 		allNodes               []corev1.Node
 		interPodAffinityPlugin *interpodaffinity.InterPodAffinity
 	}
@@ -338,7 +336,7 @@ func TestAnalyzer_checkTaints(t *testing.T) {
 					{Key: "disk", Value: "ssd", Effect: corev1.TaintEffectNoSchedule},
 				},
 			},
-			want: "{\n\t\"key\": \"disk\",\n\t\"value\": \"ssd\",\n\t\"effect\": \"NoSchedule\"\n}",
+			want: "disk,ssd,NoSchedule",
 		},
 		{
 			name: "部分容忍",
@@ -353,7 +351,7 @@ func TestAnalyzer_checkTaints(t *testing.T) {
 					{Key: "gpu", Value: "nvidia", Effect: corev1.TaintEffectNoExecute}, // 未容忍
 				},
 			},
-			want: "{\n\t\"key\": \"gpu\",\n\t\"value\": \"nvidia\",\n\t\"effect\": \"NoExecute\"\n}",
+			want: "gpu,nvidia,NoExecute",
 		},
 		{
 			name: "全容忍",
@@ -390,7 +388,7 @@ func TestAnalyzer_checkTaints(t *testing.T) {
 					{Key: "special", Value: "false", Effect: corev1.TaintEffectPreferNoSchedule}, // 值不匹配
 				},
 			},
-			want: "{\n\t\"key\": \"special\",\n\t\"value\": \"false\",\n\t\"effect\": \"PreferNoSchedule\"\n}",
+			want: "special,false,PreferNoSchedule",
 		},
 		{
 			name: "Effect 不匹配",
@@ -406,7 +404,7 @@ func TestAnalyzer_checkTaints(t *testing.T) {
 					{Key: "network", Value: "unstable", Effect: corev1.TaintEffectNoExecute}, // Effect 不同
 				},
 			},
-			want: "{\n\t\"key\": \"network\",\n\t\"value\": \"unstable\",\n\t\"effect\": \"NoExecute\"\n}",
+			want: "network,unstable,NoExecute",
 		},
 	}
 	for _, tt := range tests {
@@ -420,7 +418,7 @@ func TestAnalyzer_checkTaints(t *testing.T) {
 				allNodes:               tt.fields.allNodes,
 				interPodAffinityPlugin: tt.fields.interPodAffinityPlugin,
 			}
-			if got := a.checkTaints(tt.args.taints); got != tt.want {
+			if got := failedReasons(a.checkTaints(tt.args.taints)); got != tt.want {
 				t.Errorf("checkTaints() = %v, want %v", got, tt.want)
 			}
 		})
@@ -434,7 +432,6 @@ func TestAnalyzer_checkVolumeNodeAffinity(t *testing.T) {
 		Namespace              string
 		PodName                string
 		TargetConditions       *Conditions
-		NodeReport             NodeReport
 		allNodes               []corev1.Node
 		interPodAffinityPlugin *interpodaffinity.InterPodAffinity
 	}
@@ -463,7 +460,7 @@ func TestAnalyzer_checkVolumeNodeAffinity(t *testing.T) {
 			name: "empty",
 			fields: fields{
 				TargetConditions: &Conditions{
-					PersistentVolumeAffinity: []*corev1.VolumeNodeAffinity{},
+					PersistentVolumeAffinity: []*framework.PVCStatus{},
 				},
 			},
 			args: args{
@@ -475,9 +472,11 @@ func TestAnalyzer_checkVolumeNodeAffinity(t *testing.T) {
 			name: "standard",
 			fields: fields{
 				TargetConditions: &Conditions{
-					PersistentVolumeAffinity: []*corev1.VolumeNodeAffinity{
+					PersistentVolumeAffinity: []*framework.PVCStatus{
 						{
-							Required: &corev1.NodeSelector{
+							Name:   "data",
+							PVName: "pv-data",
+							PVVolumeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
 								NodeSelectorTerms: []corev1.NodeSelectorTerm{
 									{
 										MatchExpressions: []corev1.NodeSelectorRequirement{
@@ -489,7 +488,7 @@ func TestAnalyzer_checkVolumeNodeAffinity(t *testing.T) {
 										},
 									},
 								},
-							},
+							}},
 						},
 					},
 				},
@@ -505,9 +504,11 @@ func TestAnalyzer_checkVolumeNodeAffinity(t *testing.T) {
 			name: "standard not meet",
 			fields: fields{
 				TargetConditions: &Conditions{
-					PersistentVolumeAffinity: []*corev1.VolumeNodeAffinity{
+					PersistentVolumeAffinity: []*framework.PVCStatus{
 						{
-							Required: &corev1.NodeSelector{
+							Name:   "data",
+							PVName: "pv-data",
+							PVVolumeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
 								NodeSelectorTerms: []corev1.NodeSelectorTerm{
 									{
 										MatchExpressions: []corev1.NodeSelectorRequirement{
@@ -519,7 +520,7 @@ func TestAnalyzer_checkVolumeNodeAffinity(t *testing.T) {
 										},
 									},
 								},
-							},
+							}},
 						},
 					},
 				},
@@ -529,7 +530,7 @@ func TestAnalyzer_checkVolumeNodeAffinity(t *testing.T) {
 					"kubernetes.io/hostname": "base2-xakd.dev6.abcstackint.com",
 				},
 			},
-			want: "{\n\t\"nodeSelectorTerms\": [\n\t\t{\n\t\t\t\"matchExpressions\": [\n\t\t\t\t{\n\t\t\t\t\t\"key\": \"kubernetes.io/hostname\",\n\t\t\t\t\t\"operator\": \"In\",\n\t\t\t\t\t\"values\": [\n\t\t\t\t\t\t\"base1-xakd.dev6.abcstackint.com\"\n\t\t\t\t\t]\n\t\t\t\t}\n\t\t\t]\n\t\t}\n\t]\n}",
+			want: "pvc data's pv in base1-xakd.dev6.abcstackint.com",
 		},
 	}
 	for _, tt := range tests {
@@ -543,7 +544,7 @@ func TestAnalyzer_checkVolumeNodeAffinity(t *testing.T) {
 				allNodes:               tt.fields.allNodes,
 				interPodAffinityPlugin: tt.fields.interPodAffinityPlugin,
 			}
-			if got := a.checkVolumeNodeAffinity(tt.args.nodeLabels); got != tt.want {
+			if got := failedReasons(a.checkVolumeNodeAffinity(tt.args.nodeLabels)); got != tt.want {
 				t.Errorf("checkVolumeNodeAffinity() = %v, want %v", got, tt.want)
 			}
 		})
@@ -557,7 +558,6 @@ func TestAnalyzer_doCheckResource(t *testing.T) {
 		Namespace              string
 		PodName                string
 		TargetConditions       *Conditions
-		NodeReport             NodeReport
 		allNodes               []corev1.Node
 		interPodAffinityPlugin *interpodaffinity.InterPodAffinity
 	}
@@ -595,6 +595,7 @@ func TestAnalyzer_doCheckResource(t *testing.T) {
 						Name:     "cpu",
 						Requests: 2,
 						Capacity: 130,
+						Left:     128,
 					},
 				},
 			},
@@ -615,10 +616,11 @@ func TestAnalyzer_doCheckResource(t *testing.T) {
 						Name:     "cpu",
 						Requests: 2,
 						Capacity: 100,
+						Left:     98,
 					},
 				},
 			},
-			want: "cpu: want 123, have 98 left",
+			want: "cpu: want 123m, have 98m left",
 		},
 		{
 			name:   "standard not exist",
@@ -632,7 +634,7 @@ func TestAnalyzer_doCheckResource(t *testing.T) {
 				},
 				have: framework.ResourceList{},
 			},
-			want: "cpu: want 123, have 0",
+			want: "cpu: want 123m, have 0",
 		},
 		{
 			name:   "standard not meet multiple",
@@ -643,9 +645,9 @@ func TestAnalyzer_doCheckResource(t *testing.T) {
 						Name:     "cpu",
 						Requests: 123,
 					},
-					"netdevice": &framework.Resource{
-						Name:     "netdevice",
-						Requests: 2,
+					"cloudbed.abcstack.com/mlnx_numa0_netdevice": &framework.Resource{
+						Name:     "cloudbed.abcstack.com/mlnx_numa0_netdevice",
+						Requests: 2000,
 					},
 				},
 				have: framework.ResourceList{
@@ -653,15 +655,17 @@ func TestAnalyzer_doCheckResource(t *testing.T) {
 						Name:     "cpu",
 						Requests: 123,
 						Capacity: 130,
+						Left:     7,
 					},
-					"netdevice": &framework.Resource{
-						Name:     "netdevice",
-						Requests: 1,
-						Capacity: 2,
+					"cloudbed.abcstack.com/mlnx_numa0_netdevice": &framework.Resource{
+						Name:     "cloudbed.abcstack.com/mlnx_numa0_netdevice",
+						Requests: 1000,
+						Capacity: 2000,
+						Left:     1000,
 					},
 				},
 			},
-			want: "cpu: want 123, have 7 left\nnetdevice: want 2, have 1 left",
+			want: "cloudbed.abcstack.com/mlnx_numa0_netdevice: want 2, have 1 left\ncpu: want 123m, have 7m left",
 		},
 	}
 	for _, tt := range tests {
@@ -675,7 +679,7 @@ func TestAnalyzer_doCheckResource(t *testing.T) {
 				allNodes:               tt.fields.allNodes,
 				interPodAffinityPlugin: tt.fields.interPodAffinityPlugin,
 			}
-			if got := a.doCheckResource(tt.args.want, tt.args.have); got != tt.want {
+			if got := failedReasons(a.doCheckResource(tt.args.want, tt.args.have)); got != tt.want {
 				t.Errorf("doCheckResource() = %v, want %v", got, tt.want)
 			}
 		})
@@ -689,7 +693,6 @@ func TestAnalyzer_checkNodeAffinity(t *testing.T) {
 		Namespace              string
 		PodName                string
 		TargetConditions       *Conditions
-		NodeReport             NodeReport
 		allNodes               []corev1.Node
 		interPodAffinityPlugin *interpodaffinity.InterPodAffinity
 	}
@@ -804,7 +807,7 @@ func TestAnalyzer_checkNodeAffinity(t *testing.T) {
 					},
 				},
 			},
-			want: "don't match node affinity: {\"nodeSelectorTerms\":[{\"matchExpressions\":[{\"key\":\"topology.kubernetes.io/subnet\",\"operator\":\"In\",\"values\":[\"podcidr-10-10-84-0-mask-22\"]}]}]}",
+			want: "topology.kubernetes.io/subnet In podcidr-10-10-84-0-mask-22",
 		},
 	}
 	for _, tt := range tests {
@@ -818,9 +821,175 @@ func TestAnalyzer_checkNodeAffinity(t *testing.T) {
 				allNodes:               tt.fields.allNodes,
 				interPodAffinityPlugin: tt.fields.interPodAffinityPlugin,
 			}
-			if got := a.checkNodeAffinity(tt.args.node); got != tt.want {
+			if got := failedReasons(a.checkNodeAffinity(tt.args.node)); got != tt.want {
 				t.Errorf("checkNodeAffinity() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestAnalyzer_checkTopologySpread(t *testing.T) {
+	web := map[string]string{"app": "web"}
+	nodes := []corev1.Node{
+		schedulertest.Node("node1", map[string]string{"pool": "a", corev1.LabelTopologyZone: "z1"}, "4", "8Gi"),
+		schedulertest.Node("node2", map[string]string{"pool": "a", corev1.LabelTopologyZone: "z2"}, "4", "8Gi"),
+		// not selected by the pod, so z3 is not a domain
+		schedulertest.Node("node3", map[string]string{"pool": "b", corev1.LabelTopologyZone: "z3"}, "4", "8Gi"),
+		schedulertest.Node("node4", map[string]string{"pool": "a"}, "4", "8Gi"),
+	}
+	pods := []corev1.Pod{
+		schedulertest.Pod("web-0", "node1", web, "100m", "128Mi"),
+		schedulertest.Pod("web-1", "node1", web, "100m", "128Mi"),
+		schedulertest.Pod("web-2", "node2", web, "100m", "128Mi"),
+		schedulertest.Pod("db-0", "node2", map[string]string{"app": "db"}, "100m", "128Mi"),
+	}
+	pod := schedulertest.Pod("web-3", "", web, "100m", "128Mi")
+	pod.Spec.NodeSelector = map[string]string{"pool": "a"}
+	pod.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       corev1.LabelTopologyZone,
+		WhenUnsatisfiable: corev1.DoNotSchedule,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: web},
+	}}
+	a := NewAnalyzerForPod(nil, &pod, NewSnapshot(schedulertest.ClientSet(), nodes, pods))
+
+	type args struct {
+		node *corev1.Node
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "domain above min",
+			args: args{node: &nodes[0]},
+			want: "topology.kubernetes.io/zone=z1: skew 2, maxSkew 1",
+		},
+		{
+			name: "domain at min",
+			args: args{node: &nodes[1]},
+			want: "",
+		},
+		{
+			name: "node without topology key",
+			args: args{node: &nodes[3]},
+			want: "node has no label topology.kubernetes.io/zone",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failedReasons(a.checkTopologySpread(tt.args.node)); got != tt.want {
+				t.Errorf("checkTopologySpread() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_checkExclusiveCPUs(t *testing.T) {
+	nodes := []corev1.Node{
+		schedulertest.Node("node1", nil, "8", "32Gi"),
+		schedulertest.Node("node2", nil, "8", "32Gi"),
+	}
+	pods := []corev1.Pod{schedulertest.Pod("pinned", "node1", nil, "4", "8Gi")}
+	configs := map[string]*framework.CPUManagerConfig{
+		"node1": {Policy: framework.CPUManagerPolicyStatic, ReservedCPUs: 2},
+	}
+	burstable := schedulertest.Pod("burstable", "", nil, "3", "1Gi")
+	burstable.Spec.Containers[0].Resources.Limits = nil
+
+	type args struct {
+		pod  corev1.Pod
+		node *corev1.Node
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "enough free cores",
+			args: args{pod: schedulertest.Pod("web", "", nil, "2", "1Gi"), node: &nodes[0]},
+			want: "",
+		},
+		{
+			name: "reserved and pinned cores are not free",
+			args: args{pod: schedulertest.Pod("web", "", nil, "3", "1Gi"), node: &nodes[0]},
+			want: "exclusive cpus: want 3, have 2 free",
+		},
+		{
+			name: "burstable pod is not pinned",
+			args: args{pod: burstable, node: &nodes[0]},
+			want: "",
+		},
+		{
+			name: "node without configz",
+			args: args{pod: schedulertest.Pod("web", "", nil, "3", "1Gi"), node: &nodes[1]},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAnalyzerForPod(nil, &tt.args.pod, NewSnapshot(schedulertest.ClientSet(), nodes, pods))
+			a.CPUManagerConfigs = configs
+			if got := failedReasons(a.checkExclusiveCPUs(tt.args.node)); got != tt.want {
+				t.Errorf("checkExclusiveCPUs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_checkTopologyManager(t *testing.T) {
+	zone := func(name string, cpu, memory int64) *framework.NUMAZone {
+		return &framework.NUMAZone{Name: name, Resources: map[string]*framework.ZoneResource{
+			"cpu":    {Available: cpu},
+			"memory": {Available: memory},
+		}}
+	}
+	nodes := []corev1.Node{
+		schedulertest.Node("node1", nil, "8", "32Gi"),
+		schedulertest.Node("node2", nil, "8", "32Gi"),
+	}
+	gi := int64(1024 * 1024 * 1024 * 1000)
+	topologies := map[string]*framework.NodeTopology{
+		"node1": {NodeName: "node1", Policy: framework.TopologyPolicySingleNUMANode, Scope: framework.TopologyScopeContainer,
+			Zones: []*framework.NUMAZone{zone("node-0", 4000, 8*gi), zone("node-1", 2000, 8*gi)}},
+		"node2": {NodeName: "node2", Policy: "best-effort", Scope: framework.TopologyScopeContainer,
+			Zones: []*framework.NUMAZone{zone("node-0", 1000, gi)}},
+	}
+
+	type args struct {
+		pod  corev1.Pod
+		node *corev1.Node
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "fits a zone",
+			args: args{pod: schedulertest.Pod("web", "", nil, "3", "1Gi"), node: &nodes[0]},
+			want: "",
+		},
+		{
+			name: "free on the node but not in one zone",
+			args: args{pod: schedulertest.Pod("web", "", nil, "5", "1Gi"), node: &nodes[0]},
+			want: "container scope: no NUMA zone has cpu 5000m, memory 1.0Gi available",
+		},
+		{
+			name: "policy without alignment",
+			args: args{pod: schedulertest.Pod("web", "", nil, "5", "1Gi"), node: &nodes[1]},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAnalyzerForPod(nil, &tt.args.pod, NewSnapshot(schedulertest.ClientSet(), nodes, nil))
+			a.NodeTopologies = topologies
+			if got := failedReasons(a.checkTopologyManager(tt.args.node)); got != tt.want {
+				t.Errorf("checkTopologyManager() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"github.com/ops-tool/pkg/util"
+	"github.com/ops-tool/pkg/workloads"
+)
+
+// FitsOnAnalyzer runs the analyzer checks in reverse: every pending pod, and optionally every
+// workload template, against a single node.
+type FitsOnAnalyzer struct {
+	ClientSet kubernetes.Interface
+
+	NodeName  string
+	Namespace string
+	Templates bool
+
+	// what-if changes applied to the node before checking
+	Uncordon     bool
+	SetLabels    map[string]string
+	RemoveTaints []string
+}
+
+type FitsOnResult struct {
+	Kind   string
+	Pod    *v1.Pod
+	Report *Report
+}
+
+func (r *FitsOnResult) ToStringList() []string {
+	result := util.NewGreenText("Feasible")
+	if !r.Report.Feasible() {
+		result = util.NewRedText("NotFeasible")
+	}
+	return []string{
+		r.Kind,
+		fmt.Sprintf("%s/%s", r.Pod.Namespace, r.Pod.Name),
+		result.String(),
+		strings.Join(r.Report.FailedChecks(), "\n"),
+		r.Report.FailedReasons().String(),
+	}
+}
+
+// whatIfNode returns a copy of node with the requested label, taint and cordon changes.
+func (f *FitsOnAnalyzer) whatIfNode(node *v1.Node) *v1.Node {
+	node = node.DeepCopy()
+	if f.Uncordon {
+		node.Spec.Unschedulable = false
+	}
+	if len(f.SetLabels) > 0 && node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+	for k, v := range f.SetLabels {
+		node.Labels[k] = v
+	}
+	var taints []v1.Taint
+	for _, taint := range node.Spec.Taints {
+		removed := false
+		for _, key := range f.RemoveTaints {
+			if taint.Key == key {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			taints = append(taints, taint)
+		}
+	}
+	node.Spec.Taints = taints
+	return node
+}
+
+// whatIfSnapshot applies the what-if changes to the node before the snapshot is built, so
+// that the inter pod affinity plugin counts the pods of the node under its new labels too.
+func (f *FitsOnAnalyzer) whatIfSnapshot(nodes []v1.Node, pods []v1.Pod) (*Snapshot, *v1.Node, error) {
+	for i := range nodes {
+		if nodes[i].Name == f.NodeName {
+			nodes[i] = *f.whatIfNode(&nodes[i])
+			snapshot := NewSnapshot(f.ClientSet, nodes, pods)
+			return snapshot, &snapshot.Nodes[i], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("node %s not found", f.NodeName)
+}
+
+func (f *FitsOnAnalyzer) FitsOn() error {
+
+	allPods, err := framework.ListAllPods(f.ClientSet, v1.NamespaceAll, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	allNodes, err := framework.ListAllNodes(f.ClientSet, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	snapshot, node, err := f.whatIfSnapshot(allNodes, allPods)
+	if err != nil {
+		return err
+	}
+
+	type candidate struct {
+		kind string
+		pod  *v1.Pod
+	}
+	var candidates []candidate
+	for i := range snapshot.Pods {
+		pod := &snapshot.Pods[i]
+		if pod.Spec.NodeName == "" && pod.Status.Phase == v1.PodPending && (f.Namespace == "" || pod.Namespace == f.Namespace) {
+			candidates = append(candidates, candidate{kind: "Pod", pod: pod})
+		}
+	}
+	if f.Templates {
+		templates, err := f.listTemplates()
+		if err != nil {
+			return err
+		}
+		for kind, pods := range templates {
+			for _, pod := range pods {
+				candidates = append(candidates, candidate{kind: kind, pod: pod})
+			}
+		}
+	}
+
+	var results []*FitsOnResult
+	for _, c := range candidates {
+		analyzer := NewAnalyzerForPod(f.ClientSet, c.pod, snapshot)
+		results = append(results, &FitsOnResult{Kind: c.kind, Pod: c.pod, Report: analyzer.DiagnoseNodeChecks(node, ReportHeader[1:]...)})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Report.Feasible() != results[j].Report.Feasible() {
+			return results[i].Report.Feasible()
+		}
+		if results[i].Kind != results[j].Kind {
+			return results[i].Kind < results[j].Kind
+		}
+		return results[i].Pod.Namespace+"/"+results[i].Pod.Name < results[j].Pod.Namespace+"/"+results[j].Pod.Name
+	})
+	printFitsOn(results)
+
+	feasible := 0
+	for _, r := range results {
+		if r.Report.Feasible() {
+			feasible++
+		}
+	}
+	fmt.Printf("\n%d of %d candidates fit on node %s\n", feasible, len(results), f.NodeName)
+	return nil
+}
+
+func (f *FitsOnAnalyzer) listTemplates() (map[string][]*v1.Pod, error) {
+	templates := map[string][]*v1.Pod{}
+
+	deployments, err := f.ClientSet.AppsV1().Deployments(f.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, deploy := range deployments.Items {
+		templates["Deployment"] = append(templates["Deployment"], workloads.PodFromTemplate(deploy.Namespace, deploy.Name, &deploy.Spec.Template))
+	}
+
+	statefulSets, err := f.ClientSet.AppsV1().StatefulSets(f.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, sts := range statefulSets.Items {
+		templates["StatefulSet"] = append(templates["StatefulSet"], workloads.PodFromTemplate(sts.Namespace, sts.Name, &sts.Spec.Template))
+	}
+
+	return templates, nil
+}
+
+func printFitsOn(results []*FitsOnResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Kind", "Namespace/Name", "Result", "FailedChecks", "Reason"})
+	for _, r := range results {
+		t.AppendRow(util.ListToRow(r.ToStringList()))
+	}
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ops-tool/pkg/scheduler/schedulertest"
+)
+
+func TestFitsOnAnalyzer_whatIfSnapshot(t *testing.T) {
+	db := map[string]string{"app": "db"}
+	pod := schedulertest.Pod("web-0", "", map[string]string{"app": "web"}, "100m", "128Mi")
+	pod.Spec.Affinity = &v1.Affinity{PodAffinity: &v1.PodAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: db},
+			TopologyKey:   "rack",
+		}},
+	}}
+
+	tests := []struct {
+		name      string
+		setLabels map[string]string
+		want      bool
+	}{
+		{
+			name: "node without the topology key",
+			want: false,
+		},
+		{
+			name:      "label set by the what-if",
+			setLabels: map[string]string{"rack": "r1"},
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := []v1.Node{schedulertest.Node("node1", nil, "4", "8Gi")}
+			pods := []v1.Pod{schedulertest.Pod("db-0", "node1", db, "100m", "128Mi")}
+			f := &FitsOnAnalyzer{ClientSet: schedulertest.ClientSet(), NodeName: "node1", SetLabels: tt.setLabels}
+
+			snapshot, node, err := f.whatIfSnapshot(nodes, pods)
+			if err != nil {
+				t.Fatalf("whatIfSnapshot() error = %v", err)
+			}
+			report := NewAnalyzerForPod(f.ClientSet, &pod, snapshot).DiagnoseNodeChecks(node, ReportHeader[1:]...)
+			if got := report.Feasible(); got != tt.want {
+				t.Errorf("Feasible() = %v, want %v, failed %v", got, tt.want, report.FailedReasons())
+			}
+		})
+	}
+
+	f := &FitsOnAnalyzer{ClientSet: schedulertest.ClientSet(), NodeName: "missing"}
+	if _, _, err := f.whatIfSnapshot([]v1.Node{schedulertest.Node("node1", nil, "4", "8Gi")}, nil); err == nil {
+		t.Errorf("whatIfSnapshot() of a missing node should fail")
+	}
+}
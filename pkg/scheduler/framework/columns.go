@@ -0,0 +1,169 @@
+package framework
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Units a resource column can be formatted with. Quantities are kept in milli units, as in Resource.
+const (
+	UnitMilli = "m"
+	UnitCount = "count"
+	UnitKi    = "Ki"
+	UnitMi    = "Mi"
+	UnitGi    = "Gi"
+	UnitTi    = "Ti"
+)
+
+var binaryUnits = map[string]float64{
+	UnitKi: 1024,
+	UnitMi: 1024 * 1024,
+	UnitGi: 1024 * 1024 * 1024,
+	UnitTi: 1024 * 1024 * 1024 * 1024,
+}
+
+// ResourceColumn is one resource shown in the node report.
+type ResourceColumn struct {
+	Name string `json:"name"`
+	// Alias is the column header, the name by default.
+	Alias string `json:"alias,omitempty"`
+	// Unit is one of m, count, Ki, Mi, Gi, Ti. The default depends on the resource, see defaultUnit.
+	Unit string `json:"unit,omitempty"`
+}
+
+func (c ResourceColumn) Header() string {
+	if c.Alias != "" {
+		return c.Alias
+	}
+	return c.Name
+}
+
+// ResourceProfile sets which resources the node report shows and how.
+type ResourceProfile struct {
+	Resources []ResourceColumn `json:"resources"`
+	// DiscoverExtended adds a column for every extended resource found in a node's allocatable
+	// that is not listed in Resources.
+	DiscoverExtended bool `json:"discoverExtended"`
+}
+
+// DefaultProfile is the set of columns shown without a config file.
+var DefaultProfile = &ResourceProfile{
+	Resources: []ResourceColumn{
+		{Name: "cpu", Unit: UnitMilli},
+		{Name: "memory", Unit: UnitGi},
+		{Name: "ephemeral-storage", Unit: UnitGi},
+		{Name: "hugepages-1Gi", Unit: UnitGi},
+		{Name: "hugepages-2Mi", Unit: UnitGi},
+		{Name: "cloudbed.abcstack.com/mlnx_numa0_netdevice", Alias: "mlnx_numa0_netdevice", Unit: UnitCount},
+		{Name: "cloudbed.abcstack.com/mlnx_numa1_netdevice", Alias: "mlnx_numa1_netdevice", Unit: UnitCount},
+		{Name: "cloudbed.abcstack.com/hdd-passthrough", Alias: "hdd-passthrough", Unit: UnitCount},
+		{Name: "cloudbed.abcstack.com/ssd-passthrough", Alias: "ssd-passthrough", Unit: UnitCount},
+	},
+	DiscoverExtended: true,
+}
+
+// activeProfile is used by Resource.String to format quantities.
+var activeProfile = DefaultProfile
+
+// SetProfile makes the profile used to build and format the node report.
+func SetProfile(profile *ResourceProfile) {
+	activeProfile = profile
+}
+
+// LoadProfile reads a resource profile from a YAML or JSON file.
+func LoadProfile(path string) (*ResourceProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// a file without discoverExtended keeps the default, the columns are always the listed ones
+	profile := &ResourceProfile{DiscoverExtended: DefaultProfile.DiscoverExtended}
+	if err := yaml.UnmarshalStrict(data, profile); err != nil {
+		return nil, fmt.Errorf("invalid resource profile %s: %w", path, err)
+	}
+	for _, column := range profile.Resources {
+		if column.Name == "" {
+			return nil, fmt.Errorf("invalid resource profile %s: resource name is required", path)
+		}
+		if _, ok := binaryUnits[column.Unit]; !ok && column.Unit != "" && column.Unit != UnitMilli && column.Unit != UnitCount {
+			return nil, fmt.Errorf("invalid resource profile %s: unknown unit %q of %s", path, column.Unit, column.Name)
+		}
+	}
+	return profile, nil
+}
+
+// Columns returns the configured columns followed by the extended resources discovered on nodes.
+func (p *ResourceProfile) Columns(nodes []*Node) []ResourceColumn {
+	columns := append([]ResourceColumn{}, p.Resources...)
+	if !p.DiscoverExtended {
+		return columns
+	}
+
+	known := map[string]bool{}
+	for _, column := range columns {
+		known[column.Name] = true
+	}
+	var discovered []string
+	for _, node := range nodes {
+		for name := range node.Status.Allocatable {
+			if !known[name.String()] && IsExtendedResource(name) {
+				known[name.String()] = true
+				discovered = append(discovered, name.String())
+			}
+		}
+	}
+	sort.Strings(discovered)
+	for _, name := range discovered {
+		columns = append(columns, ResourceColumn{Name: name, Alias: name[strings.LastIndex(name, "/")+1:], Unit: UnitCount})
+	}
+	return columns
+}
+
+func (p *ResourceProfile) unit(name string) string {
+	for _, column := range p.Resources {
+		if column.Name == name && column.Unit != "" {
+			return column.Unit
+		}
+	}
+	return defaultUnit(name)
+}
+
+// IsExtendedResource reports whether name is a resource outside the kubernetes.io domain, like a
+// device plugin resource.
+func IsExtendedResource(name v1.ResourceName) bool {
+	s := name.String()
+	return strings.Contains(s, "/") && !strings.Contains(s, v1.ResourceDefaultNamespacePrefix) && !strings.HasPrefix(s, "requests.")
+}
+
+func defaultUnit(name string) string {
+	switch {
+	case name == string(v1.ResourceCPU):
+		return UnitMilli
+	case name == string(v1.ResourceMemory) || name == string(v1.ResourceEphemeralStorage) || strings.HasPrefix(name, v1.ResourceHugePagesPrefix):
+		return UnitGi
+	}
+	return UnitCount
+}
+
+// FormatMilli formats a milli quantity in unit.
+func FormatMilli(milli int64, unit string) string {
+	switch unit {
+	case UnitMilli:
+		return fmt.Sprintf("%dm", milli)
+	case UnitCount:
+		if milli%1000 == 0 || math.Abs(float64(milli)) >= 100*1000 {
+			return fmt.Sprintf("%d", milli/1000)
+		}
+		return fmt.Sprintf("%.1f", float64(milli)/1000)
+	}
+	if divisor, ok := binaryUnits[unit]; ok {
+		return fmt.Sprintf("%.1f%s", float64(milli)/(1000*divisor), unit)
+	}
+	return fmt.Sprintf("%dm", milli)
+}
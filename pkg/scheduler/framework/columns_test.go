@@ -0,0 +1,106 @@
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestResourceProfile_Columns(t *testing.T) {
+	node := &Node{}
+	node.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:                          resource.MustParse("8"),
+		"nvidia.com/gpu":                        resource.MustParse("4"),
+		"kubernetes.io/batch-cpu":               resource.MustParse("1"),
+		"cloudbed.abcstack.com/hdd-passthrough": resource.MustParse("2"),
+	}
+	profile := &ResourceProfile{
+		Resources:        []ResourceColumn{{Name: "cpu"}, {Name: "cloudbed.abcstack.com/hdd-passthrough", Alias: "hdd"}},
+		DiscoverExtended: true,
+	}
+
+	columns := profile.Columns([]*Node{node})
+	want := []string{"cpu", "hdd", "gpu"}
+	if len(columns) != len(want) {
+		t.Fatalf("Columns() = %v, want headers %v", columns, want)
+	}
+	for i, column := range columns {
+		if column.Header() != want[i] {
+			t.Errorf("Columns()[%d] = %s, want %s", i, column.Header(), want[i])
+		}
+	}
+
+	profile.DiscoverExtended = false
+	if columns := profile.Columns([]*Node{node}); len(columns) != 2 {
+		t.Errorf("Columns() without discovery = %v, want 2 columns", columns)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           string
+		wantColumns      int
+		discoverExtended bool
+		wantErr          bool
+	}{
+		{
+			name:             "discovery on by default",
+			config:           "resources:\n- name: cpu\n  unit: m\n- name: memory\n  unit: Gi\n",
+			wantColumns:      2,
+			discoverExtended: true,
+		},
+		{
+			name:             "discovery off",
+			config:           "discoverExtended: false\nresources:\n- name: cpu\n",
+			wantColumns:      1,
+			discoverExtended: false,
+		},
+		{
+			name:    "unknown unit",
+			config:  "resources:\n- name: cpu\n  unit: cores\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profile.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			profile, err := LoadProfile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(profile.Resources) != tt.wantColumns || profile.DiscoverExtended != tt.discoverExtended {
+				t.Errorf("LoadProfile() = %d columns, discoverExtended %v, want %d, %v",
+					len(profile.Resources), profile.DiscoverExtended, tt.wantColumns, tt.discoverExtended)
+			}
+		})
+	}
+}
+
+func TestFormatMilli(t *testing.T) {
+	tests := []struct {
+		milli int64
+		unit  string
+		want  string
+	}{
+		{milli: 1500, unit: UnitMilli, want: "1500m"},
+		{milli: 4000, unit: UnitCount, want: "4"},
+		{milli: 1500, unit: UnitCount, want: "1.5"},
+		{milli: 2 * 1024 * 1024 * 1024 * 1000, unit: UnitGi, want: "2.0Gi"},
+		{milli: 512 * 1024 * 1024 * 1000, unit: UnitMi, want: "512.0Mi"},
+	}
+	for _, tt := range tests {
+		if got := FormatMilli(tt.milli, tt.unit); got != tt.want {
+			t.Errorf("FormatMilli(%d, %s) = %s, want %s", tt.milli, tt.unit, got, tt.want)
+		}
+	}
+}
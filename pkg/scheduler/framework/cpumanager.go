@@ -0,0 +1,239 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/util/qos"
+	"k8s.io/utils/cpuset"
+
+	"github.com/ops-tool/pkg/util"
+)
+
+const CPUManagerPolicyStatic = "static"
+
+// CPUManagerConfig is the part of the kubelet configuration deciding exclusive cores.
+type CPUManagerConfig struct {
+	Policy string
+	// ReservedCPUs is the number of cores kept in the shared pool for the system.
+	ReservedCPUs int64
+}
+
+// kubeletConfigz is the body of the node proxy configz endpoint.
+type kubeletConfigz struct {
+	KubeletConfig struct {
+		CPUManagerPolicy   string            `json:"cpuManagerPolicy"`
+		ReservedSystemCPUs string            `json:"reservedSystemCPUs"`
+		KubeReserved       map[string]string `json:"kubeReserved"`
+		SystemReserved     map[string]string `json:"systemReserved"`
+	} `json:"kubeletconfig"`
+}
+
+// GetCPUManagerConfig reads the CPU manager policy and the reserved CPUs of the kubelet
+// through the apiserver node proxy.
+func GetCPUManagerConfig(clientset kubernetes.Interface, nodeName string) (*CPUManagerConfig, error) {
+	body, err := clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").Name(nodeName).SubResource("proxy").Suffix("configz").
+		DoRaw(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configz of node %s: %w", nodeName, err)
+	}
+	return parseConfigz(body)
+}
+
+func parseConfigz(body []byte) (*CPUManagerConfig, error) {
+	configz := &kubeletConfigz{}
+	if err := json.Unmarshal(body, configz); err != nil {
+		return nil, fmt.Errorf("failed to decode configz: %w", err)
+	}
+	kc := configz.KubeletConfig
+
+	config := &CPUManagerConfig{Policy: kc.CPUManagerPolicy}
+	if config.Policy == "" {
+		config.Policy = "none"
+	}
+	if kc.ReservedSystemCPUs != "" {
+		reserved, err := cpuset.Parse(kc.ReservedSystemCPUs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reservedSystemCPUs %q: %w", kc.ReservedSystemCPUs, err)
+		}
+		config.ReservedCPUs = int64(reserved.Size())
+		return config, nil
+	}
+	// the static policy reserves whole cores for kube-reserved plus system-reserved
+	var reservedMilli int64
+	for _, reserved := range []map[string]string{kc.KubeReserved, kc.SystemReserved} {
+		if cpu, ok := reserved[string(v1.ResourceCPU)]; ok {
+			quantity, err := resource.ParseQuantity(cpu)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reserved cpu %q: %w", cpu, err)
+			}
+			reservedMilli += quantity.MilliValue()
+		}
+	}
+	config.ReservedCPUs = ceilCores(reservedMilli)
+	return config, nil
+}
+
+func ceilCores(milli int64) int64 {
+	return (milli + 999) / 1000
+}
+
+// integerCPUs returns the cpu request of the container when it is a whole number of cores.
+func integerCPUs(container *v1.Container) int64 {
+	request, ok := container.Resources.Requests[v1.ResourceCPU]
+	if !ok || request.MilliValue()%1000 != 0 {
+		return 0
+	}
+	return request.MilliValue() / 1000
+}
+
+// ExclusiveCPUs returns the cores the static CPU manager has to find free to admit the pod:
+// only Guaranteed pods get exclusive cores, for their containers with an integer cpu request.
+// Init containers run one at a time and give their cores back to the app containers.
+func ExclusiveCPUs(pod *v1.Pod) int64 {
+	if qos.GetPodQOS(pod) != v1.PodQOSGuaranteed {
+		return 0
+	}
+	var init, app int64
+	for i := range pod.Spec.InitContainers {
+		init = max(init, integerCPUs(&pod.Spec.InitContainers[i]))
+	}
+	for i := range pod.Spec.Containers {
+		app += integerCPUs(&pod.Spec.Containers[i])
+	}
+	return max(init, app)
+}
+
+// pinnedCPUs returns the cores held by the running containers of the pod.
+func pinnedCPUs(pod *v1.Pod) int64 {
+	if qos.GetPodQOS(pod) != v1.PodQOSGuaranteed {
+		return 0
+	}
+	var pinned int64
+	for i := range pod.Spec.Containers {
+		pinned += integerCPUs(&pod.Spec.Containers[i])
+	}
+	return pinned
+}
+
+// CPUPools splits the cores of a node into the ones pinned to containers and the shared pool.
+type CPUPools struct {
+	NodeName string
+	// Policy is the kubelet CPU manager policy, empty when configz was not read.
+	Policy string
+	CPUs   int64
+	// Reserved is estimated from capacity minus allocatable when configz was not read.
+	Reserved   int64
+	Pinned     int64
+	PinnedPods []string
+	// SharedRequests is the milli cpu requested by the containers running in the shared pool.
+	SharedRequests int64
+}
+
+// NewCPUPools accounts the pods bound to the node, config may be nil.
+func NewCPUPools(node *v1.Node, pods []v1.Pod, config *CPUManagerConfig) *CPUPools {
+	capacity := node.Status.Capacity[v1.ResourceCPU]
+	allocatable := node.Status.Allocatable[v1.ResourceCPU]
+	pools := &CPUPools{
+		NodeName: node.Name,
+		CPUs:     capacity.Value(),
+		Reserved: ceilCores(capacity.MilliValue() - allocatable.MilliValue()),
+	}
+	if config != nil {
+		pools.Policy = config.Policy
+		pools.Reserved = config.ReservedCPUs
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName != node.Name || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		pinned := int64(0)
+		if pools.Policy == "" || pools.Policy == CPUManagerPolicyStatic {
+			pinned = pinnedCPUs(pod)
+		}
+		if pinned > 0 {
+			pools.Pinned += pinned
+			pools.PinnedPods = append(pools.PinnedPods, fmt.Sprintf("%s/%s: %d", pod.Namespace, pod.Name, pinned))
+		}
+		for j := range pod.Spec.Containers {
+			container := &pod.Spec.Containers[j]
+			if pinned > 0 && integerCPUs(container) > 0 {
+				continue
+			}
+			request := container.Resources.Requests[v1.ResourceCPU]
+			pools.SharedRequests += request.MilliValue()
+		}
+	}
+	return pools
+}
+
+// SharedPool is the number of cores not pinned to any container, reserved cores included.
+func (p *CPUPools) SharedPool() int64 {
+	return p.CPUs - p.Pinned
+}
+
+// FreeExclusive is the number of cores that can still be pinned, reserved cores never are.
+func (p *CPUPools) FreeExclusive() int64 {
+	return max(p.CPUs-p.Reserved-p.Pinned, 0)
+}
+
+// CPUPools builds the CPU pools of the node from the pods of the node report.
+func (nr *Node) CPUPools(config *CPUManagerConfig) *CPUPools {
+	pods := make([]v1.Pod, 0, len(nr.Pods))
+	for _, podInfo := range nr.Pods {
+		pods = append(pods, *podInfo.Pod)
+	}
+	return NewCPUPools(&nr.Node, pods, config)
+}
+
+// PrintCPUPools prints the exclusive and shared cores per node. Nodes missing from configs
+// are assumed to run the static policy, their reserved cores are estimated.
+func PrintCPUPools(nodeList []*Node, configs map[string]*CPUManagerConfig) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"nodeName", "policy", "cpus", "reserved", "pinned", "free exclusive", "shared pool", "shared requests", "pinned pods"})
+
+	sorted := append([]*Node{}, nodeList...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, n := range sorted {
+		pools := n.CPUPools(configs[n.Name])
+		policy, reserved := pools.Policy, fmt.Sprintf("%d", pools.Reserved)
+		if policy == "" {
+			policy, reserved = "-", fmt.Sprintf("~%d", pools.Reserved)
+		}
+
+		free := util.NewGreenText(fmt.Sprintf("%d", pools.FreeExclusive()))
+		if pools.FreeExclusive() == 0 {
+			free = util.NewRedText(free.Text)
+		}
+		shared := util.ColorText{Text: fmt.Sprintf("%dm", pools.SharedRequests)}
+		if pools.SharedRequests > pools.SharedPool()*1000 {
+			shared = util.NewRedText(shared.Text)
+		}
+		pinnedPods := "-"
+		if len(pools.PinnedPods) > 0 {
+			pinnedPods = strings.Join(pools.PinnedPods, "\n")
+		}
+
+		t.AppendRow(table.Row{n.Name, policy, pools.CPUs, reserved, pools.Pinned, free.String(),
+			pools.SharedPool(), shared.String(), pinnedPods})
+	}
+
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
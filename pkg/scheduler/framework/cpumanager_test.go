@@ -0,0 +1,60 @@
+package framework
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCPUPools(t *testing.T) {
+	newPod := func(name, cpu, limit string) v1.Pod {
+		container := v1.Container{Name: "c", Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse("1Gi")},
+			Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse(limit), v1.ResourceMemory: resource.MustParse("1Gi")},
+		}}
+		pod := v1.Pod{Spec: v1.PodSpec{NodeName: "node1", Containers: []v1.Container{container}}}
+		pod.Name, pod.Namespace = name, "default"
+		return pod
+	}
+	node := &v1.Node{Status: v1.NodeStatus{
+		Capacity:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("16")},
+		Allocatable: v1.ResourceList{v1.ResourceCPU: resource.MustParse("14500m")},
+	}}
+	node.Name = "node1"
+	pods := []v1.Pod{
+		newPod("pinned", "8", "8"),
+		newPod("fractional", "1500m", "1500m"),
+		newPod("burstable", "2", "4"),
+	}
+
+	pools := NewCPUPools(node, pods, nil)
+	if pools.Reserved != 2 || pools.Pinned != 8 || pools.FreeExclusive() != 6 || pools.SharedPool() != 8 || pools.SharedRequests != 3500 {
+		t.Errorf("NewCPUPools() = %+v, free %d", pools, pools.FreeExclusive())
+	}
+
+	guaranteed := newPod("new", "7", "7")
+	if want := ExclusiveCPUs(&guaranteed); want != 7 || want <= pools.FreeExclusive() {
+		t.Errorf("ExclusiveCPUs() = %d, want 7 more than the %d free cores", want, pools.FreeExclusive())
+	}
+	burstable := newPod("burst", "7", "8")
+	if want := ExclusiveCPUs(&burstable); want != 0 {
+		t.Errorf("ExclusiveCPUs() of a burstable pod = %d, want 0", want)
+	}
+
+	pools = NewCPUPools(node, pods, &CPUManagerConfig{Policy: "none", ReservedCPUs: 1})
+	if pools.Pinned != 0 || pools.SharedRequests != 11500 {
+		t.Errorf("NewCPUPools() with policy none = %+v", pools)
+	}
+}
+
+func TestParseConfigz(t *testing.T) {
+	config, err := parseConfigz([]byte(`{"kubeletconfig":{"cpuManagerPolicy":"static","reservedSystemCPUs":"0-1,8"}}`))
+	if err != nil || config.Policy != CPUManagerPolicyStatic || config.ReservedCPUs != 3 {
+		t.Errorf("parseConfigz() = %+v, %v", config, err)
+	}
+	config, err = parseConfigz([]byte(`{"kubeletconfig":{"kubeReserved":{"cpu":"500m"},"systemReserved":{"cpu":"1"}}}`))
+	if err != nil || config.Policy != "none" || config.ReservedCPUs != 2 {
+		t.Errorf("parseConfigz() = %+v, %v", config, err)
+	}
+}
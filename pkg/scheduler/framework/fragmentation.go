@@ -0,0 +1,217 @@
+package framework
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/ops-tool/pkg/util"
+)
+
+// Shape is a standard pod size counted by the fragmentation view.
+type Shape struct {
+	Name     string
+	Requests ResourceList
+}
+
+// DefaultShapes are the shapes counted when none is configured.
+var DefaultShapes = []string{
+	"1c2g=cpu:1,memory:2Gi",
+	"2c8g=cpu:2,memory:8Gi",
+	"4c16g=cpu:4,memory:16Gi",
+	"8c32g=cpu:8,memory:32Gi",
+	"16c64g=cpu:16,memory:64Gi",
+}
+
+// ParseShape parses "name=resource:quantity,resource:quantity".
+func ParseShape(spec string) (*Shape, error) {
+	name, requests, ok := strings.Cut(spec, "=")
+	if !ok || name == "" || requests == "" {
+		return nil, fmt.Errorf("invalid shape %q, using format name=resource:quantity,...", spec)
+	}
+	shape := &Shape{Name: name, Requests: ResourceList{}}
+	for _, request := range strings.Split(requests, ",") {
+		resourceName, value, ok := strings.Cut(request, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid shape %q, using format name=resource:quantity,...", spec)
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q in shape %s: %w", value, name, err)
+		}
+		shape.Requests[resourceName] = &Resource{Name: resourceName, Requests: quantity.MilliValue()}
+	}
+	// every pod takes a pod slot of the node
+	if _, ok := shape.Requests["pods"]; !ok {
+		shape.Requests["pods"] = &Resource{Name: "pods", Requests: 1000}
+	}
+	return shape, nil
+}
+
+func ParseShapes(specs []string) ([]*Shape, error) {
+	var shapes []*Shape
+	for _, spec := range specs {
+		shape, err := ParseShape(spec)
+		if err != nil {
+			return nil, err
+		}
+		shapes = append(shapes, shape)
+	}
+	return shapes, nil
+}
+
+// ShapeFit is how many pods of a shape fit in the cluster as it is, and how many would fit if
+// all free resources were on a single node.
+type ShapeFit struct {
+	Shape *Shape
+	Fit   int64
+	Ideal int64
+}
+
+// Fragmentation is the share of the ideal fit lost because free resources are spread over nodes.
+func (sf *ShapeFit) Fragmentation() float64 {
+	if sf.Ideal <= 0 {
+		return 0
+	}
+	return float64(sf.Ideal-sf.Fit) / float64(sf.Ideal) * 100
+}
+
+// schedulable returns the nodes new pods can land on.
+func schedulable(nodeList []*Node) []*Node {
+	var result []*Node
+	for _, n := range nodeList {
+		if !n.Spec.Unschedulable {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// FitShapes counts the fit of each shape on the schedulable nodes.
+func FitShapes(nodeList []*Node, shapes []*Shape) []*ShapeFit {
+	nodes := schedulable(nodeList)
+	free := ResourceList{}
+	for _, n := range nodes {
+		for name, r := range n.AllocatedResourceMap {
+			if _, ok := free[name]; !ok {
+				free[name] = &Resource{Name: name}
+			}
+			if r.Left > 0 {
+				free[name].Left += r.Left
+			}
+		}
+	}
+
+	var result []*ShapeFit
+	for _, shape := range shapes {
+		sf := &ShapeFit{Shape: shape}
+		for _, n := range nodes {
+			fit, _ := n.AllocatedResourceMap.MaxFit(shape.Requests)
+			if fit != math.MaxInt64 {
+				sf.Fit += fit
+			}
+		}
+		sf.Ideal, _ = free.MaxFit(shape.Requests)
+		if sf.Ideal == math.MaxInt64 {
+			sf.Ideal = 0
+		}
+		result = append(result, sf)
+	}
+	return result
+}
+
+// FragmentationScore is the mean fragmentation of the shapes that would fit at all.
+func FragmentationScore(fits []*ShapeFit) float64 {
+	total, count := 0.0, 0
+	for _, sf := range fits {
+		if sf.Ideal > 0 {
+			total += sf.Fragmentation()
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// largestShape is what a single pod could still get on the node, per column resource.
+func (nr *Node) largestShape(columns []ResourceColumn) string {
+	if pods, ok := nr.AllocatedResourceMap["pods"]; ok && pods.Capacity > 0 && pods.Left <= 0 {
+		return util.NewRedText("no pod slot left").String()
+	}
+	var res []string
+	for _, column := range columns {
+		cur, ok := nr.AllocatedResourceMap[column.Name]
+		if !ok || cur.Capacity == 0 {
+			continue
+		}
+		left := &Resource{Name: column.Name, Requests: max(cur.Left, 0)}
+		res = append(res, fmt.Sprintf("%s %s", column.Header(), left.String()))
+	}
+	return strings.Join(res, "\n")
+}
+
+// PrintFragmentation prints the largest shape each node still accepts, how many pods of each
+// shape fit per node and in the cluster, and the fragmentation score.
+func PrintFragmentation(nodeList []*Node, shapes []*Shape) {
+	columns := activeProfile.Columns(nodeList)
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	header := []string{"nodeName", "largest pod"}
+	for _, shape := range shapes {
+		header = append(header, shape.Name)
+	}
+	t.AppendHeader(util.ListToRow(header))
+
+	sorted := append([]*Node{}, nodeList...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, n := range sorted {
+		name := n.Name
+		if n.Spec.Unschedulable {
+			name += " (cordoned)"
+		}
+		row := []string{name, n.largestShape(columns)}
+		for _, shape := range shapes {
+			fit, _ := n.AllocatedResourceMap.MaxFit(shape.Requests)
+			row = append(row, fmt.Sprintf("%d", fit))
+		}
+		t.AppendRow(util.ListToRow(row))
+	}
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+
+	fits := FitShapes(nodeList, shapes)
+	h := table.NewWriter()
+	h.SetOutputMirror(os.Stdout)
+	h.SetTitle("schedulable nodes")
+	h.AppendHeader(table.Row{"shape", "requests", "fit", "fit if defragmented", "fragmentation"})
+	for _, sf := range fits {
+		var requests []string
+		for name, r := range sf.Shape.Requests {
+			if name == "pods" {
+				continue
+			}
+			requests = append(requests, fmt.Sprintf("%s %s", name, r.String()))
+		}
+		sort.Strings(requests)
+		fragmentation := fmt.Sprintf("%.1f%%", sf.Fragmentation())
+		if sf.Fit == 0 && sf.Ideal > 0 {
+			fragmentation = util.NewRedText(fragmentation).String()
+		}
+		h.AppendRow(table.Row{sf.Shape.Name, strings.Join(requests, ","), sf.Fit, sf.Ideal, fragmentation})
+	}
+	h.SetStyle(style)
+	h.Render()
+	fmt.Printf("fragmentation score: %.1f%% (share of the pods that would fit if free resources were not spread over nodes)\n", FragmentationScore(fits))
+}
@@ -0,0 +1,46 @@
+package framework
+
+import "testing"
+
+func TestFitShapes(t *testing.T) {
+	newNode := func(name string, cpuLeft, memLeftGi int64) *Node {
+		n := &Node{Name: name, AllocatedResourceMap: ResourceList{
+			"cpu":    {Name: "cpu", Capacity: 16000, Left: cpuLeft},
+			"memory": {Name: "memory", Capacity: 64 * 1024 * 1024 * 1024 * 1000, Left: memLeftGi * 1024 * 1024 * 1024 * 1000},
+			"pods":   {Name: "pods", Capacity: 110000, Left: 100000},
+		}}
+		n.Node.Name = name
+		return n
+	}
+	// 12 cores free in total, but no node has 8
+	nodeList := []*Node{newNode("node1", 4000, 16), newNode("node2", 4000, 16), newNode("node3", 4000, 16)}
+	cordoned := newNode("node4", 16000, 64)
+	cordoned.Spec.Unschedulable = true
+	nodeList = append(nodeList, cordoned)
+
+	shapes, err := ParseShapes([]string{"4c8g=cpu:4,memory:8Gi", "8c16g=cpu:8,memory:16Gi"})
+	if err != nil {
+		t.Fatalf("ParseShapes() error = %v", err)
+	}
+	fits := FitShapes(nodeList, shapes)
+
+	want := []struct {
+		fit, ideal    int64
+		fragmentation float64
+	}{
+		{fit: 3, ideal: 3, fragmentation: 0},
+		{fit: 0, ideal: 1, fragmentation: 100},
+	}
+	for i, sf := range fits {
+		if sf.Fit != want[i].fit || sf.Ideal != want[i].ideal || sf.Fragmentation() != want[i].fragmentation {
+			t.Errorf("FitShapes() %s = fit %d ideal %d (%.1f%%), want %+v", sf.Shape.Name, sf.Fit, sf.Ideal, sf.Fragmentation(), want[i])
+		}
+	}
+	if score := FragmentationScore(fits); score != 50 {
+		t.Errorf("FragmentationScore() = %.1f, want 50", score)
+	}
+
+	if _, err := ParseShape("8c16g"); err == nil {
+		t.Errorf("ParseShape() without requests should fail")
+	}
+}
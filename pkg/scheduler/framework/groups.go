@@ -0,0 +1,159 @@
+package framework
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/ops-tool/pkg/util"
+)
+
+// NoGroup is the group of nodes without the group-by label.
+const NoGroup = "<none>"
+
+// NodeGroup aggregates the resources of the nodes sharing a label value.
+type NodeGroup struct {
+	Name     string
+	Nodes    int
+	Cordoned int
+	// Total holds the summed allocatable, requests, limits and usage of the nodes.
+	Total *Node
+}
+
+func newNodeGroup(name string) *NodeGroup {
+	return &NodeGroup{Name: name, Total: &Node{Name: name, AllocatedResourceMap: ResourceList{}}}
+}
+
+func (g *NodeGroup) add(n *Node) {
+	g.Nodes++
+	if n.Spec.Unschedulable {
+		g.Cordoned++
+	}
+	for name, r := range n.AllocatedResourceMap {
+		total, ok := g.Total.AllocatedResourceMap[name]
+		if !ok {
+			total = &Resource{Name: name}
+			g.Total.AllocatedResourceMap[name] = total
+		}
+		total.Requests += r.Requests
+		total.Limits += r.Limits
+		total.Capacity += r.Capacity
+		total.Left += r.Left
+		if total.Capacity > 0 {
+			total.RequestsFraction = float64(total.Requests) / float64(total.Capacity) * 100
+			total.LimitsFraction = float64(total.Limits) / float64(total.Capacity) * 100
+		}
+	}
+	if n.Usage != nil {
+		if g.Total.Usage == nil {
+			g.Total.Usage = v1.ResourceList{}
+		}
+		for name, quantity := range n.Usage {
+			total := g.Total.Usage[name]
+			total.Add(quantity)
+			g.Total.Usage[name] = total
+		}
+	}
+}
+
+func (g *NodeGroup) limitStrings() []string {
+	var res []string
+	for _, resourceName := range usageResourceNames {
+		cur, ok := g.Total.AllocatedResourceMap[resourceName]
+		if !ok || cur.Capacity == 0 {
+			res = append(res, "-")
+			continue
+		}
+		limits := &Resource{Name: resourceName, Requests: cur.Limits}
+		capacity := &Resource{Name: resourceName, Requests: cur.Capacity}
+		res = append(res, fmt.Sprintf("%s/%s(%d%%)", limits.String(), capacity.String(), int64(cur.LimitsFraction)))
+	}
+	return res
+}
+
+// GroupNodes aggregates the nodes by the value of label, groups are sorted by name.
+func GroupNodes(nodeList []*Node, label string) []*NodeGroup {
+	groups := map[string]*NodeGroup{}
+	for _, n := range nodeList {
+		name, ok := n.Labels[label]
+		if !ok {
+			name = NoGroup
+		}
+		if _, ok := groups[name]; !ok {
+			groups[name] = newNodeGroup(name)
+		}
+		groups[name].add(n)
+	}
+
+	var result []*NodeGroup
+	for _, g := range groups {
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// PrintNodeGroups prints one subtotal row per value of label and a cluster total row.
+func PrintNodeGroups(nodeList []*Node, label string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+
+	withUsage := false
+	for _, n := range nodeList {
+		if n.Usage != nil {
+			withUsage = true
+			break
+		}
+	}
+
+	columns := activeProfile.Columns(nodeList)
+	header := []string{label, "nodes", "cordoned"}
+	for _, column := range columns {
+		header = append(header, column.Header())
+	}
+	header = append(header, "cpu limits", "memory limits")
+	if withUsage {
+		header = append(header, "cpu usage", "memory usage", "requested but idle")
+	}
+	t.AppendHeader(util.ListToRow(header))
+
+	cluster := newNodeGroup("total")
+	row := func(g *NodeGroup) []string {
+		// the first cell of ResourceStrings is the node name
+		res := append([]string{g.Name, fmt.Sprintf("%d", g.Nodes), fmt.Sprintf("%d", g.Cordoned)}, g.Total.ResourceStrings(columns)[1:]...)
+		res = append(res, g.limitStrings()...)
+		if withUsage {
+			res = append(res, g.Total.UsageStrings()...)
+		}
+		return res
+	}
+	for _, g := range GroupNodes(nodeList, label) {
+		t.AppendRow(util.ListToRow(row(g)))
+	}
+	for _, n := range nodeList {
+		cluster.add(n)
+	}
+	t.AppendFooter(util.ListToRow(row(cluster)))
+
+	columnConfigs := make([]table.ColumnConfig, len(header))
+	for i := range header {
+		columnConfigs[i] = table.ColumnConfig{
+			Number:      i + 1,
+			Align:       text.AlignCenter,
+			AlignHeader: text.AlignCenter,
+			AlignFooter: text.AlignCenter,
+		}
+	}
+	t.SetColumnConfigs(columnConfigs)
+	style := table.StyleDefault
+	style.Format.Header = 0
+	style.Format.Footer = 0
+	t.SetStyle(style)
+	t.Render()
+}
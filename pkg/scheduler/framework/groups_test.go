@@ -0,0 +1,57 @@
+package framework
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGroupNodes(t *testing.T) {
+	newNode := func(name, zone string, cordoned bool, cpuRequests, cpuCapacity int64) *Node {
+		n := &Node{
+			Name: name,
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+				Spec:       v1.NodeSpec{Unschedulable: cordoned},
+			},
+			AllocatedResourceMap: ResourceList{
+				"cpu": {Name: "cpu", Requests: cpuRequests, Capacity: cpuCapacity, Left: cpuCapacity - cpuRequests},
+			},
+		}
+		if zone != "" {
+			n.Labels[v1.LabelTopologyZone] = zone
+		}
+		return n
+	}
+	nodeList := []*Node{
+		newNode("node1", "zone-b", false, 1000, 4000),
+		newNode("node2", "zone-a", true, 3000, 4000),
+		newNode("node3", "zone-b", true, 2000, 4000),
+		newNode("node4", "", false, 0, 4000),
+	}
+
+	groups := GroupNodes(nodeList, v1.LabelTopologyZone)
+	want := []struct {
+		name     string
+		nodes    int
+		cordoned int
+		requests int64
+		fraction float64
+	}{
+		{name: NoGroup, nodes: 1, cordoned: 0, requests: 0, fraction: 0},
+		{name: "zone-a", nodes: 1, cordoned: 1, requests: 3000, fraction: 75},
+		{name: "zone-b", nodes: 2, cordoned: 1, requests: 3000, fraction: 37.5},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("GroupNodes() returned %d groups, want %d", len(groups), len(want))
+	}
+	for i, g := range groups {
+		cpu := g.Total.AllocatedResourceMap["cpu"]
+		if g.Name != want[i].name || g.Nodes != want[i].nodes || g.Cordoned != want[i].cordoned ||
+			cpu.Requests != want[i].requests || cpu.RequestsFraction != want[i].fraction {
+			t.Errorf("GroupNodes()[%d] = %s nodes %d cordoned %d requests %d (%.1f%%), want %+v",
+				i, g.Name, g.Nodes, g.Cordoned, cpu.Requests, cpu.RequestsFraction, want[i])
+		}
+	}
+}
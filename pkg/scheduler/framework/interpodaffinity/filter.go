@@ -10,14 +10,20 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/workqueue"
+	"sync"
 	"sync/atomic"
 )
 
 type InterPodAffinity struct {
-	ClientSet                                    *kubernetes.Clientset
+	ClientSet                                    kubernetes.Interface
 	AllNodes                                     []*framework.Node
 	havePodsWithAffinityNodeInfoList             []*framework.Node
 	havePodsWithRequiredAntiAffinityNodeInfoList []*framework.Node
+
+	// preFilter of the last filtered pod, Filter is called once per node for the same pod.
+	mu          sync.Mutex
+	cachedPod   *v1.Pod
+	cachedState *preFilterState
 }
 
 func createNodeInfoMap(pods []v1.Pod, allnodes []v1.Node) map[string]*framework.Node {
@@ -40,14 +46,23 @@ func createNodeInfoMap(pods []v1.Pod, allnodes []v1.Node) map[string]*framework.
 	return nodeNameToInfo
 }
 
-func NewInterPodAffinityFilter(clientset *kubernetes.Clientset, allPods []v1.Pod, allNodes []v1.Node) *InterPodAffinity {
+func NewInterPodAffinityFilter(clientset kubernetes.Interface, allPods []v1.Pod, allNodes []v1.Node) *InterPodAffinity {
 
 	nodeInfoMap := createNodeInfoMap(allPods, allNodes)
 	nodeInfoList := make([]*framework.Node, 0, len(nodeInfoMap))
-	havePodsWithAffinityNodeInfoList := make([]*framework.Node, 0, len(nodeInfoMap))
-	havePodsWithRequiredAntiAffinityNodeInfoList := make([]*framework.Node, 0, len(nodeInfoMap))
 	for _, v := range nodeInfoMap {
 		nodeInfoList = append(nodeInfoList, v)
+	}
+	return NewInterPodAffinityFilterFromNodes(clientset, nodeInfoList)
+}
+
+// NewInterPodAffinityFilterFromNodes builds the filter from a node list that already holds
+// the pods of every node, e.g. the one built by framework.BuildNodeListFromPods.
+func NewInterPodAffinityFilterFromNodes(clientset kubernetes.Interface, nodeInfoList []*framework.Node) *InterPodAffinity {
+
+	havePodsWithAffinityNodeInfoList := make([]*framework.Node, 0, len(nodeInfoList))
+	havePodsWithRequiredAntiAffinityNodeInfoList := make([]*framework.Node, 0, len(nodeInfoList))
+	for _, v := range nodeInfoList {
 		if len(v.PodsWithAffinity) > 0 {
 			havePodsWithAffinityNodeInfoList = append(havePodsWithAffinityNodeInfoList, v)
 		}
@@ -63,6 +78,24 @@ func NewInterPodAffinityFilter(clientset *kubernetes.Clientset, allPods []v1.Pod
 	}
 }
 
+// AddPod adds a pod bound by a simulation to its node, so that later filtering takes it into account.
+func (pl *InterPodAffinity) AddPod(pod *v1.Pod) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	for _, nodeInfo := range pl.AllNodes {
+		if nodeInfo.Node.Name != pod.Spec.NodeName {
+			continue
+		}
+		hadRequiredAntiAffinity := len(nodeInfo.PodsWithRequiredAntiAffinity) > 0
+		nodeInfo.AddPod(pod)
+		if !hadRequiredAntiAffinity && len(nodeInfo.PodsWithRequiredAntiAffinity) > 0 {
+			pl.havePodsWithRequiredAntiAffinityNodeInfoList = append(pl.havePodsWithRequiredAntiAffinityNodeInfoList, nodeInfo)
+		}
+		break
+	}
+	pl.cachedPod, pl.cachedState = nil, nil
+}
+
 type topologyPair struct {
 	key   string
 	value string
@@ -326,9 +359,23 @@ func (pl *InterPodAffinity) preFilter(pod *v1.Pod) (*preFilterState, error) {
 	return s, nil
 }
 
+func (pl *InterPodAffinity) getPreFilterState(pod *v1.Pod) (*preFilterState, error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if pl.cachedPod == pod && pl.cachedState != nil {
+		return pl.cachedState, nil
+	}
+	state, err := pl.preFilter(pod)
+	if err != nil {
+		return nil, err
+	}
+	pl.cachedPod, pl.cachedState = pod, state
+	return state, nil
+}
+
 func (pl *InterPodAffinity) Filter(pod *v1.Pod, node *v1.Node) util.ColorTextList {
 	//fmt.Printf("checking pod affinity...\n")
-	state, err := pl.preFilter(pod)
+	state, err := pl.getPreFilterState(pod)
 	var result util.ColorTextList
 	if err != nil {
 		return util.ColorTextList{
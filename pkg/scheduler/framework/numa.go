@@ -0,0 +1,325 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/kubectl/pkg/util/qos"
+
+	"github.com/ops-tool/pkg/util"
+)
+
+const (
+	TopologyPolicySingleNUMANode = "single-numa-node"
+	TopologyScopePod             = "pod"
+	TopologyScopeContainer       = "container"
+)
+
+// nodeResourceTopologyVersions are the NodeResourceTopology API versions tried in order.
+var nodeResourceTopologyVersions = []string{"v1alpha2", "v1alpha1"}
+
+// v1alpha1 encodes policy and scope in a single value.
+var v1alpha1TopologyPolicies = map[string][2]string{
+	"SingleNUMANodeContainerLevel": {TopologyPolicySingleNUMANode, TopologyScopeContainer},
+	"SingleNUMANodePodLevel":       {TopologyPolicySingleNUMANode, TopologyScopePod},
+	"Restricted":                   {"restricted", TopologyScopeContainer},
+	"BestEffort":                   {"best-effort", TopologyScopeContainer},
+	"None":                         {"none", TopologyScopeContainer},
+}
+
+// ZoneResource is a resource of a NUMA zone, in milli units.
+type ZoneResource struct {
+	Capacity    int64
+	Allocatable int64
+	Available   int64
+}
+
+type NUMAZone struct {
+	Name      string
+	Resources map[string]*ZoneResource
+}
+
+// NodeTopology is the NodeResourceTopology object of a node.
+type NodeTopology struct {
+	NodeName string
+	Policy   string
+	Scope    string
+	Zones    []*NUMAZone
+}
+
+// ListNodeTopologies reads the NodeResourceTopology objects by node name, the map is empty
+// when the CRD is not installed.
+func ListNodeTopologies(dynamicClient dynamic.Interface) (map[string]*NodeTopology, error) {
+	result := make(map[string]*NodeTopology)
+	for _, version := range nodeResourceTopologyVersions {
+		gvr := schema.GroupVersionResource{Group: "topology.node.k8s.io", Version: version, Resource: "noderesourcetopologies"}
+		list, err := dynamicClient.Resource(gvr).List(context.TODO(), metav1.ListOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list noderesourcetopologies: %w", err)
+		}
+		for i := range list.Items {
+			topology, err := parseNodeTopology(&list.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			result[topology.NodeName] = topology
+		}
+		return result, nil
+	}
+	return result, nil
+}
+
+func parseNodeTopology(obj *unstructured.Unstructured) (*NodeTopology, error) {
+	topology := &NodeTopology{NodeName: obj.GetName(), Policy: "none", Scope: TopologyScopeContainer}
+
+	attributes, _, _ := unstructured.NestedSlice(obj.Object, "attributes")
+	for _, attribute := range attributes {
+		attr, _ := attribute.(map[string]interface{})
+		name, _ := attr["name"].(string)
+		value, _ := attr["value"].(string)
+		switch name {
+		case "topologyManagerPolicy":
+			topology.Policy = value
+		case "topologyManagerScope":
+			topology.Scope = value
+		}
+	}
+	policies, _, _ := unstructured.NestedStringSlice(obj.Object, "topologyPolicies")
+	if len(attributes) == 0 && len(policies) > 0 {
+		if policy, ok := v1alpha1TopologyPolicies[policies[0]]; ok {
+			topology.Policy, topology.Scope = policy[0], policy[1]
+		}
+	}
+
+	zones, _, _ := unstructured.NestedSlice(obj.Object, "zones")
+	for _, z := range zones {
+		zoneMap, _ := z.(map[string]interface{})
+		if zoneType, _ := zoneMap["type"].(string); zoneType != "" && zoneType != "Node" {
+			continue
+		}
+		zone := &NUMAZone{Resources: make(map[string]*ZoneResource)}
+		zone.Name, _ = zoneMap["name"].(string)
+		resources, _, _ := unstructured.NestedSlice(zoneMap, "resources")
+		for _, r := range resources {
+			resourceMap, _ := r.(map[string]interface{})
+			name, _ := resourceMap["name"].(string)
+			zr := &ZoneResource{}
+			for field, target := range map[string]*int64{"capacity": &zr.Capacity, "allocatable": &zr.Allocatable, "available": &zr.Available} {
+				value, ok := resourceMap[field]
+				if !ok {
+					continue
+				}
+				quantity, err := resource.ParseQuantity(fmt.Sprint(value))
+				if err != nil {
+					return nil, fmt.Errorf("noderesourcetopology %s: invalid %s of %s in zone %s: %w", topology.NodeName, field, name, zone.Name, err)
+				}
+				*target = quantity.MilliValue()
+			}
+			zone.Resources[name] = zr
+		}
+		topology.Zones = append(topology.Zones, zone)
+	}
+	sort.Slice(topology.Zones, func(i, j int) bool { return topology.Zones[i].Name < topology.Zones[j].Name })
+	return topology, nil
+}
+
+// alignedResources returns the requests of the pod the topology manager aligns: everything
+// the zones report for Guaranteed pods, only devices for the others. One entry per
+// container, init containers apart since their resources are reused by the app
+// containers, or a single app entry for the pod scope.
+func (t *NodeTopology) alignedResources(pod *v1.Pod) (initContainers, containers []ResourceList) {
+	guaranteed := qos.GetPodQOS(pod) == v1.PodQOSGuaranteed
+	aligned := func(name v1.ResourceName) bool {
+		if !guaranteed && (name == v1.ResourceCPU || name == v1.ResourceMemory || strings.HasPrefix(string(name), v1.ResourceHugePagesPrefix)) {
+			return false
+		}
+		for _, zone := range t.Zones {
+			if _, ok := zone.Resources[string(name)]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	requestsOf := func(container *v1.Container) ResourceList {
+		requests := ResourceList{}
+		for name, quantity := range container.Resources.Requests {
+			if aligned(name) && !quantity.IsZero() {
+				requests[string(name)] = &Resource{Name: string(name), Requests: quantity.MilliValue()}
+			}
+		}
+		return requests
+	}
+
+	if t.Scope == TopologyScopePod {
+		total := ResourceList{}
+		for i := range pod.Spec.Containers {
+			for name, r := range requestsOf(&pod.Spec.Containers[i]) {
+				if _, ok := total[name]; !ok {
+					total[name] = &Resource{Name: name}
+				}
+				total[name].Requests += r.Requests
+			}
+		}
+		for i := range pod.Spec.InitContainers {
+			for name, r := range requestsOf(&pod.Spec.InitContainers[i]) {
+				if cur, ok := total[name]; !ok || cur.Requests < r.Requests {
+					total[name] = r
+				}
+			}
+		}
+		return nil, []ResourceList{total}
+	}
+	for i := range pod.Spec.InitContainers {
+		if requests := requestsOf(&pod.Spec.InitContainers[i]); len(requests) > 0 {
+			initContainers = append(initContainers, requests)
+		}
+	}
+	for i := range pod.Spec.Containers {
+		if requests := requestsOf(&pod.Spec.Containers[i]); len(requests) > 0 {
+			containers = append(containers, requests)
+		}
+	}
+	return initContainers, containers
+}
+
+// FitSingleNUMA places the aligned requests of the pod on single NUMA zones, the way the
+// kubelet admits it under the single-numa-node policy. Init containers run one at a time
+// and give their resources back, so each only has to fit a zone on its own. It returns
+// the zone of every container, or the reason no zone fits.
+func (t *NodeTopology) FitSingleNUMA(pod *v1.Pod) ([]string, error) {
+	available := make([]map[string]int64, len(t.Zones))
+	for i, zone := range t.Zones {
+		available[i] = make(map[string]int64)
+		for name, r := range zone.Resources {
+			available[i][name] = r.Available
+		}
+	}
+
+	initContainers, containers := t.alignedResources(pod)
+	var placed []string
+	for _, requests := range initContainers {
+		fit, err := t.fitZone(available, requests)
+		if err != nil {
+			return placed, err
+		}
+		placed = append(placed, t.Zones[fit].Name)
+	}
+	for _, requests := range containers {
+		fit, err := t.fitZone(available, requests)
+		if err != nil {
+			return placed, err
+		}
+		for name, r := range requests {
+			available[fit][name] -= r.Requests
+		}
+		placed = append(placed, t.Zones[fit].Name)
+	}
+	return placed, nil
+}
+
+// fitZone returns the first zone with all requests available.
+func (t *NodeTopology) fitZone(available []map[string]int64, requests ResourceList) (int, error) {
+	for i := range t.Zones {
+		fits := true
+		for name, r := range requests {
+			if available[i][name] < r.Requests {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return i, nil
+		}
+	}
+	var wants []string
+	for name, r := range requests {
+		wants = append(wants, fmt.Sprintf("%s %s", name, r))
+	}
+	sort.Strings(wants)
+	return -1, fmt.Errorf("no NUMA zone has %s available", strings.Join(wants, ", "))
+}
+
+// PrintNodeTopologies prints the available/allocatable resources of every NUMA zone.
+func PrintNodeTopologies(nodeList []*Node, topologies map[string]*NodeTopology) {
+	if len(topologies) == 0 {
+		fmt.Println("No NodeResourceTopology found, is the topology exporter (e.g. RTE or NFD topology-updater) deployed?")
+		return
+	}
+
+	var resourceNames []string
+	seen := make(map[string]bool)
+	for _, topology := range topologies {
+		for _, zone := range topology.Zones {
+			for name := range zone.Resources {
+				if !seen[name] {
+					seen[name] = true
+					resourceNames = append(resourceNames, name)
+				}
+			}
+		}
+	}
+	sort.Slice(resourceNames, func(i, j int) bool {
+		// native resources first, the way the node report orders them
+		ni, nj := IsExtendedResource(v1.ResourceName(resourceNames[i])), IsExtendedResource(v1.ResourceName(resourceNames[j]))
+		if ni != nj {
+			return nj
+		}
+		return resourceNames[i] < resourceNames[j]
+	})
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	header := []string{"nodeName", "policy", "zone"}
+	for _, name := range resourceNames {
+		header = append(header, name)
+	}
+	t.AppendHeader(util.ListToRow(header))
+
+	sorted := append([]*Node{}, nodeList...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, n := range sorted {
+		topology, ok := topologies[n.Name]
+		if !ok {
+			continue
+		}
+		for _, zone := range topology.Zones {
+			row := []string{n.Name, fmt.Sprintf("%s/%s", topology.Policy, topology.Scope), zone.Name}
+			for _, name := range resourceNames {
+				zr, ok := zone.Resources[name]
+				if !ok {
+					row = append(row, "-")
+					continue
+				}
+				available := &Resource{Name: name, Requests: zr.Available}
+				allocatable := &Resource{Name: name, Requests: zr.Allocatable}
+				cell := util.ColorText{Text: fmt.Sprintf("%s/%s", available, allocatable)}
+				if zr.Allocatable > 0 && zr.Available == 0 {
+					cell = util.NewRedText(cell.Text)
+				}
+				row = append(row, cell.String())
+			}
+			t.AppendRow(util.ListToRow(row))
+		}
+	}
+
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
@@ -0,0 +1,74 @@
+package framework
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFitSingleNUMA(t *testing.T) {
+	zone := func(name, cpu, memory string) interface{} {
+		return map[string]interface{}{"name": name, "type": "Node", "resources": []interface{}{
+			map[string]interface{}{"name": "cpu", "capacity": "32", "allocatable": "30", "available": cpu},
+			map[string]interface{}{"name": "memory", "capacity": "128Gi", "allocatable": "120Gi", "available": memory},
+		}}
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "node1"},
+		"attributes": []interface{}{
+			map[string]interface{}{"name": "topologyManagerPolicy", "value": "single-numa-node"},
+			map[string]interface{}{"name": "topologyManagerScope", "value": "container"},
+		},
+		"zones": []interface{}{zone("node-0", "6", "64Gi"), zone("node-1", "10", "16Gi")},
+	}}
+	topology, err := parseNodeTopology(obj)
+	if err != nil {
+		t.Fatalf("parseNodeTopology() error = %v", err)
+	}
+	if topology.Policy != TopologyPolicySingleNUMANode || len(topology.Zones) != 2 || topology.Zones[1].Resources["cpu"].Available != 10000 {
+		t.Fatalf("parseNodeTopology() = %+v", topology)
+	}
+
+	container := func(cpu, memory string) v1.Container {
+		list := v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(memory)}
+		return v1.Container{Resources: v1.ResourceRequirements{Requests: list, Limits: list}}
+	}
+	// 16 cores and 80Gi are free on the node, but no zone has 8 cores with 32Gi
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{container("8", "32Gi")}}}
+	if _, err := topology.FitSingleNUMA(pod); err == nil {
+		t.Errorf("FitSingleNUMA() of 8 cores 32Gi should fail")
+	}
+
+	pod = &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{container("4", "32Gi"), container("8", "8Gi")}}}
+	zones, err := topology.FitSingleNUMA(pod)
+	if err != nil || len(zones) != 2 || zones[0] != "node-0" || zones[1] != "node-1" {
+		t.Errorf("FitSingleNUMA() = %v, %v, want [node-0 node-1]", zones, err)
+	}
+
+	topology.Scope = TopologyScopePod
+	if _, err := topology.FitSingleNUMA(pod); err == nil {
+		t.Errorf("FitSingleNUMA() with pod scope should fail, 12 cores do not fit a zone")
+	}
+
+	// burstable pods are not aligned for cpu and memory
+	burstable := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Resources: v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("20")},
+	}}}}}
+	if zones, err := topology.FitSingleNUMA(burstable); err != nil || len(zones) != 1 {
+		t.Errorf("FitSingleNUMA() of a burstable pod = %v, %v", zones, err)
+	}
+
+	// init containers are aligned in the container scope, and give their cores back
+	topology.Scope = TopologyScopeContainer
+	pod = &v1.Pod{Spec: v1.PodSpec{InitContainers: []v1.Container{container("12", "1Gi")}, Containers: []v1.Container{container("1", "1Gi")}}}
+	if _, err := topology.FitSingleNUMA(pod); err == nil {
+		t.Errorf("FitSingleNUMA() of a 12 cores init container should fail")
+	}
+	pod = &v1.Pod{Spec: v1.PodSpec{InitContainers: []v1.Container{container("8", "1Gi")}, Containers: []v1.Container{container("8", "1Gi")}}}
+	zones, err = topology.FitSingleNUMA(pod)
+	if err != nil || len(zones) != 2 || zones[0] != "node-1" || zones[1] != "node-1" {
+		t.Errorf("FitSingleNUMA() with an init container = %v, %v, want [node-1 node-1]", zones, err)
+	}
+}
@@ -0,0 +1,152 @@
+package framework
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	v1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/ops-tool/pkg/util"
+)
+
+// Default limits/allocatable percentages of the overcommit view.
+var (
+	DefaultOvercommitWarn     = map[string]int{"cpu": 150, "memory": 150}
+	DefaultOvercommitCritical = map[string]int{"cpu": 200, "memory": 200}
+)
+
+// PodHeadroom is how much a pod may burst above its requests, up to its limits.
+type PodHeadroom struct {
+	Pod      *v1.Pod
+	Resource string
+	Headroom int64
+	// Unbounded is set when a container has no limit, the pod may use all the node has.
+	Unbounded bool
+}
+
+func (ph *PodHeadroom) String() string {
+	if ph.Unbounded {
+		return fmt.Sprintf("%s/%s: %s no limit", ph.Pod.Namespace, ph.Pod.Name, ph.Resource)
+	}
+	headroom := &Resource{Name: ph.Resource, Requests: ph.Headroom}
+	return fmt.Sprintf("%s/%s: %s +%s", ph.Pod.Namespace, ph.Pod.Name, ph.Resource, headroom.String())
+}
+
+// BurstableHeadroom returns the pods of the node by decreasing headroom of resourceName,
+// unbounded pods first.
+func (nr *Node) BurstableHeadroom(resourceName string) []*PodHeadroom {
+	var result []*PodHeadroom
+	for _, podInfo := range nr.Pods {
+		pod := podInfo.Pod
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		ph := &PodHeadroom{Pod: pod, Resource: resourceName}
+		for _, container := range pod.Spec.Containers {
+			if _, ok := container.Resources.Limits[v1.ResourceName(resourceName)]; !ok {
+				ph.Unbounded = true
+			}
+		}
+		requests, limits := resourcehelper.PodRequestsAndLimits(pod)
+		request, limit := requests[v1.ResourceName(resourceName)], limits[v1.ResourceName(resourceName)]
+		ph.Headroom = limit.MilliValue() - request.MilliValue()
+		if ph.Unbounded || ph.Headroom > 0 {
+			result = append(result, ph)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Unbounded != result[j].Unbounded {
+			return result[i].Unbounded
+		}
+		return result[i].Headroom > result[j].Headroom
+	})
+	return result
+}
+
+// overcommitLevel returns 2 when the limits fraction of the resource reaches critical, 1 for warn.
+func overcommitLevel(r *Resource, warn, critical map[string]int) int {
+	if threshold, ok := critical[r.Name]; ok && r.LimitsFraction >= float64(threshold) {
+		return 2
+	}
+	if threshold, ok := warn[r.Name]; ok && r.LimitsFraction >= float64(threshold) {
+		return 1
+	}
+	return 0
+}
+
+// PrintOvercommit prints limits/allocatable of the resources having a threshold and, for the
+// overcommitted nodes, the top pods by burstable headroom of the overcommitted resources.
+func PrintOvercommit(nodeList []*Node, warn, critical map[string]int, top int) {
+	var resourceNames []string
+	for name := range warn {
+		resourceNames = append(resourceNames, name)
+	}
+	for name := range critical {
+		if _, ok := warn[name]; !ok {
+			resourceNames = append(resourceNames, name)
+		}
+	}
+	sort.Strings(resourceNames)
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	header := []string{"nodeName"}
+	for _, name := range resourceNames {
+		header = append(header, name+" limits")
+	}
+	header = append(header, "top burstable pods")
+	t.AppendHeader(util.ListToRow(header))
+
+	sorted := append([]*Node{}, nodeList...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, n := range sorted {
+		row := []string{n.Name}
+		var overcommitted []string
+		for _, name := range resourceNames {
+			cur, ok := n.AllocatedResourceMap[name]
+			if !ok || cur.Capacity == 0 {
+				row = append(row, "-")
+				continue
+			}
+			limits := &Resource{Name: name, Requests: cur.Limits}
+			capacity := &Resource{Name: name, Requests: cur.Capacity}
+			cell := util.ColorText{Text: fmt.Sprintf("%s/%s(%d%%)", limits.String(), capacity.String(), int64(cur.LimitsFraction))}
+			switch overcommitLevel(cur, warn, critical) {
+			case 2:
+				cell = util.NewRedText(cell.Text)
+				overcommitted = append(overcommitted, name)
+			case 1:
+				cell = util.NewYellowText(cell.Text)
+				overcommitted = append(overcommitted, name)
+			}
+			row = append(row, cell.String())
+		}
+
+		var pods []string
+		for _, name := range overcommitted {
+			headrooms := n.BurstableHeadroom(name)
+			if len(headrooms) > top {
+				headrooms = headrooms[:top]
+			}
+			for _, ph := range headrooms {
+				pods = append(pods, ph.String())
+			}
+		}
+		if len(pods) == 0 {
+			pods = []string{"-"}
+		}
+		row = append(row, strings.Join(pods, "\n"))
+		t.AppendRow(util.ListToRow(row))
+	}
+
+	style := table.StyleRounded
+	style.Format.Header = text.FormatDefault
+	t.SetStyle(style)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
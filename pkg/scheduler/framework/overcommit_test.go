@@ -0,0 +1,54 @@
+package framework
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestBurstableHeadroom(t *testing.T) {
+	newPod := func(name string, request, limit string, phase v1.PodPhase) *PodInfo {
+		container := v1.Container{Name: "c", Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse(request)},
+		}}
+		if limit != "" {
+			container.Resources.Limits = v1.ResourceList{v1.ResourceMemory: resource.MustParse(limit)}
+		}
+		pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{container}}, Status: v1.PodStatus{Phase: phase}}
+		pod.Name, pod.Namespace = name, "default"
+		return &PodInfo{Pod: pod}
+	}
+	n := &Node{Pods: []*PodInfo{
+		newPod("small", "1Gi", "2Gi", v1.PodRunning),
+		newPod("guaranteed", "4Gi", "4Gi", v1.PodRunning),
+		newPod("large", "1Gi", "8Gi", v1.PodRunning),
+		newPod("unbounded", "1Gi", "", v1.PodRunning),
+		newPod("completed", "1Gi", "16Gi", v1.PodSucceeded),
+	}}
+
+	headrooms := n.BurstableHeadroom("memory")
+	var names []string
+	for _, ph := range headrooms {
+		names = append(names, ph.Pod.Name)
+	}
+	want := []string{"unbounded", "large", "small"}
+	if len(names) != len(want) {
+		t.Fatalf("BurstableHeadroom() = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("BurstableHeadroom() = %v, want %v", names, want)
+		}
+	}
+	if headrooms[1].Headroom != 7*1024*1024*1024*1000 {
+		t.Errorf("headroom of large = %d, want 7Gi in milli", headrooms[1].Headroom)
+	}
+
+	warn, critical := map[string]int{"memory": 150}, map[string]int{"memory": 200}
+	for fraction, level := range map[float64]int{100: 0, 150: 1, 199: 1, 250: 2} {
+		if got := overcommitLevel(&Resource{Name: "memory", LimitsFraction: fraction}, warn, critical); got != level {
+			t.Errorf("overcommitLevel(%.0f) = %d, want %d", fraction, got, level)
+		}
+	}
+}
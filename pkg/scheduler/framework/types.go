@@ -3,6 +3,7 @@ package framework
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"strings"
 
@@ -20,11 +21,6 @@ import (
 	"github.com/ops-tool/pkg/util"
 )
 
-var (
-	ResourceNames      = []string{"cpu", "memory", "ephemeral-storage", "hugepages-1Gi", "hugepages-2Mi", "cloudbed.abcstack.com/mlnx_numa0_netdevice", "cloudbed.abcstack.com/mlnx_numa1_netdevice", "cloudbed.abcstack.com/hdd-passthrough", "cloudbed.abcstack.com/ssd-passthrough"}
-	printResourceNames = []string{"cpu", "memory", "ephemeral-storage", "hugepages-1Gi", "hugepages-2Mi", "mlnx_numa0_netdevice", "mlnx_numa1_netdevice", "hdd-passthrough", "ssd-passthrough"}
-)
-
 type Resource struct {
 	Name             string  `json:"name"`
 	Requests         int64   `json:"requests"`
@@ -36,25 +32,69 @@ type Resource struct {
 }
 
 func (r *Resource) String() string {
+	return FormatMilli(r.Requests, activeProfile.unit(r.Name))
+}
 
-	resourceName := r.Name
-	if resourceName == "cpu" {
-		return fmt.Sprintf("%dm", r.Requests)
-	} else if resourceName == "cloudbed.abcstack.com/mlnx_numa0_netdevice" || resourceName == "cloudbed.abcstack.com/mlnx_numa1_netdevice" || resourceName == "cloudbed.abcstack.com/hdd-passthrough" || resourceName == "cloudbed.abcstack.com/ssd-passthrough" {
-		return fmt.Sprintf("%d", int64(r.Requests)/1000)
-	} else if resourceName == "ephemeral-storage" {
-		return fmt.Sprintf("%.1fGi", float64(r.Requests)/(1000*1024*1024*1024))
+type ResourceList map[string]*Resource
+
+// MaxFit returns how many more copies of want still fit into what is left in rl,
+// together with the resource that runs out first. A want without any request
+// fits an unlimited number of times and returns math.MaxInt64 and "".
+func (rl ResourceList) MaxFit(want ResourceList) (int64, string) {
+	fit, limitedBy := int64(math.MaxInt64), ""
+	for name, w := range want {
+		if w.Requests <= 0 {
+			continue
+		}
+		var n int64
+		if h, ok := rl[name]; ok && h.Left > 0 {
+			n = h.Left / w.Requests
+		}
+		if n < fit || (n == fit && name < limitedBy) {
+			fit, limitedBy = n, name
+		}
 	}
-	return fmt.Sprintf("%.1fGi", float64(r.Requests)/(1000*1024*1024*1024))
+	return fit, limitedBy
 }
 
-type ResourceList map[string]*Resource
+// AddPod accounts the requests and limits of pod against rl, as if it was bound to the node.
+func (rl ResourceList) AddPod(pod *v1.Pod) {
+	podResources := BuildPodResourceList(pod)
+	podResources[string(v1.ResourcePods)] = &Resource{Name: string(v1.ResourcePods), Requests: 1000}
+	for name, r := range podResources {
+		have, ok := rl[name]
+		if !ok {
+			have = &Resource{Name: name}
+			rl[name] = have
+		}
+		have.Requests += r.Requests
+		have.Limits += r.Limits
+		have.Left -= r.Requests
+		if have.Capacity > 0 {
+			have.RequestsFraction = float64(have.Requests) / float64(have.Capacity) * 100
+			have.LimitsFraction = float64(have.Limits) / float64(have.Capacity) * 100
+		}
+	}
+}
+
+// thresholds of the "requested but idle" column: a node requesting at least idleRequestsFraction
+// of a resource while using at most idleUsageFraction of it looks full but is cold.
+const (
+	idleRequestsFraction = 70
+	idleUsageFraction    = 30
+)
+
+// usageResourceNames are the resources metrics-server reports.
+var usageResourceNames = []string{"cpu", "memory"}
 
 type Node struct {
 	Name                 string
 	AllocatedResourceMap ResourceList
 	v1.Node
 
+	// Usage is the metrics-server usage of the node, nil when metrics are not available.
+	Usage v1.ResourceList
+
 	Pods             []*PodInfo
 	PodsWithAffinity []*PodInfo
 
@@ -79,9 +119,15 @@ func (n *Node) AddPod(pod *v1.Pod) {
 }
 
 func (nr *Node) String() []string {
+	return nr.ResourceStrings(activeProfile.Columns(nil))
+}
+
+// ResourceStrings returns the node name followed by the requested/allocatable cell of each column.
+func (nr *Node) ResourceStrings(columns []ResourceColumn) []string {
 
 	res := []string{strings.Split(nr.Name, "-")[0]}
-	for _, resourceName := range ResourceNames {
+	for _, column := range columns {
+		resourceName := column.Name
 		if cur, ok := nr.AllocatedResourceMap[resourceName]; ok {
 			//res = append(res, nr.AllocatedResourceMap[resourceName].String())
 			if cur.Capacity == 0 {
@@ -99,6 +145,42 @@ func (nr *Node) String() []string {
 	return res
 }
 
+// UsageStrings returns the used/allocatable column of each usage resource and the
+// "requested but idle" column.
+func (nr *Node) UsageStrings() []string {
+	var res, idle []string
+	cold := false
+	for _, resourceName := range usageResourceNames {
+		cur, ok := nr.AllocatedResourceMap[resourceName]
+		quantity, used := nr.Usage[v1.ResourceName(resourceName)]
+		if !ok || !used || cur.Capacity == 0 {
+			res = append(res, "-")
+			continue
+		}
+		usage := &Resource{Name: resourceName, Requests: quantity.MilliValue()}
+		capacity := &Resource{Name: resourceName, Requests: cur.Capacity}
+		usageFraction := float64(usage.Requests) / float64(cur.Capacity) * 100
+		res = append(res, fmt.Sprintf("%s/%s(%d%%)", usage.String(), capacity.String(), int64(usageFraction)))
+
+		if cur.Requests > usage.Requests {
+			idleResource := &Resource{Name: resourceName, Requests: cur.Requests - usage.Requests}
+			idle = append(idle, fmt.Sprintf("%s %s(%d%%)", resourceName, idleResource.String(), int64(cur.RequestsFraction-usageFraction)))
+		}
+		if cur.RequestsFraction >= idleRequestsFraction && usageFraction <= idleUsageFraction {
+			cold = true
+		}
+	}
+
+	if len(idle) == 0 {
+		return append(res, "-")
+	}
+	idleText := util.ColorText{Text: strings.Join(idle, "\n")}
+	if cold {
+		idleText = util.NewRedText(idleText.Text)
+	}
+	return append(res, idleText.String())
+}
+
 func (n *Node) SetNode(node *v1.Node) {
 	n.Node = *node
 }
@@ -300,49 +382,97 @@ func PodWithRequiredAntiAffinity(p *v1.Pod) bool {
 		len(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 0
 }
 
-func BuildNodeList(clientset *kubernetes.Clientset) ([]*Node, error) {
-	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+// listPageSize is the page size of the paginated lists, the apiserver serves a page from its
+// watch cache without scanning the whole etcd range.
+const listPageSize = 500
 
-	if err != nil {
-		return nil, err
+// ListAllPods lists the pods of namespace page by page.
+func ListAllPods(clientset kubernetes.Interface, namespace string, options metav1.ListOptions) ([]v1.Pod, error) {
+	var result []v1.Pod
+	options.Limit = listPageSize
+	for {
+		pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), options)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, pods.Items...)
+		if pods.Continue == "" {
+			return result, nil
+		}
+		options.Continue = pods.Continue
 	}
+}
 
-	var nodeList []*Node
-
-	for _, node := range nodes.Items {
-		allocatedResourceMap, err := BuildAllocatedResourceMap(clientset, &node)
+// ListAllNodes lists the nodes page by page.
+func ListAllNodes(clientset kubernetes.Interface, options metav1.ListOptions) ([]v1.Node, error) {
+	var result []v1.Node
+	options.Limit = listPageSize
+	for {
+		nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), options)
 		if err != nil {
-			fmt.Printf("error fetching node resource of node %s\n", node.Name)
-			continue
+			return nil, err
 		}
+		result = append(result, nodes.Items...)
+		if nodes.Continue == "" {
+			return result, nil
+		}
+		options.Continue = nodes.Continue
+	}
+}
 
-		fieldSelector := fmt.Sprintf("spec.nodeName=%s", node.Name)
-		pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{
-			FieldSelector: fieldSelector,
-		})
-
-		if err != nil {
-			fmt.Printf("error fetching pods on node  %s\n", node.Name)
+// IndexPodsByNode groups the pods bound to a node by node name.
+func IndexPodsByNode(pods []v1.Pod) map[string][]v1.Pod {
+	result := make(map[string][]v1.Pod)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
 			continue
 		}
+		result[pod.Spec.NodeName] = append(result[pod.Spec.NodeName], pod)
+	}
+	return result
+}
+
+// BuildNodeList lists the nodes and all pods once and builds the node report from them.
+func BuildNodeList(clientset kubernetes.Interface) ([]*Node, error) {
+	nodes, err := ListAllNodes(clientset, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := ListAllPods(clientset, v1.NamespaceAll, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildNodeListFromPods(nodes, pods), nil
+}
+
+// BuildNodeListFromPods is BuildNodeList for nodes and pods that are already listed.
+func BuildNodeListFromPods(nodes []v1.Node, pods []v1.Pod) []*Node {
+	podsByNode := IndexPodsByNode(pods)
+
+	var nodeList []*Node
+	for i := range nodes {
+		node := &nodes[i]
+		nodePods := podsByNode[node.Name]
 
 		nodeInfo := &Node{
 			Name:                 node.Name,
-			AllocatedResourceMap: allocatedResourceMap,
-			Node:                 node,
+			AllocatedResourceMap: BuildAllocatedResourceMapFromPods(node, nodePods),
+			Node:                 *node,
 		}
 
-		for _, pod := range pods.Items {
-			nodeInfo.AddPod(&pod)
+		for j := range nodePods {
+			nodeInfo.AddPod(&nodePods[j])
 		}
 
 		nodeList = append(nodeList, nodeInfo)
 	}
 
-	return nodeList, nil
+	return nodeList
 }
 
-func BuildAllocatedResourceMap(clientset *kubernetes.Clientset, node *v1.Node) (ResourceList, error) {
+func BuildAllocatedResourceMap(clientset kubernetes.Interface, node *v1.Node) (ResourceList, error) {
 
 	fieldSelector, err := fields.ParseSelector("spec.nodeName=" + node.Name +
 		",status.phase!=" + string(v1.PodSucceeded) +
@@ -355,15 +485,34 @@ func BuildAllocatedResourceMap(clientset *kubernetes.Clientset, node *v1.Node) (
 	pods, err := clientset.CoreV1().Pods(v1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
 		FieldSelector: fieldSelector.String(),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildAllocatedResourceMapFromPods(node, pods.Items), nil
+}
+
+// BuildAllocatedResourceMapFromPods is BuildAllocatedResourceMap for pods that are already
+// listed. Pods bound to other nodes and terminated pods are ignored, the number of the
+// remaining pods is accounted as the "pods" resource.
+func BuildAllocatedResourceMapFromPods(node *v1.Node, pods []v1.Pod) ResourceList {
+	activePods := &v1.PodList{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != node.Name || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		activePods.Items = append(activePods.Items, pod)
+	}
 
 	allocatables := node.Status.Capacity
 	if len(node.Status.Allocatable) > 0 {
 		allocatables = node.Status.Allocatable
 	}
 
-	reqs, limits := GetPodsTotalRequestsAndLimits(pods)
+	reqs, limits := GetPodsTotalRequestsAndLimits(activePods)
+	reqs[v1.ResourcePods] = *resource.NewQuantity(int64(len(activePods.Items)), resource.DecimalSI)
 
-	return resourceListToAllocatedResource(reqs, limits, allocatables), nil
+	return resourceListToAllocatedResource(reqs, limits, allocatables)
 }
 
 func resourceListToAllocatedResource(reqs, limits, allocatables map[v1.ResourceName]resource.Quantity) ResourceList {
@@ -412,10 +561,29 @@ func PrintNodeList(nodeList []*Node) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
 
-	header := append([]string{"nodeName"}, printResourceNames...)
+	withUsage := false
+	for _, n := range nodeList {
+		if n.Usage != nil {
+			withUsage = true
+			break
+		}
+	}
+
+	columns := activeProfile.Columns(nodeList)
+	header := []string{"nodeName"}
+	for _, column := range columns {
+		header = append(header, column.Header())
+	}
+	if withUsage {
+		header = append(header, "cpu usage", "memory usage", "requested but idle")
+	}
 	t.AppendHeader(util.ListToRow(header))
 	for _, n := range nodeList {
-		t.AppendRow(util.ListToRow(n.String()))
+		row := n.ResourceStrings(columns)
+		if withUsage {
+			row = append(row, n.UsageStrings()...)
+		}
+		t.AppendRow(util.ListToRow(row))
 	}
 
 	columnConfigs := make([]table.ColumnConfig, len(header))
@@ -460,7 +628,7 @@ type PVCStatus struct {
 	PVError          string
 }
 
-func BuildPVAffinity(clientset *kubernetes.Clientset, pod *v1.Pod) []*PVCStatus {
+func BuildPVAffinity(clientset kubernetes.Interface, pod *v1.Pod) []*PVCStatus {
 
 	var pvAffinity []*PVCStatus
 	for _, volume := range pod.Spec.Volumes {
@@ -0,0 +1,184 @@
+package framework
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestResourceList_MaxFit(t *testing.T) {
+	type args struct {
+		want ResourceList
+	}
+	tests := []struct {
+		name          string
+		rl            ResourceList
+		args          args
+		wantFit       int64
+		wantLimitedBy string
+	}{
+		{
+			name:          "nothing requested",
+			rl:            ResourceList{"cpu": &Resource{Name: "cpu", Left: 1000}},
+			args:          args{want: ResourceList{}},
+			wantFit:       math.MaxInt64,
+			wantLimitedBy: "",
+		},
+		{
+			name: "limited by memory",
+			rl: ResourceList{
+				"cpu":    &Resource{Name: "cpu", Left: 8000},
+				"memory": &Resource{Name: "memory", Left: 3000},
+			},
+			args: args{want: ResourceList{
+				"cpu":    &Resource{Name: "cpu", Requests: 1000},
+				"memory": &Resource{Name: "memory", Requests: 1000},
+			}},
+			wantFit:       3,
+			wantLimitedBy: "memory",
+		},
+		{
+			name: "resource missing on node",
+			rl: ResourceList{
+				"cpu": &Resource{Name: "cpu", Left: 8000},
+			},
+			args: args{want: ResourceList{
+				"cpu":                                   &Resource{Name: "cpu", Requests: 1000},
+				"cloudbed.abcstack.com/ssd-passthrough": &Resource{Name: "cloudbed.abcstack.com/ssd-passthrough", Requests: 1000},
+			}},
+			wantFit:       0,
+			wantLimitedBy: "cloudbed.abcstack.com/ssd-passthrough",
+		},
+		{
+			name: "overcommitted node",
+			rl: ResourceList{
+				"cpu": &Resource{Name: "cpu", Left: -500},
+			},
+			args: args{want: ResourceList{
+				"cpu": &Resource{Name: "cpu", Requests: 100},
+			}},
+			wantFit:       0,
+			wantLimitedBy: "cpu",
+		},
+		{
+			name: "tie resolved by name",
+			rl: ResourceList{
+				"cpu":  &Resource{Name: "cpu", Left: 2000},
+				"pods": &Resource{Name: "pods", Left: 2000},
+			},
+			args: args{want: ResourceList{
+				"pods": &Resource{Name: "pods", Requests: 1000},
+				"cpu":  &Resource{Name: "cpu", Requests: 1000},
+			}},
+			wantFit:       2,
+			wantLimitedBy: "cpu",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFit, gotLimitedBy := tt.rl.MaxFit(tt.args.want)
+			if gotFit != tt.wantFit || gotLimitedBy != tt.wantLimitedBy {
+				t.Errorf("MaxFit() = %v, %v, want %v, %v", gotFit, gotLimitedBy, tt.wantFit, tt.wantLimitedBy)
+			}
+		})
+	}
+}
+
+// newFakeCluster returns a fake clientset holding nodes nodes and pods pods spread over them.
+func newFakeCluster(nodes, pods int) *fake.Clientset {
+	objects := make([]runtime.Object, 0, nodes+pods)
+	for i := 0; i < nodes; i++ {
+		objects = append(objects, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)},
+			Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("64"),
+				v1.ResourceMemory: resource.MustParse("256Gi"),
+				v1.ResourcePods:   resource.MustParse("110"),
+			}},
+		})
+	}
+	for i := 0; i < pods; i++ {
+		objects = append(objects, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)},
+			Spec: v1.PodSpec{
+				NodeName: fmt.Sprintf("node-%d", i%nodes),
+				Containers: []v1.Container{{
+					Name: "app",
+					Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("500m"),
+						v1.ResourceMemory: resource.MustParse("1Gi"),
+					}},
+				}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		})
+	}
+	return fake.NewSimpleClientset(objects...)
+}
+
+func TestListAllPods_Paginates(t *testing.T) {
+	clientset := newFakeCluster(2, 1200)
+	// the fake clientset ignores Limit, serve the pages by hand
+	calls := 0
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		options := action.(k8stesting.ListActionImpl).ListOptions
+		start := 0
+		if options.Continue != "" {
+			start, _ = strconv.Atoi(options.Continue)
+		}
+		list := &v1.PodList{}
+		for i := start; i < start+int(options.Limit) && i < 1200; i++ {
+			list.Items = append(list.Items, v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i)}})
+		}
+		if next := start + int(options.Limit); next < 1200 {
+			list.Continue = strconv.Itoa(next)
+		}
+		return true, list, nil
+	})
+
+	pods, err := ListAllPods(clientset, v1.NamespaceAll, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListAllPods() error = %v", err)
+	}
+	if len(pods) != 1200 || calls != 3 {
+		t.Errorf("ListAllPods() = %d pods in %d calls, want 1200 pods in 3 calls", len(pods), calls)
+	}
+}
+
+func TestBuildNodeList(t *testing.T) {
+	nodeList, err := BuildNodeList(newFakeCluster(4, 40))
+	if err != nil {
+		t.Fatalf("BuildNodeList() error = %v", err)
+	}
+	if len(nodeList) != 4 {
+		t.Fatalf("BuildNodeList() = %d nodes, want 4", len(nodeList))
+	}
+	for _, n := range nodeList {
+		cpu := n.AllocatedResourceMap["cpu"]
+		if len(n.Pods) != 10 || cpu.Requests != 5000 {
+			t.Errorf("node %s has %d pods requesting %dm cpu, want 10 pods requesting 5000m", n.Name, len(n.Pods), cpu.Requests)
+		}
+	}
+}
+
+func BenchmarkBuildNodeList(b *testing.B) {
+	for _, size := range []struct{ nodes, pods int }{{100, 3000}, {400, 12000}} {
+		clientset := newFakeCluster(size.nodes, size.pods)
+		b.Run(fmt.Sprintf("%dnodes-%dpods", size.nodes, size.pods), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := BuildNodeList(clientset); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package scheduler
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ops-tool/pkg/util"
+)
+
+// failedReasons joins the red texts of a check result, i.e. why the node was rejected.
+func failedReasons(result util.ColorTextList) string {
+	var reasons []string
+	for _, ct := range result {
+		if ct.Color == color.FgRed && ct.Text != "" {
+			reasons = append(reasons, ct.Text)
+		}
+	}
+	return strings.Join(reasons, "\n")
+}
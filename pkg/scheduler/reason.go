@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/fatih/color"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"golang.org/x/term"
@@ -20,12 +21,58 @@ type Report struct {
 	TolerationReason       util.ColorTextList
 	PersistentVolumeReason util.ColorTextList
 	PodAffinityReason      util.ColorTextList
+	TopologySpreadReason   util.ColorTextList
+	TopologyManagerReason  util.ColorTextList
 }
 
 func (r *Report) ToStringList() []string {
 
 	return []string{r.NodeName, r.NodeUnschedulable.String(), r.NodeSelectorReason.String(),
-		r.NodeAffinityReason.String(), r.PodAffinityReason.String(), r.TolerationReason.String(), r.ResourceReason.String(), r.PersistentVolumeReason.String()}
+		r.NodeAffinityReason.String(), r.PodAffinityReason.String(), r.TolerationReason.String(), r.ResourceReason.String(), r.PersistentVolumeReason.String(), r.TopologySpreadReason.String(), r.TopologyManagerReason.String()}
+}
+
+func (r *Report) reasons() []util.ColorTextList {
+	return []util.ColorTextList{r.NodeUnschedulable, r.NodeSelectorReason, r.NodeAffinityReason,
+		r.PodAffinityReason, r.TolerationReason, r.ResourceReason, r.PersistentVolumeReason, r.TopologySpreadReason, r.TopologyManagerReason}
+}
+
+// Reason returns the result of a single check by its ReportHeader name.
+func (r *Report) Reason(check string) util.ColorTextList {
+	for i, reason := range r.reasons() {
+		if ReportHeader[i+1] == check {
+			return reason
+		}
+	}
+	return nil
+}
+
+// FailedReasons flattens the failed parts of every failed check into "check: reason" lines.
+func (r *Report) FailedReasons() util.ColorTextList {
+	var result util.ColorTextList
+	for _, check := range r.FailedChecks() {
+		for _, ct := range r.Reason(check) {
+			if ct.Color == color.FgRed && ct.Text != "" {
+				result = append(result, util.NewRedText(fmt.Sprintf("%s: %s", check, ct.Text)))
+			}
+		}
+	}
+	return result
+}
+
+// FailedChecks returns the names of the checks that rule the node out, in ReportHeader order.
+func (r *Report) FailedChecks() []string {
+	var failed []string
+	for i, reason := range r.reasons() {
+		if reason.HasRed() {
+			failed = append(failed, ReportHeader[i+1])
+		}
+	}
+	return failed
+}
+
+// Feasible reports whether the pod can be placed on the node as far as the executed checks go.
+func (r *Report) Feasible() bool {
+	return len(r.FailedChecks()) == 0
 }
 
 func getTerminalWidth() int {
@@ -0,0 +1,70 @@
+// Package schedulertest builds the nodes, pods and fake clientsets the scheduler snapshot is
+// tested with.
+package schedulertest
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// Node returns a node allocating cpu, memory and 110 pods.
+func Node(name string, labels map[string]string, cpu, memory string) v1.Node {
+	allocatable := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+		v1.ResourcePods:   resource.MustParse("110"),
+	}
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status:     v1.NodeStatus{Capacity: allocatable, Allocatable: allocatable.DeepCopy()},
+	}
+}
+
+// Pod returns a running pod in the default namespace whose limits equal its requests, pending
+// when nodeName is empty.
+func Pod(name, nodeName string, labels map[string]string, cpu, memory string) v1.Pod {
+	requests := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: name, UID: types.UID(name), Labels: labels},
+		Spec: v1.PodSpec{
+			NodeName:   nodeName,
+			Containers: []v1.Container{{Name: "main", Resources: v1.ResourceRequirements{Requests: requests, Limits: requests}}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+// OwnedBy makes kind/name the controller of the pod. A Deployment owns it through a ReplicaSet
+// carrying the pod-template-hash, as the deployment controller does.
+func OwnedBy(pod v1.Pod, kind, name string) v1.Pod {
+	controller := true
+	ref := metav1.OwnerReference{Kind: kind, Name: name, UID: types.UID(kind + "/" + name), Controller: &controller}
+	if kind == "Deployment" {
+		ref.Kind, ref.Name, ref.UID = "ReplicaSet", name+"-5d8f7c", types.UID("ReplicaSet/"+name+"-5d8f7c")
+		labels := map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "5d8f7c"}
+		for k, v := range pod.Labels {
+			labels[k] = v
+		}
+		pod.Labels = labels
+	}
+	pod.OwnerReferences = []metav1.OwnerReference{ref}
+	return pod
+}
+
+// ClientSet returns a fake clientset holding the objects and the default and kube-system
+// namespaces, whose labels the inter pod affinity plugin reads.
+func ClientSet(objects ...runtime.Object) *fake.Clientset {
+	objects = append(objects,
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceDefault}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem}},
+	)
+	return fake.NewSimpleClientset(objects...)
+}
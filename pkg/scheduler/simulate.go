@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Placement is the node a simulation bound a pod to, or why no node could take it.
+type Placement struct {
+	Pod      *v1.Pod
+	NodeName string
+	// FailedNodes counts, per check, how many nodes it ruled out when NodeName is empty.
+	FailedNodes map[string]int
+	TotalNodes  int
+}
+
+func (p *Placement) Scheduled() bool {
+	return p.NodeName != ""
+}
+
+// Reason summarizes a failed placement like the scheduler's FailedScheduling event does.
+func (p *Placement) Reason() string {
+	if p.Scheduled() {
+		return ""
+	}
+	var checks []string
+	for check := range p.FailedNodes {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+
+	var reasons []string
+	for _, check := range checks {
+		reasons = append(reasons, fmt.Sprintf("%d %s", p.FailedNodes[check], check))
+	}
+	return fmt.Sprintf("0/%d nodes are available: %s", p.TotalNodes, strings.Join(reasons, ", "))
+}
+
+// Place runs every analyzer check for pod against the nodes of the snapshot and binds it to
+// the feasible node with the most cpu and memory left, like the default least-allocated
+// scoring would. The snapshot is updated with the bound pod.
+func (s *Snapshot) Place(pod *v1.Pod) *Placement {
+	analyzer := NewAnalyzerForPod(s.ClientSet, pod, s)
+	placement := &Placement{Pod: pod, FailedNodes: map[string]int{}, TotalNodes: len(s.Nodes)}
+
+	bestScore := -1.0
+	for i := range s.Nodes {
+		node := &s.Nodes[i]
+		report := analyzer.DiagnoseNodeChecks(node, ReportHeader[1:]...)
+		if failed := report.FailedChecks(); len(failed) > 0 {
+			for _, check := range failed {
+				placement.FailedNodes[check]++
+			}
+			continue
+		}
+		if score := s.leastAllocatedScore(node.Name); score > bestScore {
+			bestScore, placement.NodeName = score, node.Name
+		}
+	}
+
+	if placement.Scheduled() {
+		bound := pod.DeepCopy()
+		bound.Spec.NodeName = placement.NodeName
+		s.AddPod(bound)
+	}
+	return placement
+}
+
+func (s *Snapshot) leastAllocatedScore(nodeName string) float64 {
+	var score float64
+	for _, name := range []string{string(v1.ResourceCPU), string(v1.ResourceMemory)} {
+		if r, ok := s.NodeResources[nodeName][name]; ok && r.Capacity > 0 {
+			score += float64(r.Left) / float64(r.Capacity)
+		}
+	}
+	return score
+}
+
+// PlaceBefore orders pods to re-place like the scheduling queue would: higher priority first,
+// then bigger cpu requests first so that large pods are not starved by small ones.
+func PlaceBefore(a, b *v1.Pod) bool {
+	pa, pb := podPriority(a), podPriority(b)
+	if pa != pb {
+		return pa > pb
+	}
+	return cpuRequest(a) > cpuRequest(b)
+}
+
+func podPriority(pod *v1.Pod) int32 {
+	if pod.Spec.Priority != nil {
+		return *pod.Spec.Priority
+	}
+	return 0
+}
+
+func cpuRequest(pod *v1.Pod) int64 {
+	var total int64
+	for _, container := range pod.Spec.Containers {
+		total += container.Resources.Requests.Cpu().MilliValue()
+	}
+	return total
+}
@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/ops-tool/pkg/scheduler/schedulertest"
+)
+
+func TestSnapshot_Place(t *testing.T) {
+	nodes := []v1.Node{
+		schedulertest.Node("node1", nil, "4", "8Gi"),
+		schedulertest.Node("node2", nil, "4", "8Gi"),
+	}
+	pods := []v1.Pod{schedulertest.Pod("running", "node1", nil, "1", "2Gi")}
+	snapshot := NewSnapshot(schedulertest.ClientSet(), nodes, pods)
+
+	tests := []struct {
+		name       string
+		pod        v1.Pod
+		wantNode   string
+		wantReason string
+	}{
+		{
+			name:     "least allocated node",
+			pod:      schedulertest.Pod("web-0", "", nil, "2", "2Gi"),
+			wantNode: "node2",
+		},
+		{
+			name:     "sees the pod bound before",
+			pod:      schedulertest.Pod("web-1", "", nil, "2", "2Gi"),
+			wantNode: "node1",
+		},
+		{
+			name:       "no node left",
+			pod:        schedulertest.Pod("web-2", "", nil, "3", "2Gi"),
+			wantReason: "0/2 nodes are available: 2 resource",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement := snapshot.Place(&tt.pod)
+			if placement.NodeName != tt.wantNode {
+				t.Errorf("Place() node = %q, want %q", placement.NodeName, tt.wantNode)
+			}
+			if got := placement.Reason(); got != tt.wantReason {
+				t.Errorf("Place() reason = %q, want %q", got, tt.wantReason)
+			}
+		})
+	}
+	if len(snapshot.Pods) != 3 {
+		t.Errorf("snapshot has %d pods after placing, want 3", len(snapshot.Pods))
+	}
+}
+
+func TestSnapshot_WithoutNodes(t *testing.T) {
+	nodes := []v1.Node{
+		schedulertest.Node("node1", nil, "4", "8Gi"),
+		schedulertest.Node("node2", nil, "4", "8Gi"),
+	}
+	pods := []v1.Pod{
+		schedulertest.Pod("web-0", "node1", nil, "1", "1Gi"),
+		schedulertest.Pod("web-1", "node2", nil, "1", "1Gi"),
+		schedulertest.Pod("pending", "", nil, "1", "1Gi"),
+	}
+	snapshot := NewSnapshot(schedulertest.ClientSet(), nodes, pods)
+
+	drained := snapshot.WithoutNodes(sets.New("node1"))
+	if len(drained.Nodes) != 1 || drained.Nodes[0].Name != "node2" {
+		t.Errorf("WithoutNodes() nodes = %v, want node2", drained.Nodes)
+	}
+	for _, pod := range drained.Pods {
+		if pod.Spec.NodeName == "node1" {
+			t.Errorf("WithoutNodes() kept pod %s of node1", pod.Name)
+		}
+	}
+	if len(drained.Pods) != 2 {
+		t.Errorf("WithoutNodes() has %d pods, want 2", len(drained.Pods))
+	}
+	if _, ok := drained.NodeResources["node1"]; ok {
+		t.Errorf("WithoutNodes() kept the resources of node1")
+	}
+	if len(snapshot.Nodes) != 2 || len(snapshot.Pods) != 3 {
+		t.Errorf("WithoutNodes() changed the receiver")
+	}
+}
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ops-tool/pkg/scheduler/framework"
+	"github.com/ops-tool/pkg/scheduler/framework/interpodaffinity"
+)
+
+// Snapshot is the nodes and pods of the cluster listed once, so that any number of
+// pods can be analyzed against the same state without going back to the apiserver.
+type Snapshot struct {
+	ClientSet     kubernetes.Interface
+	Nodes         []v1.Node
+	Pods          []v1.Pod
+	NodeResources map[string]framework.ResourceList
+
+	interPodAffinityPlugin *interpodaffinity.InterPodAffinity
+}
+
+func TakeSnapshot(clientSet kubernetes.Interface) (*Snapshot, error) {
+
+	allPods, err := framework.ListAllPods(clientSet, v1.NamespaceAll, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	allNodes, err := framework.ListAllNodes(clientSet, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	return NewSnapshot(clientSet, allNodes, allPods), nil
+}
+
+func NewSnapshot(clientSet kubernetes.Interface, nodes []v1.Node, pods []v1.Pod) *Snapshot {
+
+	// the resources and the inter pod affinity plugin share one node list, built the same
+	// way as the node report, so pods are indexed once per snapshot
+	nodeList := framework.BuildNodeListFromPods(nodes, pods)
+
+	nodeResources := make(map[string]framework.ResourceList, len(nodeList))
+	for _, node := range nodeList {
+		nodeResources[node.Name] = node.AllocatedResourceMap
+	}
+
+	return &Snapshot{
+		ClientSet:              clientSet,
+		Nodes:                  nodes,
+		Pods:                   pods,
+		NodeResources:          nodeResources,
+		interPodAffinityPlugin: interpodaffinity.NewInterPodAffinityFilterFromNodes(clientSet, nodeList),
+	}
+}
+
+// WithoutNodes returns a snapshot of the cluster as if the given nodes, and every pod bound
+// to them, were gone. The receiver is left untouched.
+func (s *Snapshot) WithoutNodes(nodeNames sets.Set[string]) *Snapshot {
+	var nodes []v1.Node
+	for _, node := range s.Nodes {
+		if !nodeNames.Has(node.Name) {
+			nodes = append(nodes, node)
+		}
+	}
+	var pods []v1.Pod
+	for _, pod := range s.Pods {
+		if !nodeNames.Has(pod.Spec.NodeName) {
+			pods = append(pods, pod)
+		}
+	}
+	return NewSnapshot(s.ClientSet, nodes, pods)
+}
+
+// AddPod accounts a pod bound to pod.Spec.NodeName by a simulation, so that the pods
+// analyzed afterwards see it.
+func (s *Snapshot) AddPod(pod *v1.Pod) {
+	s.Pods = append(s.Pods, *pod)
+	if nodeResources, ok := s.NodeResources[pod.Spec.NodeName]; ok {
+		nodeResources.AddPod(pod)
+	}
+	s.interPodAffinityPlugin.AddPod(pod)
+}
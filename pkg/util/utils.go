@@ -66,6 +66,13 @@ func NewRedText(text string) ColorText {
 	}
 }
 
+func NewYellowText(text string) ColorText {
+	return ColorText{
+		Color: color.FgYellow,
+		Text:  text,
+	}
+}
+
 func (ctl ColorTextList) String() string {
 
 	result := ""
@@ -99,3 +106,13 @@ func (ctl *ColorTextList) MergeList(input ColorTextList) ColorTextList {
 	*ctl = append(*ctl, input...)
 	return *ctl
 }
+
+// HasRed reports whether any text in the list is marked red, i.e. a check failed.
+func (ctl ColorTextList) HasRed() bool {
+	for _, ct := range ctl {
+		if ct.Color == color.FgRed && ct.Text != "" {
+			return true
+		}
+	}
+	return false
+}
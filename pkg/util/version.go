@@ -0,0 +1,47 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// MaxKubeletSkew is the number of minor versions a kubelet may be older than the kube-apiserver.
+const MaxKubeletSkew = 3
+
+// MinorSkew returns how many minor versions component is behind server, negative when it is newer.
+// Versions of different majors are reported as an error.
+func MinorSkew(component, server string) (int, error) {
+	c, err := version.ParseGeneric(component)
+	if err != nil {
+		return 0, err
+	}
+	s, err := version.ParseGeneric(server)
+	if err != nil {
+		return 0, err
+	}
+	if c.Major() != s.Major() {
+		return 0, fmt.Errorf("major version %d differs from %d", c.Major(), s.Major())
+	}
+	return int(s.Minor()) - int(c.Minor()), nil
+}
+
+// KubeletSkew checks a kubelet version against the kube-apiserver version following the version
+// skew policy, it returns an empty string when supported.
+func KubeletSkew(kubelet, server string) string {
+	return ComponentSkew("kubelet", kubelet, server)
+}
+
+// ComponentSkew is KubeletSkew for any node component following the kubelet policy, like kube-proxy.
+func ComponentSkew(component, componentVersion, server string) string {
+	skew, err := MinorSkew(componentVersion, server)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("cannot compare %s %s with apiserver %s: %v", component, componentVersion, server, err)
+	case skew < 0:
+		return fmt.Sprintf("%s %s is newer than apiserver %s", component, componentVersion, server)
+	case skew > MaxKubeletSkew:
+		return fmt.Sprintf("%s %s is %d minor versions older than apiserver %s, at most %d is supported", component, componentVersion, skew, server, MaxKubeletSkew)
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package util
+
+import "testing"
+
+func TestKubeletSkew(t *testing.T) {
+	tests := []struct {
+		kubelet string
+		server  string
+		skewed  bool
+	}{
+		{kubelet: "v1.31.2", server: "v1.31.0", skewed: false},
+		{kubelet: "v1.28.15", server: "v1.31.2", skewed: false},
+		{kubelet: "v1.27.3", server: "v1.31.2", skewed: true},
+		{kubelet: "v1.32.0", server: "v1.31.2", skewed: true},
+		{kubelet: "v1.30.1-eks-1234", server: "v1.31.2+k3s1", skewed: false},
+		{kubelet: "unknown", server: "v1.31.2", skewed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kubelet, func(t *testing.T) {
+			got := KubeletSkew(tt.kubelet, tt.server)
+			if (got != "") != tt.skewed {
+				t.Errorf("KubeletSkew(%s, %s) = %q, want skewed %v", tt.kubelet, tt.server, got, tt.skewed)
+			}
+		})
+	}
+}
+
+func TestComponentSkew(t *testing.T) {
+	tests := []struct {
+		name      string
+		component string
+		version   string
+		server    string
+		want      string
+	}{
+		{name: "supported", component: "kube-proxy", version: "v1.29.4", server: "v1.31.2", want: ""},
+		{name: "oldest supported", component: "kube-proxy", version: "v1.28.0", server: "v1.31.2", want: ""},
+		{name: "too old", component: "kube-proxy", version: "v1.27.9", server: "v1.31.2",
+			want: "kube-proxy v1.27.9 is 4 minor versions older than apiserver v1.31.2, at most 3 is supported"},
+		{name: "newer", component: "kube-proxy", version: "v1.32.0", server: "v1.31.2",
+			want: "kube-proxy v1.32.0 is newer than apiserver v1.31.2"},
+		{name: "other major", component: "kubelet", version: "v2.0.0", server: "v1.31.2",
+			want: "cannot compare kubelet v2.0.0 with apiserver v1.31.2: major version 2 differs from 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComponentSkew(tt.component, tt.version, tt.server); got != tt.want {
+				t.Errorf("ComponentSkew(%s, %s, %s) = %q, want %q", tt.component, tt.version, tt.server, got, tt.want)
+			}
+		})
+	}
+}
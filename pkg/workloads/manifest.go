@@ -0,0 +1,69 @@
+package workloads
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// PodsFromManifest reads a YAML or JSON file, possibly holding several documents, and returns the pods
+// of every pod or workload in it. Other kinds are skipped. Objects without namespace get namespace.
+func PodsFromManifest(filename, namespace string) ([]*v1.Pod, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []*v1.Pod
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(doc, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", filename, err)
+		}
+
+		var pod *v1.Pod
+		switch o := obj.(type) {
+		case *v1.Pod:
+			pod = o
+		case *appsv1.Deployment:
+			pod = PodFromTemplate(o.Namespace, o.Name, &o.Spec.Template)
+		case *appsv1.StatefulSet:
+			pod = PodFromTemplate(o.Namespace, o.Name, &o.Spec.Template)
+		case *appsv1.DaemonSet:
+			pod = PodFromTemplate(o.Namespace, o.Name, &o.Spec.Template)
+		case *appsv1.ReplicaSet:
+			pod = PodFromTemplate(o.Namespace, o.Name, &o.Spec.Template)
+		case *batchv1.Job:
+			pod = PodFromTemplate(o.Namespace, o.Name, &o.Spec.Template)
+		case *batchv1.CronJob:
+			pod = PodFromTemplate(o.Namespace, o.Name, &o.Spec.JobTemplate.Spec.Template)
+		default:
+			continue
+		}
+		if pod.Namespace == "" {
+			pod.Namespace = namespace
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
@@ -0,0 +1,117 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// OwnerOf returns the kind and name of the workload controlling pod, resolving a ReplicaSet
+// created by a Deployment to the Deployment through the pod-template-hash label. A pod without
+// controller returns empty strings.
+func OwnerOf(pod *v1.Pod) (kind, name string) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return "", ""
+	}
+	if ref.Kind == "ReplicaSet" {
+		if hash, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok && strings.HasSuffix(ref.Name, "-"+hash) {
+			return "Deployment", strings.TrimSuffix(ref.Name, "-"+hash)
+		}
+	}
+	return ref.Kind, ref.Name
+}
+
+// kinds maps the kinds and short names accepted in references to the kind of the object.
+var kinds = map[string]string{
+	"deploy": "Deployment", "deployment": "Deployment", "deployments": "Deployment",
+	"sts": "StatefulSet", "statefulset": "StatefulSet", "statefulsets": "StatefulSet",
+	"ds": "DaemonSet", "daemonset": "DaemonSet", "daemonsets": "DaemonSet",
+	"rs": "ReplicaSet", "replicaset": "ReplicaSet", "replicasets": "ReplicaSet",
+	"job": "Job", "jobs": "Job",
+	"cj": "CronJob", "cronjob": "CronJob", "cronjobs": "CronJob",
+	"pod": "Pod", "pods": "Pod", "po": "Pod",
+}
+
+// KindOf returns the kind named by kind, a short name like deploy or a kind in any case.
+func KindOf(kind string) (string, bool) {
+	result, ok := kinds[strings.ToLower(kind)]
+	return result, ok
+}
+
+// OwnerString is OwnerOf formatted as kind/name, or "-" for a pod without controller.
+func OwnerString(pod *v1.Pod) string {
+	kind, name := OwnerOf(pod)
+	if kind == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+func IsMirrorPod(pod *v1.Pod) bool {
+	_, ok := pod.Annotations[v1.MirrorPodAnnotationKey]
+	return ok
+}
+
+func IsDaemonSetPod(pod *v1.Pod) bool {
+	ref := metav1.GetControllerOf(pod)
+	return ref != nil && ref.Kind == "DaemonSet"
+}
+
+func IsTerminated(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
+
+// OwnerResolver walks the controller references of pods up to the top-level workload,
+// Pod→ReplicaSet→Deployment and Pod→Job→CronJob.
+type OwnerResolver struct {
+	controllers map[types.UID]*metav1.OwnerReference
+}
+
+// NewOwnerResolver lists the ReplicaSets and Jobs of namespace, all namespaces when empty.
+func NewOwnerResolver(clientSet kubernetes.Interface, namespace string) (*OwnerResolver, error) {
+	resolver := &OwnerResolver{controllers: make(map[types.UID]*metav1.OwnerReference)}
+	replicaSets, err := clientSet.AppsV1().ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	for i := range replicaSets.Items {
+		resolver.add(&replicaSets.Items[i].ObjectMeta)
+	}
+	jobs, err := clientSet.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobs.Items {
+		resolver.add(&jobs.Items[i].ObjectMeta)
+	}
+	return resolver, nil
+}
+
+func (r *OwnerResolver) add(meta *metav1.ObjectMeta) {
+	if ref := metav1.GetControllerOfNoCopy(meta); ref != nil {
+		r.controllers[meta.UID] = ref
+	}
+}
+
+// Resolve returns the kind and name of the top-level workload of pod, the pod itself when it
+// has no controller.
+func (r *OwnerResolver) Resolve(pod *v1.Pod) (kind, name string) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return "Pod", pod.Name
+	}
+	for {
+		owner, ok := r.controllers[ref.UID]
+		if !ok {
+			return ref.Kind, ref.Name
+		}
+		ref = owner
+	}
+}
@@ -0,0 +1,124 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ParseRef splits a "kind/name" reference, a bare name is taken as a pod.
+func ParseRef(ref string) (kind, name string, err error) {
+	parts := strings.Split(ref, "/")
+	switch len(parts) {
+	case 1:
+		kind, name = "pod", parts[0]
+	case 2:
+		kind, name = strings.ToLower(parts[0]), parts[1]
+	default:
+		return "", "", fmt.Errorf("invalid reference %q, using format kind/name", ref)
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("invalid reference %q, name is required", ref)
+	}
+	return kind, name, nil
+}
+
+// PodFromRef returns the pod a reference points to. For a workload it returns a pod built
+// from the workload's pod template, the way its controller would create it.
+func PodFromRef(clientSet kubernetes.Interface, namespace, ref string) (*v1.Pod, error) {
+	kind, name, err := ParseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var template *v1.PodTemplateSpec
+	switch kind {
+	case "pod", "pods", "po":
+		pod, err := clientSet.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod %s/%s: %w", namespace, name, err)
+		}
+		return pod, nil
+	case "deployment", "deployments", "deploy":
+		deploy, err := clientSet.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment %s/%s: %w", namespace, name, err)
+		}
+		template = &deploy.Spec.Template
+	case "statefulset", "statefulsets", "sts":
+		sts, err := clientSet.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get statefulset %s/%s: %w", namespace, name, err)
+		}
+		template = &sts.Spec.Template
+	case "daemonset", "daemonsets", "ds":
+		ds, err := clientSet.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get daemonset %s/%s: %w", namespace, name, err)
+		}
+		template = &ds.Spec.Template
+	case "replicaset", "replicasets", "rs":
+		rs, err := clientSet.AppsV1().ReplicaSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get replicaset %s/%s: %w", namespace, name, err)
+		}
+		template = &rs.Spec.Template
+	case "job", "jobs":
+		job, err := clientSet.BatchV1().Jobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get job %s/%s: %w", namespace, name, err)
+		}
+		template = &job.Spec.Template
+	case "cronjob", "cronjobs", "cj":
+		cj, err := clientSet.BatchV1().CronJobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cronjob %s/%s: %w", namespace, name, err)
+		}
+		template = &cj.Spec.JobTemplate.Spec.Template
+	default:
+		return nil, fmt.Errorf("unsupported kind %q, supported kinds: pod, deployment, statefulset, daemonset, replicaset, job, cronjob", kind)
+	}
+
+	return PodFromTemplate(namespace, name, template), nil
+}
+
+// PodFromTemplate builds an unscheduled pod from a pod template.
+func PodFromTemplate(namespace, ownerName string, template *v1.PodTemplateSpec) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: *template.ObjectMeta.DeepCopy(),
+		Spec:       *template.Spec.DeepCopy(),
+	}
+	pod.Namespace = namespace
+	if pod.Name == "" {
+		pod.Name = ownerName + "-template"
+	}
+	pod.Spec.NodeName = ""
+	return pod
+}
+
+// ApplyRuntimeClass merges the scheduling constraints of the pod's RuntimeClass into the pod,
+// the way the RuntimeClass admission plugin does. A nodeSelector conflicting with the pod's
+// own is rejected.
+func ApplyRuntimeClass(pod *v1.Pod, runtimeClass *nodev1.RuntimeClass) error {
+	if runtimeClass.Scheduling == nil {
+		return nil
+	}
+	if len(runtimeClass.Scheduling.NodeSelector) > 0 {
+		if pod.Spec.NodeSelector == nil {
+			pod.Spec.NodeSelector = make(map[string]string)
+		}
+		for key, value := range runtimeClass.Scheduling.NodeSelector {
+			if podValue, ok := pod.Spec.NodeSelector[key]; ok && podValue != value {
+				return fmt.Errorf("nodeSelector %s=%s conflicts with %s=%s of runtimeclass %s", key, podValue, key, value, runtimeClass.Name)
+			}
+			pod.Spec.NodeSelector[key] = value
+		}
+	}
+	pod.Spec.Tolerations = append(pod.Spec.Tolerations, runtimeClass.Scheduling.Tolerations...)
+	return nil
+}
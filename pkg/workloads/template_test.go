@@ -0,0 +1,69 @@
+package workloads
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodFromRef(t *testing.T) {
+	template := func(app string) v1.PodTemplateSpec {
+		return v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": app}},
+			Spec:       v1.PodSpec{NodeName: "node1", Containers: []v1.Container{{Name: "main"}}},
+		}
+	}
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "default", Name: name}
+	}
+	clientSet := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: meta("debug"), Spec: v1.PodSpec{NodeName: "node1"}},
+		&appsv1.Deployment{ObjectMeta: meta("web"), Spec: appsv1.DeploymentSpec{Template: template("web")}},
+		&appsv1.StatefulSet{ObjectMeta: meta("db"), Spec: appsv1.StatefulSetSpec{Template: template("db")}},
+		&appsv1.DaemonSet{ObjectMeta: meta("agent"), Spec: appsv1.DaemonSetSpec{Template: template("agent")}},
+		&appsv1.ReplicaSet{ObjectMeta: meta("cache"), Spec: appsv1.ReplicaSetSpec{Template: template("cache")}},
+		&batchv1.Job{ObjectMeta: meta("migrate"), Spec: batchv1.JobSpec{Template: template("migrate")}},
+		&batchv1.CronJob{ObjectMeta: meta("backup"), Spec: batchv1.CronJobSpec{
+			JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: template("backup")}},
+		}},
+	)
+
+	tests := []struct {
+		name     string
+		ref      string
+		wantName string
+		wantApp  string
+		wantNode string
+		wantErr  bool
+	}{
+		{name: "pod", ref: "debug", wantName: "debug", wantNode: "node1"},
+		{name: "deployment", ref: "deploy/web", wantName: "web-template", wantApp: "web"},
+		{name: "statefulset", ref: "sts/db", wantName: "db-template", wantApp: "db"},
+		{name: "daemonset", ref: "ds/agent", wantName: "agent-template", wantApp: "agent"},
+		{name: "replicaset", ref: "rs/cache", wantName: "cache-template", wantApp: "cache"},
+		{name: "job", ref: "job/migrate", wantName: "migrate-template", wantApp: "migrate"},
+		{name: "cronjob", ref: "cj/backup", wantName: "backup-template", wantApp: "backup"},
+		{name: "cronjob by kind", ref: "CronJob/backup", wantName: "backup-template", wantApp: "backup"},
+		{name: "not found", ref: "deploy/missing", wantErr: true},
+		{name: "unsupported kind", ref: "svc/web", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod, err := PodFromRef(clientSet, "default", tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PodFromRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if pod.Namespace != "default" || pod.Name != tt.wantName || pod.Labels["app"] != tt.wantApp || pod.Spec.NodeName != tt.wantNode {
+				t.Errorf("PodFromRef() = %s/%s app %q on %q, want default/%s app %q on %q",
+					pod.Namespace, pod.Name, pod.Labels["app"], pod.Spec.NodeName, tt.wantName, tt.wantApp, tt.wantNode)
+			}
+		})
+	}
+}